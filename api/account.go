@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"errors"
 	"net/http"
+	"time"
 
 	db "github.com/AutomaticOrca/simplebank/db/sqlc"
 	"github.com/AutomaticOrca/simplebank/token"
@@ -13,6 +14,56 @@ import (
 
 type createAccountRequest struct {
 	Currency string `json:"currency" binding:"required,currency"`
+	// Product defaults to a checking account
+	Product string `json:"product" binding:"omitempty,oneof=checking savings business"`
+}
+
+// feeResponse is the fee charged for one type of transfer out of an account
+type feeResponse struct {
+	TransferType  string `json:"transfer_type"`
+	FlatFee       int64  `json:"flat_fee"`
+	PercentageBps int32  `json:"percentage_bps"`
+	MinFee        int64  `json:"min_fee"`
+	MaxFee        *int64 `json:"max_fee,omitempty"`
+}
+
+// productResponse gives the rules of the product an account was opened as
+type productResponse struct {
+	Code                   string        `json:"code"`
+	Name                   string        `json:"name"`
+	AnnualInterestRate     string        `json:"annual_interest_rate"`
+	OverdraftLimit         int64         `json:"overdraft_limit"`
+	MaxAccountsPerCurrency int32         `json:"max_accounts_per_currency"`
+	Fees                   []feeResponse `json:"fees"`
+}
+
+// createAccountResponse is the account, with its product next to its fields
+type createAccountResponse struct {
+	db.Account
+	Product productResponse `json:"product"`
+}
+
+func newProductResponse(product db.Product, fees []db.FeeSchedule) productResponse {
+	rsp := productResponse{
+		Code:                   product.Code,
+		Name:                   product.Name,
+		AnnualInterestRate:     product.AnnualInterestRate,
+		OverdraftLimit:         product.OverdraftLimit,
+		MaxAccountsPerCurrency: product.MaxAccountsPerCurrency,
+		Fees:                   make([]feeResponse, len(fees)),
+	}
+	for i, fee := range fees {
+		rsp.Fees[i] = feeResponse{
+			TransferType:  fee.TransferType,
+			FlatFee:       fee.FlatFee,
+			PercentageBps: fee.PercentageBps,
+			MinFee:        fee.MinFee,
+		}
+		if fee.MaxFee.Valid {
+			rsp.Fees[i].MaxFee = &fee.MaxFee.Int64
+		}
+	}
+	return rsp
 }
 
 func (server *Server) createAccount(ctx *gin.Context) {
@@ -22,15 +73,23 @@ func (server *Server) createAccount(ctx *gin.Context) {
 		return
 	}
 
+	if req.Product == "" {
+		req.Product = db.ProductChecking
+	}
+
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-	arg := db.CreateAccountParams{
-		Owner:    authPayload.Username,
-		Currency: req.Currency,
-		Balance:  0,
+	arg := db.CreateAccountTxParams{
+		Owner:       authPayload.Username,
+		Currency:    req.Currency,
+		ProductCode: req.Product,
 	}
 
-	account, err := server.store.CreateAccount(ctx, arg)
+	result, err := server.store.CreateAccountTx(ctx, arg)
 	if err != nil {
+		if errors.Is(err, db.ErrAccountLimitReached) || errors.Is(err, db.ErrUnknownProduct) {
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
 		if pqErr, ok := err.(*pq.Error); ok {
 			// log.Println(pqErr.Code.Name())
 			switch pqErr.Code.Name() {
@@ -43,7 +102,10 @@ func (server *Server) createAccount(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, account)
+	ctx.JSON(http.StatusOK, createAccountResponse{
+		Account: result.Account,
+		Product: newProductResponse(result.Product, result.Fees),
+	})
 }
 
 type getAccountRequest struct {
@@ -74,12 +136,30 @@ func (server *Server) getAccount(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, account)
+	ctx.JSON(http.StatusOK, newAccountResponse(account))
+}
+
+// accountResponse shows the available balance next to the ledger balance.
+// Money held by authorized holds counts towards the balance but cannot be spent
+type accountResponse struct {
+	db.Account
+	AvailableBalance int64 `json:"available_balance"`
+}
+
+func newAccountResponse(account db.Account) accountResponse {
+	return accountResponse{
+		Account:          account,
+		AvailableBalance: account.Balance - account.HeldAmount,
+	}
 }
 
 type listAccountRequest struct {
-	PageID   int32 `form:"page_id" binding:"required,min=1"`
-	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+	pageRequest
+}
+
+type listAccountsResponse struct {
+	Accounts   []db.Account `json:"accounts"`
+	NextCursor string       `json:"next_cursor,omitempty"`
 }
 
 func (server *Server) listAccounts(ctx *gin.Context) {
@@ -88,12 +168,19 @@ func (server *Server) listAccounts(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
+	cursor, err := req.parse()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
 
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 	arg := db.ListAccountsParams{
-		Owner:  authPayload.Username,
-		Limit:  req.PageSize,
-		Offset: (req.PageID - 1) * req.PageSize,
+		Owner:           authPayload.Username,
+		CursorCreatedAt: cursor.CreatedAt,
+		CursorID:        cursor.ID,
+		Limit:           req.limit(),
+		Offset:          req.offset(),
 	}
 
 	accounts, err := server.store.ListAccounts(ctx, arg)
@@ -105,9 +192,16 @@ func (server *Server) listAccounts(ctx *gin.Context) {
 	if accounts == nil {
 		// 返回一个空的 Account 切片，它会被序列化为 []
 		// 确保使用正确的类型，这里假设是 db.Account
-		ctx.JSON(http.StatusOK, []db.Account{})
+		accounts = []db.Account{}
+	}
+
+	if req.byOffset() {
+		ctx.JSON(http.StatusOK, accounts)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, accounts)
+	accounts, nextCursor := nextPage(req.pageRequest, accounts, func(account db.Account) (time.Time, int64) {
+		return account.CreatedAt, account.ID
+	})
+	ctx.JSON(http.StatusOK, listAccountsResponse{Accounts: accounts, NextCursor: nextCursor})
 }
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	db "github.com/AutomaticOrca/simplebank/db/sqlc"
+	"github.com/gin-gonic/gin"
+)
+
+type accountBalanceRequest struct {
+	AsOf time.Time `form:"as_of"`
+}
+
+// accountBalanceResponse is the balance of an account at as_of. snapshot_date is the
+// end of day snapshot the balance was worked out from, if one was used
+type accountBalanceResponse struct {
+	AccountID    int64     `json:"account_id"`
+	Currency     string    `json:"currency"`
+	AsOf         time.Time `json:"as_of"`
+	Balance      int64     `json:"balance"`
+	SnapshotDate string    `json:"snapshot_date,omitempty"`
+}
+
+// getAccountBalance returns the balance of one of the user's accounts at a point in time, now by default
+func (server *Server) getAccountBalance(ctx *gin.Context) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req accountBalanceRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	now := time.Now()
+	if req.AsOf.IsZero() {
+		req.AsOf = now
+	}
+	if req.AsOf.After(now) {
+		err := errors.New("as_of cannot be in the future")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, ok := server.ownedAccount(ctx, uri.ID)
+	if !ok {
+		return
+	}
+
+	result, err := server.store.BalanceAsOfTx(ctx, db.BalanceAsOfTxParams{
+		AccountID: account.ID,
+		AsOf:      req.AsOf,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := accountBalanceResponse{
+		AccountID: account.ID,
+		Currency:  account.Currency,
+		AsOf:      req.AsOf,
+		Balance:   result.Balance,
+	}
+	if result.SnapshotDate.Valid {
+		rsp.SnapshotDate = result.SnapshotDate.Time.Format(time.DateOnly)
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
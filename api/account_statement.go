@@ -0,0 +1,257 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	db "github.com/AutomaticOrca/simplebank/db/sqlc"
+	"github.com/AutomaticOrca/simplebank/statement"
+	"github.com/AutomaticOrca/simplebank/token"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+type accountStatementRequest struct {
+	pageRequest
+	From time.Time `form:"from"`
+	To   time.Time `form:"to"`
+}
+
+// accountStatementResponse reports the balances at the start and end of the period.
+// Without from the opening balance is the balance the account was opened with,
+// and without to the closing balance is the current balance
+type accountStatementResponse struct {
+	AccountID      int64           `json:"account_id"`
+	Currency       string          `json:"currency"`
+	OpeningBalance int64           `json:"opening_balance"`
+	ClosingBalance int64           `json:"closing_balance"`
+	Entries        []statementLine `json:"entries"`
+	NextCursor     string          `json:"next_cursor,omitempty"`
+}
+
+// statementLine is an entry with the account balance right after it,
+// and the transfer that made it if there is one
+type statementLine struct {
+	ID                    int64     `json:"id"`
+	Amount                int64     `json:"amount"`
+	Balance               int64     `json:"balance"`
+	CreatedAt             time.Time `json:"created_at"`
+	TransferID            int64     `json:"transfer_id,omitempty"`
+	CounterpartyAccountID int64     `json:"counterparty_account_id,omitempty"`
+	Description           string    `json:"description,omitempty"`
+}
+
+func newStatementLine(row db.ListAccountStatementRow) statementLine {
+	line := statementLine{
+		ID:          row.ID,
+		Amount:      row.Amount,
+		Balance:     row.Balance,
+		CreatedAt:   row.CreatedAt,
+		TransferID:  row.TransferID.Int64,
+		Description: row.Description.String,
+	}
+	if row.TransferID.Valid {
+		line.CounterpartyAccountID = row.FromAccountID.Int64
+		if row.FromAccountID.Int64 == row.AccountID {
+			line.CounterpartyAccountID = row.ToAccountID.Int64
+		}
+	}
+	return line
+}
+
+// getAccountStatement lists the entries of one of the user's accounts, oldest first, with running balances
+func (server *Server) getAccountStatement(ctx *gin.Context) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req accountStatementRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if err := validatePeriod(req.From, req.To); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	cursor, err := req.parse()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, ok := server.ownedAccount(ctx, uri.ID)
+	if !ok {
+		return
+	}
+
+	arg := db.AccountStatementTxParams{
+		AccountID:       account.ID,
+		CursorCreatedAt: cursor.CreatedAt,
+		CursorID:        cursor.ID,
+		Limit:           req.limit(),
+		Offset:          req.offset(),
+	}
+	if !req.From.IsZero() {
+		arg.CreatedFrom = sql.NullTime{Time: req.From, Valid: true}
+	}
+	if !req.To.IsZero() {
+		arg.CreatedTo = sql.NullTime{Time: req.To, Valid: true}
+	}
+
+	result, err := server.store.AccountStatementTx(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	lines := make([]statementLine, len(result.Lines))
+	for i, row := range result.Lines {
+		lines[i] = newStatementLine(row)
+	}
+
+	response := accountStatementResponse{
+		AccountID:      account.ID,
+		Currency:       account.Currency,
+		OpeningBalance: result.OpeningBalance,
+		ClosingBalance: result.ClosingBalance,
+		Entries:        lines,
+	}
+	if !req.byOffset() {
+		response.Entries, response.NextCursor = nextPage(req.pageRequest, lines, func(line statementLine) (time.Time, int64) {
+			return line.CreatedAt, line.ID
+		})
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+const statementExportBatchSize = 500
+
+type exportStatementRequest struct {
+	Format string    `form:"format" binding:"required,oneof=csv ofx camt053"`
+	From   time.Time `form:"from"`
+	To     time.Time `form:"to"`
+}
+
+// exportAccountStatement streams the statement of one of the user's accounts as a file.
+// The period defaults to everything from the opening of the account until now
+func (server *Server) exportAccountStatement(ctx *gin.Context) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req exportStatementRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, ok := server.ownedAccount(ctx, uri.ID)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	if req.From.IsZero() {
+		req.From = account.CreatedAt
+	}
+	if req.To.IsZero() {
+		req.To = now
+	}
+	if err := validatePeriod(req.From, req.To); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	writer, err := statement.NewWriter(req.Format, ctx.Writer)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	header := statement.Header{
+		AccountID: account.ID,
+		Currency:  account.Currency,
+		From:      req.From,
+		To:        req.To,
+		CreatedAt: now,
+	}
+
+	started := false
+	err = server.store.ExportAccountStatementTx(ctx, db.ExportAccountStatementTxParams{
+		AccountID:   account.ID,
+		CreatedFrom: sql.NullTime{Time: req.From, Valid: true},
+		CreatedTo:   sql.NullTime{Time: req.To, Valid: true},
+		BatchSize:   statementExportBatchSize,
+		Begin: func(openingBalance, closingBalance int64) error {
+			header.OpeningBalance = openingBalance
+			header.ClosingBalance = closingBalance
+
+			filename := fmt.Sprintf("statement-%d-%s-%s.%s", account.ID,
+				req.From.UTC().Format("20060102"), req.To.UTC().Format("20060102"), statement.FileExtension(req.Format))
+			ctx.Header("Content-Type", statement.ContentType(req.Format))
+			ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+			ctx.Status(http.StatusOK)
+			started = true
+
+			return writer.WriteHeader(header)
+		},
+		Line: func(row db.ListAccountStatementRow) error {
+			line := newStatementLine(row)
+			return writer.WriteLine(statement.Line{
+				EntryID:               line.ID,
+				CreatedAt:             line.CreatedAt,
+				Amount:                line.Amount,
+				Balance:               line.Balance,
+				TransferID:            line.TransferID,
+				CounterpartyAccountID: line.CounterpartyAccountID,
+				Description:           line.Description,
+			})
+		},
+	})
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		if !started {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		// the status has been sent with the first bytes, so the response can only be cut short
+		log.Error().Err(err).Int64("account_id", account.ID).Msg("failed to export account statement")
+		ctx.Abort()
+	}
+}
+
+// ownedAccount loads an account and makes sure it belongs to the authenticated user
+func (server *Server) ownedAccount(ctx *gin.Context, accountID int64) (db.Account, bool) {
+	account, err := server.store.GetAccount(ctx, accountID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return account, false
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return account, false
+	}
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if account.Owner != authPayload.Username {
+		err := errors.New("account doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return account, false
+	}
+	return account, true
+}
+
+// validatePeriod checks that a period given by optional from and to bounds is not empty
+func validatePeriod(from, to time.Time) error {
+	if !from.IsZero() && !to.IsZero() && !to.After(from) {
+		return errors.New("to must be after from")
+	}
+	return nil
+}
@@ -0,0 +1,302 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/AutomaticOrca/simplebank/db/mock"
+	db "github.com/AutomaticOrca/simplebank/db/sqlc"
+	"github.com/AutomaticOrca/simplebank/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountStatementAPI(t *testing.T) {
+	user, _ := randomUserForTest(t)
+	account := randomAccount(user.Username)
+
+	createdAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	statement := db.AccountStatementTxResult{
+		OpeningBalance: 100,
+		ClosingBalance: 130,
+		Lines: []db.ListAccountStatementRow{
+			{
+				ID:            1,
+				AccountID:     account.ID,
+				Amount:        50,
+				CreatedAt:     createdAt,
+				Balance:       150,
+				TransferID:    sql.NullInt64{Int64: 10, Valid: true},
+				FromAccountID: sql.NullInt64{Int64: 7, Valid: true},
+				ToAccountID:   sql.NullInt64{Int64: account.ID, Valid: true},
+				Description:   sql.NullString{String: "rent", Valid: true},
+			},
+			{
+				ID:            2,
+				AccountID:     account.ID,
+				Amount:        -20,
+				CreatedAt:     createdAt.Add(time.Second),
+				Balance:       130,
+				TransferID:    sql.NullInt64{Int64: 11, Valid: true},
+				FromAccountID: sql.NullInt64{Int64: account.ID, Valid: true},
+				ToAccountID:   sql.NullInt64{Int64: 8, Valid: true},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		username      string
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: user.Username,
+			query:    "from=2024-03-01T00:00:00Z&to=2024-04-01T00:00:00Z",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				arg := db.AccountStatementTxParams{
+					AccountID:   account.ID,
+					CreatedFrom: sql.NullTime{Time: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+					CreatedTo:   sql.NullTime{Time: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+					Limit:       defaultPageSize + 1,
+				}
+				store.EXPECT().
+					AccountStatementTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(statement, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got accountStatementResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, account.Currency, got.Currency)
+				require.Equal(t, int64(100), got.OpeningBalance)
+				require.Equal(t, int64(130), got.ClosingBalance)
+				require.Empty(t, got.NextCursor)
+				require.Equal(t, []statementLine{
+					{ID: 1, Amount: 50, Balance: 150, CreatedAt: createdAt, TransferID: 10, CounterpartyAccountID: 7, Description: "rent"},
+					{ID: 2, Amount: -20, Balance: 130, CreatedAt: createdAt.Add(time.Second), TransferID: 11, CounterpartyAccountID: 8},
+				}, got.Entries)
+			},
+		},
+		{
+			name:     "ByOffset",
+			username: user.Username,
+			query:    "page_id=3&page_size=20",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				arg := db.AccountStatementTxParams{
+					AccountID: account.ID,
+					Limit:     20,
+					Offset:    40,
+				}
+				store.EXPECT().
+					AccountStatementTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.AccountStatementTxResult{OpeningBalance: 5, ClosingBalance: 5}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got accountStatementResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.NotNil(t, got.Entries)
+				require.Empty(t, got.Entries)
+			},
+		},
+		{
+			name:     "UnauthorizedUser",
+			username: "unauthorized_user",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().AccountStatementTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "AccountNotFound",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().AccountStatementTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "InvalidPeriod",
+			username: user.Username,
+			query:    "from=2024-04-01T00:00:00Z&to=2024-03-01T00:00:00Z",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "InvalidPageSize",
+			username: user.Username,
+			query:    "page_size=101",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%d/entries?%s", account.ID, tc.query)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestExportAccountStatementAPI(t *testing.T) {
+	user, _ := randomUserForTest(t)
+	account := randomAccount(user.Username)
+	account.Currency = util.USD
+
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	query := "from=2024-03-01T00:00:00Z&to=2024-04-01T00:00:00Z"
+
+	testCases := []struct {
+		name          string
+		username      string
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "CSV",
+			username: user.Username,
+			query:    "format=csv&" + query,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					ExportAccountStatementTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.ExportAccountStatementTxParams) error {
+						require.Equal(t, account.ID, arg.AccountID)
+						require.Equal(t, sql.NullTime{Time: from, Valid: true}, arg.CreatedFrom)
+						require.Equal(t, sql.NullTime{Time: to, Valid: true}, arg.CreatedTo)
+
+						require.NoError(t, arg.Begin(1000, 750))
+						return arg.Line(db.ListAccountStatementRow{
+							ID:            1,
+							AccountID:     account.ID,
+							Amount:        -250,
+							CreatedAt:     from.Add(time.Hour),
+							Balance:       750,
+							TransferID:    sql.NullInt64{Int64: 10, Valid: true},
+							FromAccountID: sql.NullInt64{Int64: account.ID, Valid: true},
+							ToAccountID:   sql.NullInt64{Int64: 8, Valid: true},
+						})
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "text/csv; charset=utf-8", recorder.Header().Get("Content-Type"))
+				require.Equal(t, fmt.Sprintf(`attachment; filename="statement-%d-20240301-20240401.csv"`, account.ID),
+					recorder.Header().Get("Content-Disposition"))
+				require.Equal(t, "date,entry_id,transfer_id,counterparty_account_id,description,amount,balance,currency\n"+
+					"2024-03-01T01:00:00Z,1,10,8,,-2.50,7.50,USD\n", recorder.Body.String())
+			},
+		},
+		{
+			name:     "UnsupportedFormat",
+			username: user.Username,
+			query:    "format=pdf",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "UnauthorizedUser",
+			username: "unauthorized_user",
+			query:    "format=ofx",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ExportAccountStatementTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "InternalError",
+			username: user.Username,
+			query:    "format=camt053&" + query,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					ExportAccountStatementTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%d/statement?%s", account.ID, tc.query)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -24,6 +24,33 @@ func TestCreateAccountAPI(t *testing.T) {
 	user, _ := randomUserForTest(t)
 	account := randomAccount(user.Username)
 
+	checking := db.Product{
+		Code:                   db.ProductChecking,
+		Name:                   "Checking",
+		AnnualInterestRate:     "0",
+		MaxAccountsPerCurrency: 1,
+	}
+	fees := []db.FeeSchedule{
+		{
+			ProductCode:  db.ProductChecking,
+			TransferType: db.TransferTypeP2P,
+			Currency:     account.Currency,
+			FlatFee:      25,
+			MaxFee:       sql.NullInt64{Int64: 500, Valid: true},
+		},
+	}
+
+	businessAccount := account
+	businessAccount.ProductCode = db.ProductBusiness
+	businessAccount.OverdraftLimit = 100000
+	business := db.Product{
+		Code:                   db.ProductBusiness,
+		Name:                   "Business",
+		AnnualInterestRate:     "0",
+		OverdraftLimit:         100000,
+		MaxAccountsPerCurrency: 3,
+	}
+
 	testCases := []struct {
 		name          string
 		body          gin.H
@@ -40,19 +67,30 @@ func TestCreateAccountAPI(t *testing.T) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				arg := db.CreateAccountParams{
-					Owner:    account.Owner,
-					Currency: account.Currency,
-					Balance:  0,
+				arg := db.CreateAccountTxParams{
+					Owner:       account.Owner,
+					Currency:    account.Currency,
+					ProductCode: db.ProductChecking,
 				}
 				store.EXPECT().
-					CreateAccount(gomock.Any(), gomock.Eq(arg)).
+					CreateAccountTx(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
-					Return(account, nil)
+					Return(db.CreateAccountTxResult{Account: account, Product: checking, Fees: fees}, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
-				requireBodyMatchAccount(t, recorder.Body, account)
+				requireBodyMatchAccount(t, bytes.NewBuffer(recorder.Body.Bytes()), account)
+
+				var got createAccountResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, checking.Code, got.Product.Code)
+				require.Equal(t, checking.MaxAccountsPerCurrency, got.Product.MaxAccountsPerCurrency)
+				require.Len(t, got.Product.Fees, 1)
+				require.Equal(t, db.TransferTypeP2P, got.Product.Fees[0].TransferType)
+				require.Equal(t, int64(25), got.Product.Fees[0].FlatFee)
+				require.NotNil(t, got.Product.Fees[0].MaxFee)
+				require.Equal(t, int64(500), *got.Product.Fees[0].MaxFee)
 			},
 		},
 		{
@@ -78,9 +116,9 @@ func TestCreateAccountAPI(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					CreateAccount(gomock.Any(), gomock.Any()).
+					CreateAccountTx(gomock.Any(), gomock.Any()).
 					Times(1).
-					Return(db.Account{}, sql.ErrConnDone)
+					Return(db.CreateAccountTxResult{}, sql.ErrConnDone)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
@@ -100,6 +138,97 @@ func TestCreateAccountAPI(t *testing.T) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 		},
+		{
+			name: "Savings",
+			body: gin.H{
+				"currency": account.Currency,
+				"product":  db.ProductSavings,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CreateAccountTxParams{
+					Owner:       account.Owner,
+					Currency:    account.Currency,
+					ProductCode: db.ProductSavings,
+				}
+				store.EXPECT().
+					CreateAccountTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.CreateAccountTxResult{Account: account}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Business",
+			body: gin.H{
+				"currency": account.Currency,
+				"product":  db.ProductBusiness,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CreateAccountTxParams{
+					Owner:       account.Owner,
+					Currency:    account.Currency,
+					ProductCode: db.ProductBusiness,
+				}
+				store.EXPECT().
+					CreateAccountTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.CreateAccountTxResult{Account: businessAccount, Product: business}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got createAccountResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, businessAccount, got.Account)
+				require.Equal(t, business.OverdraftLimit, got.Product.OverdraftLimit)
+				require.Empty(t, got.Product.Fees)
+			},
+		},
+		{
+			name: "AccountLimitReached",
+			body: gin.H{
+				"currency": account.Currency,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAccountTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CreateAccountTxResult{}, db.ErrAccountLimitReached)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidProduct",
+			body: gin.H{
+				"currency": account.Currency,
+				"product":  "invalid",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAccountTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
 	}
 
 	for i := range testCases {
@@ -112,7 +241,7 @@ func TestCreateAccountAPI(t *testing.T) {
 			store := mockdb.NewMockStore(ctrl)
 			tc.buildStubs(store)
 
-			server := newTestServer(t, store, nil)
+			server := newTestServer(t, store, nil, nil)
 			recorder := httptest.NewRecorder()
 
 			data, err := json.Marshal(tc.body)
@@ -133,6 +262,9 @@ func TestGetAccountAPI(t *testing.T) {
 	user, _ := randomUserForTest(t)
 	account := randomAccount(user.Username)
 
+	heldAccount := account
+	heldAccount.HeldAmount = account.Balance / 2
+
 	testCases := []struct {
 		name          string
 		accountID     int64
@@ -157,6 +289,28 @@ func TestGetAccountAPI(t *testing.T) {
 				requireBodyMatchAccount(t, recorder.Body, account)
 			},
 		},
+		{
+			name:      "WithHolds",
+			accountID: account.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(heldAccount, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got accountResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, heldAccount, got.Account)
+				require.Equal(t, heldAccount.Balance-heldAccount.HeldAmount, got.AvailableBalance)
+			},
+		},
 		{
 			name:      "UnauthorizedUser",
 			accountID: account.ID,
@@ -240,7 +394,7 @@ func TestGetAccountAPI(t *testing.T) {
 			store := mockdb.NewMockStore(ctrl)
 			tc.buildStubs(store)
 
-			server := newTestServer(t, store, nil)
+			server := newTestServer(t, store, nil, nil)
 			recorder := httptest.NewRecorder()
 
 			url := fmt.Sprintf("/accounts/%d", tc.accountID)
@@ -376,7 +530,7 @@ func TestListAccountsAPI(t *testing.T) {
 			store := mockdb.NewMockStore(ctrl)
 			tc.buildStubs(store)
 
-			server := newTestServer(t, store, nil)
+			server := newTestServer(t, store, nil, nil)
 			recorder := httptest.NewRecorder()
 
 			url := fmt.Sprintf("/accounts?page_id=%d&page_size=%d", tc.query.pageID, tc.query.pageSize)
@@ -390,12 +544,124 @@ func TestListAccountsAPI(t *testing.T) {
 	}
 }
 
+func TestListAccountsByCursorAPI(t *testing.T) {
+	user, _ := randomUserForTest(t)
+
+	n := 6
+	createdAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	accounts := make([]db.Account, n)
+	for i := 0; i < n; i++ {
+		accounts[i] = randomAccount(user.Username)
+		accounts[i].CreatedAt = createdAt.Add(time.Duration(i) * time.Microsecond)
+	}
+	last := accounts[4]
+	cursor := encodeCursor(last.CreatedAt, last.ID)
+
+	testCases := []struct {
+		name          string
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "FirstPage",
+			query: "page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListAccountsParams{
+					Owner: user.Username,
+					Limit: 6,
+				}
+				store.EXPECT().
+					ListAccounts(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(accounts, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got listAccountsResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, accounts[:5], got.Accounts)
+				require.Equal(t, cursor, got.NextCursor)
+			},
+		},
+		{
+			name:  "LastPage",
+			query: "page_size=5&cursor=" + cursor,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListAccountsParams{
+					Owner:           user.Username,
+					CursorCreatedAt: sql.NullTime{Time: last.CreatedAt, Valid: true},
+					CursorID:        sql.NullInt64{Int64: last.ID, Valid: true},
+					Limit:           6,
+				}
+				store.EXPECT().
+					ListAccounts(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(accounts[5:], nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got map[string]json.RawMessage
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.NotContains(t, got, "next_cursor")
+			},
+		},
+		{
+			name:  "InvalidCursor",
+			query: "cursor=not-a-cursor",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccounts(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "CursorWithPageID",
+			query: "page_id=2&cursor=" + cursor,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccounts(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/accounts?"+tc.query, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
 func randomAccount(owner string) db.Account {
 	return db.Account{
-		ID:       util.RandomInt(1, 1000),
-		Owner:    owner,
-		Balance:  util.RandomMoney(),
-		Currency: util.RandomCurrency(),
+		ID:          util.RandomInt(1, 1000),
+		Owner:       owner,
+		Balance:     util.RandomMoney(),
+		Currency:    util.RandomCurrency(),
+		ProductCode: db.ProductChecking,
 	}
 }
 
@@ -427,7 +693,19 @@ func addAuthorization(
 	username string,
 	duration time.Duration,
 ) {
-	token, payload, err := tokenMaker.CreateToken(username, duration)
+	addRoleAuthorization(t, request, tokenMaker, authorizationType, username, util.DepositorRole, duration)
+}
+
+func addRoleAuthorization(
+	t *testing.T,
+	request *http.Request,
+	tokenMaker token.Maker,
+	authorizationType string,
+	username string,
+	role string,
+	duration time.Duration,
+) {
+	token, payload, err := tokenMaker.CreateToken(username, role, duration)
 	require.NoError(t, err)
 	require.NotEmpty(t, payload)
 
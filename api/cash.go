@@ -0,0 +1,144 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	db "github.com/AutomaticOrca/simplebank/db/sqlc"
+	"github.com/AutomaticOrca/simplebank/token"
+	"github.com/AutomaticOrca/simplebank/util"
+	"github.com/AutomaticOrca/simplebank/worker"
+	"github.com/gin-gonic/gin"
+)
+
+type cashMovementUri struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type cashMovementRequest struct {
+	Amount   int64  `json:"amount" binding:"required,gt=0"`
+	Currency string `json:"currency" binding:"required,currency"`
+	// Reference is free text such as a teller receipt number, kept with the movement
+	Reference string `json:"reference" binding:"max=140"`
+}
+
+// createDeposit pays cash into an account. Only bankers can deposit, into any account
+func (server *Server) createDeposit(ctx *gin.Context) {
+	server.createCashMovement(ctx, db.CashMovementDeposit)
+}
+
+// createWithdrawal pays cash out of an account. Only bankers can withdraw, from any account
+func (server *Server) createWithdrawal(ctx *gin.Context) {
+	server.createCashMovement(ctx, db.CashMovementWithdrawal)
+}
+
+func (server *Server) createCashMovement(ctx *gin.Context, kind string) {
+	var uri cashMovementUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req cashMovementRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	result, err := server.store.CashMovementTx(ctx, db.CashMovementTxParams{
+		AccountID: uri.ID,
+		Kind:      kind,
+		Amount:    req.Amount,
+		Currency:  req.Currency,
+		Reference: req.Reference,
+		CreatedBy: authPayload.Username,
+	})
+	if err != nil {
+		ctx.JSON(transferErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// createTopUp asks the payment rail to pull money into one of the caller's accounts.
+// The account is credited once the rail settles the top-up
+func (server *Server) createTopUp(ctx *gin.Context) {
+	server.createRailPayment(ctx, db.CashMovementDeposit)
+}
+
+// createPayout asks the payment rail to pay money out of one of the caller's accounts.
+// The account is debited straight away, and credited back if the rail returns the payout
+func (server *Server) createPayout(ctx *gin.Context) {
+	server.createRailPayment(ctx, db.CashMovementWithdrawal)
+}
+
+func (server *Server) createRailPayment(ctx *gin.Context, kind string) {
+	var uri cashMovementUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req cashMovementRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, ok := server.ownedAccount(ctx, uri.ID)
+	if !ok {
+		return
+	}
+
+	result, err := server.store.CreateRailPaymentTx(ctx, db.CreateRailPaymentTxParams{
+		AccountID: account.ID,
+		Kind:      kind,
+		Amount:    req.Amount,
+		Currency:  req.Currency,
+		Reference: req.Reference,
+		CreatedBy: account.Owner,
+		AfterCreate: func(cashMovement db.CashMovement) error {
+			taskPayload := &worker.PayloadSubmitRailPayment{CashMovementID: cashMovement.ID}
+			// the worker retries until the transaction has committed, see ProcessTaskSubmitRailPayment
+			return server.taskDistributor.DistributeTaskSubmitRailPayment(ctx, taskPayload)
+		},
+	})
+	if err != nil {
+		ctx.JSON(transferErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, result)
+}
+
+// getCashMovement returns a cash movement, to follow a top-up or payout until it is settled or returned.
+// Owners see the movements of their accounts, bankers see every movement
+func (server *Server) getCashMovement(ctx *gin.Context) {
+	var uri cashMovementUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	cashMovement, err := server.store.GetCashMovement(ctx, uri.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if authPayload.Role != util.BankerRole {
+		if _, ok := server.ownedAccount(ctx, cashMovement.AccountID); !ok {
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusOK, cashMovement)
+}
@@ -0,0 +1,414 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/AutomaticOrca/simplebank/db/mock"
+	db "github.com/AutomaticOrca/simplebank/db/sqlc"
+	"github.com/AutomaticOrca/simplebank/util"
+	"github.com/AutomaticOrca/simplebank/worker"
+	mockwk "github.com/AutomaticOrca/simplebank/worker/mock"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCashMovementAPI(t *testing.T) {
+	banker, _ := randomUserForTest(t)
+	user, _ := randomUserForTest(t)
+
+	account := randomAccount(user.Username)
+	account.Currency = util.USD
+
+	testCases := []struct {
+		name          string
+		path          string
+		body          gin.H
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Deposit",
+			path: "deposits",
+			body: gin.H{"amount": 500, "currency": util.USD, "reference": "teller 12"},
+			role: util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CashMovementTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CashMovementTxParams) (db.CashMovementTxResult, error) {
+						require.Equal(t, account.ID, arg.AccountID)
+						require.Equal(t, db.CashMovementDeposit, arg.Kind)
+						require.Equal(t, int64(500), arg.Amount)
+						require.Equal(t, util.USD, arg.Currency)
+						require.Equal(t, "teller 12", arg.Reference)
+						require.Equal(t, banker.Username, arg.CreatedBy)
+
+						return db.CashMovementTxResult{
+							CashMovement: db.CashMovement{ID: 1, AccountID: arg.AccountID, Kind: arg.Kind, Amount: arg.Amount},
+							Account:      account,
+						}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got db.CashMovementTxResult
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, db.CashMovementDeposit, got.CashMovement.Kind)
+				require.Equal(t, account.ID, got.Account.ID)
+			},
+		},
+		{
+			name: "Withdrawal",
+			path: "withdrawals",
+			body: gin.H{"amount": 500, "currency": util.USD},
+			role: util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CashMovementTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CashMovementTxParams) (db.CashMovementTxResult, error) {
+						require.Equal(t, db.CashMovementWithdrawal, arg.Kind)
+						return db.CashMovementTxResult{}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "InsufficientFunds",
+			path: "withdrawals",
+			body: gin.H{"amount": 500, "currency": util.USD},
+			role: util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CashMovementTx(gomock.Any(), gomock.Any()).Times(1).Return(db.CashMovementTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "CurrencyMismatch",
+			path: "deposits",
+			body: gin.H{"amount": 500, "currency": util.EUR},
+			role: util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CashMovementTx(gomock.Any(), gomock.Any()).Times(1).Return(db.CashMovementTxResult{}, db.ErrCurrencyMismatch)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidAmount",
+			path: "deposits",
+			body: gin.H{"amount": -5, "currency": util.USD},
+			role: util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CashMovementTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Depositor",
+			path: "deposits",
+			body: gin.H{"amount": 500, "currency": util.USD},
+			role: util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CashMovementTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/accounts/%d/%s", account.ID, tc.path)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addRoleAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, banker.Username, tc.role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRailPaymentAPI(t *testing.T) {
+	user, _ := randomUserForTest(t)
+	other, _ := randomUserForTest(t)
+
+	account := randomAccount(user.Username)
+	account.Currency = util.USD
+
+	cashMovement := db.CashMovement{
+		ID:        util.RandomInt(1, 1000),
+		AccountID: account.ID,
+		Kind:      db.CashMovementDeposit,
+		Amount:    500,
+		Channel:   db.CashChannelRail,
+		Status:    db.CashMovementStatusPending,
+	}
+
+	testCases := []struct {
+		name          string
+		path          string
+		username      string
+		buildStubs    func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "TopUp",
+			path:     "top_ups",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					CreateRailPaymentTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateRailPaymentTxParams) (db.CashMovementTxResult, error) {
+						require.Equal(t, account.ID, arg.AccountID)
+						require.Equal(t, db.CashMovementDeposit, arg.Kind)
+						require.Equal(t, int64(500), arg.Amount)
+						require.Equal(t, user.Username, arg.CreatedBy)
+
+						err := arg.AfterCreate(cashMovement)
+						return db.CashMovementTxResult{CashMovement: cashMovement, Account: account}, err
+					})
+				distributor.EXPECT().
+					DistributeTaskSubmitRailPayment(gomock.Any(), gomock.Eq(&worker.PayloadSubmitRailPayment{
+						CashMovementID: cashMovement.ID,
+					}), gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+
+				var got db.CashMovementTxResult
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, db.CashMovementStatusPending, got.CashMovement.Status)
+			},
+		},
+		{
+			name:     "PayoutInsufficientFunds",
+			path:     "payouts",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					CreateRailPaymentTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateRailPaymentTxParams) (db.CashMovementTxResult, error) {
+						require.Equal(t, db.CashMovementWithdrawal, arg.Kind)
+						return db.CashMovementTxResult{}, db.ErrInsufficientFunds
+					})
+				distributor.EXPECT().DistributeTaskSubmitRailPayment(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name:     "UnauthorizedUser",
+			path:     "top_ups",
+			username: other.Username,
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().CreateRailPaymentTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			distributor := mockwk.NewMockTaskDistributor(ctrl)
+			tc.buildStubs(store, distributor)
+
+			server := newTestServer(t, store, nil, distributor)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{"amount": 500, "currency": util.USD})
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/accounts/%d/%s", account.ID, tc.path)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRailPaymentAPIDisabled(t *testing.T) {
+	user, _ := randomUserForTest(t)
+	account := randomAccount(user.Username)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().CreateRailPaymentTx(gomock.Any(), gomock.Any()).Times(0)
+
+	// without a payment rail there is nothing to top up from or pay out to
+	config := util.Config{
+		TokenSymmetricKey:   util.RandomString(32),
+		AccessTokenDuration: time.Minute,
+	}
+	server, err := NewServer(config, store, nil, nil)
+	require.NoError(t, err)
+
+	for _, path := range []string{"top_ups", "payouts"} {
+		data, err := json.Marshal(gin.H{"amount": 500, "currency": account.Currency})
+		require.NoError(t, err)
+
+		url := fmt.Sprintf("/accounts/%d/%s", account.ID, path)
+		request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+		require.NoError(t, err)
+
+		recorder := httptest.NewRecorder()
+		addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+		server.router.ServeHTTP(recorder, request)
+		require.Equal(t, http.StatusNotFound, recorder.Code)
+	}
+}
+
+func TestGetCashMovementAPI(t *testing.T) {
+	user, _ := randomUserForTest(t)
+	other, _ := randomUserForTest(t)
+
+	account := randomAccount(user.Username)
+	cashMovement := db.CashMovement{
+		ID:        util.RandomInt(1, 1000),
+		AccountID: account.ID,
+		Kind:      db.CashMovementWithdrawal,
+		Amount:    500,
+		Channel:   db.CashChannelRail,
+		Status:    db.CashMovementStatusReturned,
+	}
+
+	testCases := []struct {
+		name          string
+		username      string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "Owner",
+			username: user.Username,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetCashMovement(gomock.Any(), gomock.Eq(cashMovement.ID)).Times(1).Return(cashMovement, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got db.CashMovement
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, cashMovement, got)
+			},
+		},
+		{
+			name:     "Banker",
+			username: other.Username,
+			role:     util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetCashMovement(gomock.Any(), gomock.Eq(cashMovement.ID)).Times(1).Return(cashMovement, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "UnauthorizedUser",
+			username: other.Username,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetCashMovement(gomock.Any(), gomock.Eq(cashMovement.ID)).Times(1).Return(cashMovement, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "NotFound",
+			username: user.Username,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetCashMovement(gomock.Any(), gomock.Eq(cashMovement.ID)).Times(1).Return(db.CashMovement{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/cash_movements/%d", cashMovement.ID)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addRoleAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, tc.role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
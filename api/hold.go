@@ -0,0 +1,185 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	db "github.com/AutomaticOrca/simplebank/db/sqlc"
+	"github.com/AutomaticOrca/simplebank/token"
+	"github.com/gin-gonic/gin"
+)
+
+type authorizeHoldRequest struct {
+	FromAccountID int64  `json:"from_account_id" binding:"required,min=1"`
+	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1,nefield=FromAccountID"`
+	Amount        int64  `json:"amount" binding:"required,gt=0"`
+	Currency      string `json:"currency" binding:"required,currency"`
+	ToCurrency    string `json:"to_currency" binding:"omitempty,currency"`
+}
+
+// authorizeHold places a hold on an account of the authenticated user in favour of the to account.
+// The hold expires after config.HoldDuration unless it is captured or voided before
+func (server *Server) authorizeHold(ctx *gin.Context) {
+	var req authorizeHoldRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	fromAccount, valid := server.validAccount(ctx, req.FromAccountID, req.Currency)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if fromAccount.Owner != authPayload.Username {
+		err := errors.New("from account doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	toCurrency := req.Currency
+	if req.ToCurrency != "" {
+		toCurrency = req.ToCurrency
+	}
+	_, valid = server.validAccount(ctx, req.ToAccountID, toCurrency)
+	if !valid {
+		return
+	}
+
+	result, err := server.store.AuthorizeHoldTx(ctx, db.AuthorizeHoldTxParams{
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		ToCurrency:    toCurrency,
+		ExpiresAt:     time.Now().Add(server.config.HoldDuration),
+		Limits:        server.transferLimits(),
+	})
+	if err != nil {
+		ctx.JSON(transferErrorStatus(err), transferErrorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result.Hold)
+}
+
+type holdURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type captureHoldRequest struct {
+	// Amount may be less than the hold, the rest is released. Leave it out to capture the whole hold
+	Amount int64 `json:"amount" binding:"omitempty,gt=0"`
+}
+
+// captureHold settles a hold. Only the owner of the to account, who is being paid, can capture it
+func (server *Server) captureHold(ctx *gin.Context) {
+	var uri holdURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req captureHoldRequest
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+	}
+
+	hold, valid := server.validHold(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	toAccount, err := server.store.GetAccount(ctx, hold.ToAccountID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if toAccount.Owner != authPayload.Username {
+		err := errors.New("only the owner of the to account can capture the hold")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	result, err := server.store.CaptureHoldTx(ctx, db.CaptureHoldTxParams{
+		HoldID: hold.ID,
+		Amount: req.Amount,
+	})
+	if err != nil {
+		ctx.JSON(holdErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// voidHold cancels a hold. Either side of the hold can void it
+func (server *Server) voidHold(ctx *gin.Context) {
+	var uri holdURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	hold, valid := server.validHold(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	fromAccount, err := server.store.GetAccount(ctx, hold.FromAccountID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if fromAccount.Owner != authPayload.Username {
+		toAccount, err := server.store.GetAccount(ctx, hold.ToAccountID)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		if toAccount.Owner != authPayload.Username {
+			err := errors.New("hold doesn't involve an account of the authenticated user")
+			ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+	}
+
+	result, err := server.store.VoidHoldTx(ctx, hold.ID)
+	if err != nil {
+		ctx.JSON(holdErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result.Hold)
+}
+
+func (server *Server) validHold(ctx *gin.Context, holdID int64) (db.Hold, bool) {
+	hold, err := server.store.GetHold(ctx, holdID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return hold, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return hold, false
+	}
+	return hold, true
+}
+
+// holdErrorStatus maps errors of the hold transactions to HTTP status codes
+func holdErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, db.ErrHoldNotActive):
+		return http.StatusConflict
+	case errors.Is(err, db.ErrCaptureExceedsHold):
+		return http.StatusUnprocessableEntity
+	}
+	return transferErrorStatus(err)
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	db "github.com/AutomaticOrca/simplebank/db/sqlc"
+	"github.com/AutomaticOrca/simplebank/val"
+	"github.com/gin-gonic/gin"
+)
+
+const idempotencyKeyHeader = "Idempotency-Key"
+
+var errIdempotencyKeyReused = errors.New("idempotency key has already been used with a different request")
+
+// hashRequest returns a stable fingerprint of a bound request,
+// so that a replay can be told apart from a different request that reuses the same key
+func hashRequest(req any) (string, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// checkIdempotencyKey handles the Idempotency-Key header of a request that moves money.
+// It returns the key to store with the result, or nil if the header is not set.
+// If ok is false, the request has been answered already, either with an error or with the replayed response
+func (server *Server) checkIdempotencyKey(ctx *gin.Context, username string, req any) (arg *db.CreateIdempotencyKeyParams, ok bool) {
+	key := ctx.GetHeader(idempotencyKeyHeader)
+	if key == "" {
+		return nil, true
+	}
+
+	if err := val.ValidateIdempotencyKey(key); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("invalid %s header: %w", idempotencyKeyHeader, err)))
+		return nil, false
+	}
+
+	requestHash, err := hashRequest(req)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return nil, false
+	}
+
+	if server.replayIdempotentRequest(ctx, username, key, requestHash) {
+		return nil, false
+	}
+
+	return &db.CreateIdempotencyKeyParams{
+		Username:    username,
+		Key:         key,
+		RequestHash: requestHash,
+		ExpiresAt:   time.Now().Add(server.config.IdempotencyKeyDuration),
+	}, true
+}
+
+// replayIdempotentRequest writes the stored response of a previous request made with the same idempotency key.
+// It returns false if there is no unexpired record for the key, in which case nothing is written
+func (server *Server) replayIdempotentRequest(ctx *gin.Context, username, key, requestHash string) bool {
+	record, err := server.store.GetIdempotencyKey(ctx, db.GetIdempotencyKeyParams{
+		Username: username,
+		Key:      key,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return true
+	}
+
+	if record.RequestHash != requestHash {
+		ctx.JSON(http.StatusConflict, errorResponse(errIdempotencyKeyReused))
+		return true
+	}
+
+	ctx.Data(http.StatusOK, "application/json; charset=utf-8", record.Response)
+	return true
+}
+
+// replayConcurrentRequest handles db.ErrIdempotencyKeyExists,
+// which means a concurrent request with the same key committed first
+func (server *Server) replayConcurrentRequest(ctx *gin.Context, arg *db.CreateIdempotencyKeyParams, err error) {
+	if !server.replayIdempotentRequest(ctx, arg.Username, arg.Key, arg.RequestHash) {
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+	}
+}
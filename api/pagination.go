@@ -0,0 +1,104 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultPageSize = 10
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// pageRequest is embedded by list requests. A request with page_id is paged by offset
+// and answered with a plain array, as before cursors were added. Without page_id
+// the list is paged by cursor: the response carries a next_cursor to pass as cursor
+// to get the following page, which stays stable when new rows are added
+type pageRequest struct {
+	PageID   int32  `form:"page_id" binding:"omitempty,min=1"`
+	PageSize int32  `form:"page_size" binding:"omitempty,min=5,max=100"`
+	Cursor   string `form:"cursor" binding:"omitempty,max=128"`
+}
+
+// pageCursor is the position of the last row of a page in (created_at, id) order
+type pageCursor struct {
+	CreatedAt sql.NullTime
+	ID        sql.NullInt64
+}
+
+// parse checks the paging parameters and returns the cursor to list after, which is
+// empty for the first page and in offset mode
+func (req *pageRequest) parse() (pageCursor, error) {
+	if req.PageSize == 0 {
+		req.PageSize = defaultPageSize
+	}
+	if req.PageID != 0 && req.Cursor != "" {
+		return pageCursor{}, errors.New("page_id and cursor cannot be used together")
+	}
+	if req.Cursor == "" {
+		return pageCursor{}, nil
+	}
+	return decodeCursor(req.Cursor)
+}
+
+func (req pageRequest) byOffset() bool {
+	return req.PageID != 0
+}
+
+// limit is the number of rows to fetch. In cursor mode one extra row is fetched
+// to know whether there is a next page
+func (req pageRequest) limit() int32 {
+	if req.byOffset() {
+		return req.PageSize
+	}
+	return req.PageSize + 1
+}
+
+func (req pageRequest) offset() int32 {
+	if req.byOffset() {
+		return (req.PageID - 1) * req.PageSize
+	}
+	return 0
+}
+
+// nextPage drops the extra row fetched in cursor mode and returns the cursor of the next page,
+// or an empty string on the last page
+func nextPage[T any](req pageRequest, rows []T, key func(T) (time.Time, int64)) ([]T, string) {
+	if len(rows) <= int(req.PageSize) {
+		return rows, ""
+	}
+	rows = rows[:req.PageSize]
+	return rows, encodeCursor(key(rows[len(rows)-1]))
+}
+
+func encodeCursor(createdAt time.Time, id int64) string {
+	raw := fmt.Sprintf("%s,%d", createdAt.UTC().Format(time.RFC3339Nano), id)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(cursor string) (pageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return pageCursor{}, errInvalidCursor
+	}
+	createdAtText, idText, ok := strings.Cut(string(raw), ",")
+	if !ok {
+		return pageCursor{}, errInvalidCursor
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, createdAtText)
+	if err != nil {
+		return pageCursor{}, errInvalidCursor
+	}
+	id, err := strconv.ParseInt(idText, 10, 64)
+	if err != nil {
+		return pageCursor{}, errInvalidCursor
+	}
+	return pageCursor{
+		CreatedAt: sql.NullTime{Time: createdAt, Valid: true},
+		ID:        sql.NullInt64{Int64: id, Valid: true},
+	}, nil
+}
@@ -0,0 +1,246 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	db "github.com/AutomaticOrca/simplebank/db/sqlc"
+	"github.com/AutomaticOrca/simplebank/token"
+	"github.com/AutomaticOrca/simplebank/val"
+	"github.com/AutomaticOrca/simplebank/worker"
+	"github.com/gin-gonic/gin"
+)
+
+type createPaymentRequestRequest struct {
+	// ToAccountID is the requester's account that receives the money
+	ToAccountID int64  `json:"to_account_id" binding:"required,min=1"`
+	Payer       string `json:"payer" binding:"required"`
+	Amount      int64  `json:"amount" binding:"required,gt=0"`
+	Currency    string `json:"currency" binding:"required,currency"`
+	Description string `json:"description"`
+}
+
+// createPaymentRequest asks another user for money. The payer is notified by the worker
+func (server *Server) createPaymentRequest(ctx *gin.Context) {
+	var req createPaymentRequestRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if err := val.ValidateUsername(req.Payer); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("invalid payer: %w", err)))
+		return
+	}
+	if err := val.ValidateTransferDescription(req.Description); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("invalid description: %w", err)))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if req.Payer == authPayload.Username {
+		err := errors.New("cannot request money from yourself")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	toAccount, valid := server.validAccount(ctx, req.ToAccountID, req.Currency)
+	if !valid {
+		return
+	}
+	if toAccount.Owner != authPayload.Username {
+		err := errors.New("account doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	_, err := server.store.GetUser(ctx, req.Payer)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(errors.New("payer not found")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	paymentRequest, err := server.store.CreatePaymentRequestTx(ctx, db.CreatePaymentRequestTxParams{
+		CreatePaymentRequestParams: db.CreatePaymentRequestParams{
+			Requester:   authPayload.Username,
+			Payer:       req.Payer,
+			ToAccountID: req.ToAccountID,
+			Amount:      req.Amount,
+			Currency:    req.Currency,
+			Description: req.Description,
+			ExpiresAt:   time.Now().Add(server.config.PaymentRequestDuration),
+		},
+		AfterCreate: server.notifyPaymentRequest(ctx),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, paymentRequest)
+}
+
+type listPaymentRequestsRequest struct {
+	// Role picks the requests the user has to pay (the default), or the ones they sent
+	Role     string `form:"role" binding:"omitempty,oneof=payer requester"`
+	PageID   int32  `form:"page_id" binding:"required,min=1"`
+	PageSize int32  `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+func (server *Server) listPaymentRequests(ctx *gin.Context) {
+	var req listPaymentRequestsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	var paymentRequests []db.PaymentRequest
+	var err error
+	if req.Role == "requester" {
+		paymentRequests, err = server.store.ListPaymentRequestsByRequester(ctx, db.ListPaymentRequestsByRequesterParams{
+			Requester: authPayload.Username,
+			Limit:     req.PageSize,
+			Offset:    (req.PageID - 1) * req.PageSize,
+		})
+	} else {
+		paymentRequests, err = server.store.ListPaymentRequestsByPayer(ctx, db.ListPaymentRequestsByPayerParams{
+			Payer:  authPayload.Username,
+			Limit:  req.PageSize,
+			Offset: (req.PageID - 1) * req.PageSize,
+		})
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if paymentRequests == nil {
+		paymentRequests = []db.PaymentRequest{}
+	}
+	ctx.JSON(http.StatusOK, paymentRequests)
+}
+
+type paymentRequestURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type acceptPaymentRequestRequest struct {
+	FromAccountID int64 `json:"from_account_id" binding:"required,min=1"`
+}
+
+// acceptPaymentRequest pays a request from one of the payer's accounts in the requested currency
+func (server *Server) acceptPaymentRequest(ctx *gin.Context) {
+	var uri paymentRequestURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req acceptPaymentRequestRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	paymentRequest, valid := server.validPaymentRequest(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	fromAccount, valid := server.validAccount(ctx, req.FromAccountID, paymentRequest.Currency)
+	if !valid {
+		return
+	}
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if fromAccount.Owner != authPayload.Username {
+		err := errors.New("from account doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	result, err := server.store.AcceptPaymentRequestTx(ctx, db.AcceptPaymentRequestTxParams{
+		PaymentRequestID: paymentRequest.ID,
+		FromAccountID:    fromAccount.ID,
+		Limits:           server.transferLimits(),
+		AfterAccept:      server.notifyPaymentRequest(ctx),
+	})
+	if err != nil {
+		ctx.JSON(paymentRequestErrorStatus(err), transferErrorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (server *Server) declinePaymentRequest(ctx *gin.Context) {
+	var uri paymentRequestURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	paymentRequest, valid := server.validPaymentRequest(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	paymentRequest, err := server.store.DeclinePaymentRequestTx(ctx, db.DeclinePaymentRequestTxParams{
+		PaymentRequestID: paymentRequest.ID,
+		AfterDecline:     server.notifyPaymentRequest(ctx),
+	})
+	if err != nil {
+		ctx.JSON(paymentRequestErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, paymentRequest)
+}
+
+// validPaymentRequest loads a payment request and makes sure the authenticated user is the one asked to pay it
+func (server *Server) validPaymentRequest(ctx *gin.Context, paymentRequestID int64) (db.PaymentRequest, bool) {
+	paymentRequest, err := server.store.GetPaymentRequest(ctx, paymentRequestID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return paymentRequest, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return paymentRequest, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if paymentRequest.Payer != authPayload.Username {
+		err := errors.New("payment request isn't addressed to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return paymentRequest, false
+	}
+
+	return paymentRequest, true
+}
+
+// notifyPaymentRequest returns the callback that has the worker tell both users about the request's new status
+func (server *Server) notifyPaymentRequest(ctx *gin.Context) func(paymentRequest db.PaymentRequest) error {
+	return func(paymentRequest db.PaymentRequest) error {
+		taskPayload := &worker.PayloadSendPaymentRequestNotification{
+			PaymentRequestID: paymentRequest.ID,
+			Status:           paymentRequest.Status,
+		}
+		// the worker retries until the transaction has committed, see ProcessTaskSendPaymentRequestNotification
+		return server.taskDistributor.DistributeTaskSendPaymentRequestNotification(ctx, taskPayload)
+	}
+}
+
+// paymentRequestErrorStatus maps an error of a payment request transaction to a HTTP status code
+func paymentRequestErrorStatus(err error) int {
+	if errors.Is(err, db.ErrPaymentRequestNotPending) {
+		return http.StatusConflict
+	}
+	return transferErrorStatus(err)
+}
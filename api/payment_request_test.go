@@ -0,0 +1,401 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/AutomaticOrca/simplebank/db/mock"
+	db "github.com/AutomaticOrca/simplebank/db/sqlc"
+	"github.com/AutomaticOrca/simplebank/util"
+	"github.com/AutomaticOrca/simplebank/worker"
+	mockwk "github.com/AutomaticOrca/simplebank/worker/mock"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreatePaymentRequestAPI(t *testing.T) {
+	requester, _ := randomUserForTest(t)
+	payer, _ := randomUserForTest(t)
+
+	account := randomAccount(requester.Username)
+	account.Currency = util.USD
+
+	paymentRequest := db.PaymentRequest{
+		ID:          util.RandomInt(1, 1000),
+		Requester:   requester.Username,
+		Payer:       payer.Username,
+		ToAccountID: account.ID,
+		Amount:      100,
+		Currency:    util.USD,
+		Status:      db.PaymentRequestStatusPending,
+	}
+
+	body := gin.H{
+		"to_account_id": account.ID,
+		"payer":         payer.Username,
+		"amount":        100,
+		"currency":      util.USD,
+		"description":   "dinner",
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		username      string
+		buildStubs    func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			body:     body,
+			username: requester.Username,
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(payer.Username)).Times(1).Return(payer, nil)
+				store.EXPECT().
+					CreatePaymentRequestTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreatePaymentRequestTxParams) (db.PaymentRequest, error) {
+						require.Equal(t, requester.Username, arg.Requester)
+						require.Equal(t, payer.Username, arg.Payer)
+						require.Equal(t, "dinner", arg.Description)
+						require.WithinDuration(t, time.Now().Add(time.Hour), arg.ExpiresAt, time.Minute)
+
+						err := arg.AfterCreate(paymentRequest)
+						return paymentRequest, err
+					})
+				distributor.EXPECT().
+					DistributeTaskSendPaymentRequestNotification(gomock.Any(), gomock.Eq(&worker.PayloadSendPaymentRequestNotification{
+						PaymentRequestID: paymentRequest.ID,
+						Status:           db.PaymentRequestStatusPending,
+					}), gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "RequestToSelf",
+			body:     body,
+			username: payer.Username,
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(0)
+				store.EXPECT().CreatePaymentRequestTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "OtherUsersAccount",
+			body: gin.H{
+				"to_account_id": account.ID,
+				"payer":         requester.Username,
+				"amount":        100,
+				"currency":      util.USD,
+			},
+			username: payer.Username,
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().CreatePaymentRequestTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "PayerNotFound",
+			body:     body,
+			username: requester.Username,
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(payer.Username)).Times(1).Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().CreatePaymentRequestTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			distributor := mockwk.NewMockTaskDistributor(ctrl)
+			tc.buildStubs(store, distributor)
+
+			server := newTestServer(t, store, nil, distributor)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/payment_requests", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAnswerPaymentRequestAPI(t *testing.T) {
+	requester, _ := randomUserForTest(t)
+	payer, _ := randomUserForTest(t)
+
+	toAccount := randomAccount(requester.Username)
+	fromAccount := randomAccount(payer.Username)
+	toAccount.Currency = util.USD
+	fromAccount.Currency = util.USD
+
+	paymentRequest := db.PaymentRequest{
+		ID:          util.RandomInt(1, 1000),
+		Requester:   requester.Username,
+		Payer:       payer.Username,
+		ToAccountID: toAccount.ID,
+		Amount:      100,
+		Currency:    util.USD,
+		Status:      db.PaymentRequestStatusPending,
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+
+	testCases := []struct {
+		name          string
+		action        string
+		body          gin.H
+		username      string
+		buildStubs    func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "Accept",
+			action:   "accept",
+			body:     gin.H{"from_account_id": fromAccount.ID},
+			username: payer.Username,
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(paymentRequest.ID)).Times(1).Return(paymentRequest, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().
+					AcceptPaymentRequestTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.AcceptPaymentRequestTxParams) (db.AcceptPaymentRequestTxResult, error) {
+						require.Equal(t, paymentRequest.ID, arg.PaymentRequestID)
+						require.Equal(t, fromAccount.ID, arg.FromAccountID)
+						require.NotNil(t, arg.Limits)
+
+						accepted := paymentRequest
+						accepted.Status = db.PaymentRequestStatusAccepted
+						err := arg.AfterAccept(accepted)
+						return db.AcceptPaymentRequestTxResult{PaymentRequest: accepted}, err
+					})
+				distributor.EXPECT().
+					DistributeTaskSendPaymentRequestNotification(gomock.Any(), gomock.Eq(&worker.PayloadSendPaymentRequestNotification{
+						PaymentRequestID: paymentRequest.ID,
+						Status:           db.PaymentRequestStatusAccepted,
+					}), gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "AcceptByRequester",
+			action:   "accept",
+			body:     gin.H{"from_account_id": fromAccount.ID},
+			username: requester.Username,
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(paymentRequest.ID)).Times(1).Return(paymentRequest, nil)
+				store.EXPECT().AcceptPaymentRequestTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "AcceptNotPending",
+			action:   "accept",
+			body:     gin.H{"from_account_id": fromAccount.ID},
+			username: payer.Username,
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(paymentRequest.ID)).Times(1).Return(paymentRequest, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().
+					AcceptPaymentRequestTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AcceptPaymentRequestTxResult{}, db.ErrPaymentRequestNotPending)
+				distributor.EXPECT().DistributeTaskSendPaymentRequestNotification(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "AcceptInsufficientFunds",
+			action:   "accept",
+			body:     gin.H{"from_account_id": fromAccount.ID},
+			username: payer.Username,
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(paymentRequest.ID)).Times(1).Return(paymentRequest, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().
+					AcceptPaymentRequestTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AcceptPaymentRequestTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name:     "Decline",
+			action:   "decline",
+			username: payer.Username,
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(paymentRequest.ID)).Times(1).Return(paymentRequest, nil)
+				store.EXPECT().
+					DeclinePaymentRequestTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.DeclinePaymentRequestTxParams) (db.PaymentRequest, error) {
+						declined := paymentRequest
+						declined.Status = db.PaymentRequestStatusDeclined
+						err := arg.AfterDecline(declined)
+						return declined, err
+					})
+				distributor.EXPECT().
+					DistributeTaskSendPaymentRequestNotification(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got db.PaymentRequest
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, db.PaymentRequestStatusDeclined, got.Status)
+			},
+		},
+		{
+			name:     "NotFound",
+			action:   "decline",
+			username: payer.Username,
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(paymentRequest.ID)).Times(1).Return(db.PaymentRequest{}, sql.ErrNoRows)
+				store.EXPECT().DeclinePaymentRequestTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			distributor := mockwk.NewMockTaskDistributor(ctrl)
+			tc.buildStubs(store, distributor)
+
+			server := newTestServer(t, store, nil, distributor)
+			recorder := httptest.NewRecorder()
+
+			var body io.Reader = http.NoBody
+			if tc.body != nil {
+				data, err := json.Marshal(tc.body)
+				require.NoError(t, err)
+				body = bytes.NewReader(data)
+			}
+
+			url := fmt.Sprintf("/payment_requests/%d/%s", paymentRequest.ID, tc.action)
+			request, err := http.NewRequest(http.MethodPost, url, body)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListPaymentRequestsAPI(t *testing.T) {
+	user, _ := randomUserForTest(t)
+
+	testCases := []struct {
+		name       string
+		query      string
+		buildStubs func(store *mockdb.MockStore)
+		wantCode   int
+	}{
+		{
+			name:  "Payer",
+			query: "page_id=1&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListPaymentRequestsByPayerParams{Payer: user.Username, Limit: 5}
+				store.EXPECT().ListPaymentRequestsByPayer(gomock.Any(), gomock.Eq(arg)).Times(1).Return(nil, nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:  "Requester",
+			query: "role=requester&page_id=2&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListPaymentRequestsByRequesterParams{Requester: user.Username, Limit: 5, Offset: 5}
+				store.EXPECT().ListPaymentRequestsByRequester(gomock.Any(), gomock.Eq(arg)).Times(1).Return(nil, nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:  "InvalidRole",
+			query: "role=admin&page_id=1&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListPaymentRequestsByPayer(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ListPaymentRequestsByRequester(gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/payment_requests?"+tc.query, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, tc.wantCode, recorder.Code)
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	db "github.com/AutomaticOrca/simplebank/db/sqlc"
+	"github.com/AutomaticOrca/simplebank/token"
+	"github.com/AutomaticOrca/simplebank/worker"
+	"github.com/gin-gonic/gin"
+	"github.com/hibiken/asynq"
+)
+
+// scheduleTransfer stores a future dated transfer and hands its execution to the worker
+func (server *Server) scheduleTransfer(ctx *gin.Context, arg db.CreateScheduledTransferParams, idempotencyKey *db.CreateIdempotencyKeyParams) {
+	txResult, err := server.store.CreateScheduledTransferTx(ctx, db.CreateScheduledTransferTxParams{
+		CreateScheduledTransferParams: arg,
+		IdempotencyKey:                idempotencyKey,
+		AfterCreate: func(scheduledTransfer db.ScheduledTransfer) error {
+			taskPayload := &worker.PayloadExecuteScheduledTransfer{
+				ScheduledTransferID: scheduledTransfer.ID,
+			}
+			opts := []asynq.Option{
+				asynq.ProcessAt(scheduledTransfer.ExecuteAt),
+				asynq.TaskID(fmt.Sprintf("scheduled_transfer:%d", scheduledTransfer.ID)),
+			}
+			return server.taskDistributor.DistributeTaskExecuteScheduledTransfer(ctx, taskPayload, opts...)
+		},
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrIdempotencyKeyExists) {
+			server.replayConcurrentRequest(ctx, idempotencyKey, err)
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, txResult.ScheduledTransfer)
+}
+
+type listScheduledTransfersRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+func (server *Server) listScheduledTransfers(ctx *gin.Context) {
+	var req listScheduledTransfersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	arg := db.ListScheduledTransfersParams{
+		Owner:  authPayload.Username,
+		Limit:  req.PageSize,
+		Offset: (req.PageID - 1) * req.PageSize,
+	}
+
+	scheduledTransfers, err := server.store.ListScheduledTransfers(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if scheduledTransfers == nil {
+		scheduledTransfers = []db.ScheduledTransfer{}
+	}
+	ctx.JSON(http.StatusOK, scheduledTransfers)
+}
+
+type cancelScheduledTransferRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) cancelScheduledTransfer(ctx *gin.Context) {
+	var req cancelScheduledTransferRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	scheduledTransfer, err := server.store.GetScheduledTransfer(ctx, req.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if scheduledTransfer.Owner != authPayload.Username {
+		err := errors.New("scheduled transfer doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	scheduledTransfer, err = server.store.UpdateScheduledTransfer(ctx, db.UpdateScheduledTransferParams{
+		ID:     scheduledTransfer.ID,
+		Status: db.ScheduledTransferStatusCancelled,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			// the worker got to it first
+			ctx.JSON(http.StatusConflict, errorResponse(db.ErrScheduledTransferNotPending))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, scheduledTransfer)
+}
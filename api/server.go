@@ -13,6 +13,7 @@ import (
 	db "github.com/AutomaticOrca/simplebank/db/sqlc"
 	"github.com/AutomaticOrca/simplebank/token"
 	"github.com/AutomaticOrca/simplebank/util"
+	"github.com/AutomaticOrca/simplebank/worker"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
@@ -20,24 +21,26 @@ import (
 )
 
 type Server struct {
-	store      db.Store
-	router     *gin.Engine
-	tokenMaker token.Maker
-	config     util.Config
-	mailer     mail.EmailSender
+	store           db.Store
+	router          *gin.Engine
+	tokenMaker      token.Maker
+	config          util.Config
+	mailer          mail.EmailSender
+	taskDistributor worker.TaskDistributor
 }
 
-func NewServer(config util.Config, store db.Store, mailer mail.EmailSender) (*Server, error) {
+func NewServer(config util.Config, store db.Store, mailer mail.EmailSender, taskDistributor worker.TaskDistributor) (*Server, error) {
 	tokenMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create token maker: %w", err)
 	}
 
 	server := &Server{
-		config:     config,
-		store:      store,
-		tokenMaker: tokenMaker,
-		mailer:     mailer,
+		config:          config,
+		store:           store,
+		tokenMaker:      tokenMaker,
+		mailer:          mailer,
+		taskDistributor: taskDistributor,
 	}
 
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
@@ -114,7 +117,7 @@ func (server *Server) setupRouter() {
 	config := cors.Config{
 		AllowOrigins:     []string{"http://localhost:3000", "https://simplebank-frontend.vercel.app"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", idempotencyKeyHeader},
 		ExposeHeaders:    []string{"Content-Length"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
@@ -130,9 +133,40 @@ func (server *Server) setupRouter() {
 	authRoutes.POST("/accounts", server.createAccount)
 	authRoutes.GET("/accounts/:id", server.getAccount)
 	authRoutes.GET("/accounts", server.listAccounts)
+	authRoutes.GET("/accounts/:id/balance", server.getAccountBalance)
+	authRoutes.GET("/accounts/:id/entries", server.getAccountStatement)
+	authRoutes.GET("/accounts/:id/statement", server.exportAccountStatement)
+	authRoutes.POST("/accounts/:id/deposits", requireRole(util.BankerRole), server.createDeposit)
+	authRoutes.POST("/accounts/:id/withdrawals", requireRole(util.BankerRole), server.createWithdrawal)
+	// the simulator is the only payment rail so far
+	if server.config.RailSimulatorEnabled {
+		authRoutes.POST("/accounts/:id/top_ups", server.createTopUp)
+		authRoutes.POST("/accounts/:id/payouts", server.createPayout)
+	}
+	authRoutes.GET("/cash_movements/:id", server.getCashMovement)
 
 	authRoutes.POST("/transfers", server.createTransfer)
+	authRoutes.POST("/transfers/batch", server.createBatchTransfer)
+	authRoutes.POST("/transfers/quote", server.quoteTransfer)
 	authRoutes.GET("/transfers", server.listTransfers)
+	authRoutes.POST("/transfers/:id/reverse", server.reverseTransfer)
+
+	authRoutes.POST("/holds", server.authorizeHold)
+	authRoutes.POST("/holds/:id/capture", server.captureHold)
+	authRoutes.POST("/holds/:id/void", server.voidHold)
+
+	authRoutes.POST("/payment_requests", server.createPaymentRequest)
+	authRoutes.GET("/payment_requests", server.listPaymentRequests)
+	authRoutes.POST("/payment_requests/:id/accept", server.acceptPaymentRequest)
+	authRoutes.POST("/payment_requests/:id/decline", server.declinePaymentRequest)
+
+	authRoutes.GET("/scheduled_transfers", server.listScheduledTransfers)
+	authRoutes.POST("/scheduled_transfers/:id/cancel", server.cancelScheduledTransfer)
+
+	authRoutes.POST("/standing_orders", server.createStandingOrder)
+	authRoutes.GET("/standing_orders", server.listStandingOrders)
+	authRoutes.POST("/standing_orders/:id/cancel", server.cancelStandingOrder)
+	authRoutes.GET("/standing_orders/:id/runs", server.listStandingOrderRuns)
 
 	server.router = router
 }
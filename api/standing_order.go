@@ -0,0 +1,193 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	db "github.com/AutomaticOrca/simplebank/db/sqlc"
+	"github.com/AutomaticOrca/simplebank/token"
+	"github.com/AutomaticOrca/simplebank/util"
+	"github.com/gin-gonic/gin"
+)
+
+type createStandingOrderRequest struct {
+	FromAccountID int64  `json:"from_account_id" binding:"required,min=1"`
+	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1"`
+	Amount        int64  `json:"amount" binding:"required,gt=0"`
+	Currency      string `json:"currency" binding:"required,currency"`
+	ToCurrency    string `json:"to_currency" binding:"omitempty,currency"`
+	// Schedule is a standard 5 field cron spec in UTC, e.g. "0 9 1 * *" for 09:00 on the first of every month
+	Schedule string `json:"schedule" binding:"required"`
+}
+
+func (server *Server) createStandingOrder(ctx *gin.Context) {
+	var req createStandingOrderRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	nextRunAt, err := util.NextCronTime(req.Schedule, time.Now())
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	fromAccount, valid := server.validAccount(ctx, req.FromAccountID, req.Currency)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if fromAccount.Owner != authPayload.Username {
+		err := errors.New("from account doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	toCurrency := req.Currency
+	if req.ToCurrency != "" {
+		toCurrency = req.ToCurrency
+	}
+	_, valid = server.validAccount(ctx, req.ToAccountID, toCurrency)
+	if !valid {
+		return
+	}
+
+	standingOrder, err := server.store.CreateStandingOrder(ctx, db.CreateStandingOrderParams{
+		Owner:         authPayload.Username,
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		ToCurrency:    toCurrency,
+		Schedule:      req.Schedule,
+		NextRunAt:     nextRunAt,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, standingOrder)
+}
+
+type listStandingOrdersRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+func (server *Server) listStandingOrders(ctx *gin.Context) {
+	var req listStandingOrdersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	standingOrders, err := server.store.ListStandingOrders(ctx, db.ListStandingOrdersParams{
+		Owner:  authPayload.Username,
+		Limit:  req.PageSize,
+		Offset: (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if standingOrders == nil {
+		standingOrders = []db.StandingOrder{}
+	}
+	ctx.JSON(http.StatusOK, standingOrders)
+}
+
+type standingOrderURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) cancelStandingOrder(ctx *gin.Context) {
+	var uri standingOrderURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	standingOrder, valid := server.ownedStandingOrder(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	standingOrder, err := server.store.CancelStandingOrder(ctx, standingOrder.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusConflict, errorResponse(db.ErrStandingOrderNotActive))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, standingOrder)
+}
+
+type listStandingOrderRunsRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+func (server *Server) listStandingOrderRuns(ctx *gin.Context) {
+	var uri standingOrderURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req listStandingOrderRunsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	standingOrder, valid := server.ownedStandingOrder(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	runs, err := server.store.ListStandingOrderRuns(ctx, db.ListStandingOrderRunsParams{
+		StandingOrderID: standingOrder.ID,
+		Limit:           req.PageSize,
+		Offset:          (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if runs == nil {
+		runs = []db.StandingOrderRun{}
+	}
+	ctx.JSON(http.StatusOK, runs)
+}
+
+// ownedStandingOrder loads a standing order of the authenticated user, writing the error response if there is none
+func (server *Server) ownedStandingOrder(ctx *gin.Context, id int64) (db.StandingOrder, bool) {
+	standingOrder, err := server.store.GetStandingOrder(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return standingOrder, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return standingOrder, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if standingOrder.Owner != authPayload.Username {
+		err := errors.New("standing order doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return standingOrder, false
+	}
+
+	return standingOrder, true
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
@@ -9,19 +10,78 @@ import (
 
 	db "github.com/AutomaticOrca/simplebank/db/sqlc"
 	"github.com/AutomaticOrca/simplebank/token"
+	"github.com/AutomaticOrca/simplebank/val"
 	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
 )
 
 type transferRequest struct {
-	FromAccountID int64  `json:"from_account_id" binding:"required,min=1"`
-	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1"`
-	Amount        int64  `json:"amount" binding:"required,gt=0"`
-	Currency      string `json:"currency" binding:"required,currency"`
+	FromAccountID int64 `json:"from_account_id" binding:"required,min=1"`
+	ToAccountID   int64 `json:"to_account_id" binding:"omitempty,min=1"`
+	// ToUsername or ToEmail can be given instead of ToAccountID, to pay the user's account in the to currency
+	ToUsername string `json:"to_username"`
+	ToEmail    string `json:"to_email" binding:"omitempty,email"`
+	Amount     int64  `json:"amount" binding:"required,gt=0"`
+	Currency   string `json:"currency" binding:"required,currency"`
+	ToCurrency string `json:"to_currency" binding:"omitempty,currency"`
+	transferDetails
+	// ExecuteAt schedules the transfer for later instead of making it right away
+	ExecuteAt *time.Time `json:"execute_at"`
 }
 
+// transferDetails are the optional fields a client can attach to a transfer
+type transferDetails struct {
+	Description     string          `json:"description"`
+	ClientReference string          `json:"client_reference"`
+	Metadata        json.RawMessage `json:"metadata"`
+}
+
+func (details transferDetails) validate() error {
+	if err := val.ValidateTransferDescription(details.Description); err != nil {
+		return fmt.Errorf("invalid description: %w", err)
+	}
+	if details.ClientReference != "" {
+		if err := val.ValidateClientReference(details.ClientReference); err != nil {
+			return fmt.Errorf("invalid client_reference: %w", err)
+		}
+	}
+	if details.Metadata != nil {
+		if err := val.ValidateMetadata(details.Metadata); err != nil {
+			return fmt.Errorf("invalid metadata: %w", err)
+		}
+	}
+	return nil
+}
+
+// listTransfersRequest filters the caller's transfers. Direction, account, currency, counterparty
+// and amount are seen from the caller's side: for incoming transfers the amount is the amount received
 type listTransfersRequest struct {
-	PageID   int32 `form:"page_id" binding:"min=1"`
-	PageSize int32 `form:"page_size" binding:"min=5,max=10"`
+	pageRequest
+	ClientReference       string    `form:"client_reference"`
+	Status                string    `form:"status" binding:"omitempty,oneof=pending completed failed reversed"`
+	From                  time.Time `form:"from"`
+	To                    time.Time `form:"to"`
+	MinAmount             int64     `form:"min_amount" binding:"omitempty,min=1"`
+	MaxAmount             int64     `form:"max_amount" binding:"omitempty,min=1"`
+	CounterpartyAccountID int64     `form:"counterparty_account_id" binding:"omitempty,min=1"`
+	Direction             string    `form:"direction" binding:"omitempty,oneof=incoming outgoing"`
+	Currency              string    `form:"currency" binding:"omitempty,currency"`
+	AccountID             int64     `form:"account_id" binding:"omitempty,min=1"`
+}
+
+func (req listTransfersRequest) validate() error {
+	if err := validatePeriod(req.From, req.To); err != nil {
+		return err
+	}
+	if req.MinAmount != 0 && req.MaxAmount != 0 && req.MaxAmount < req.MinAmount {
+		return errors.New("max_amount must not be less than min_amount")
+	}
+	return nil
+}
+
+type listTransfersResponse struct {
+	Transfers  []transferResponse `json:"transfers"`
+	NextCursor string             `json:"next_cursor,omitempty"`
 }
 
 type transferResponse struct {
@@ -30,7 +90,13 @@ type transferResponse struct {
 	ToAccountID   int64     `json:"to_account_id"`
 	Amount        int64     `json:"amount"`
 	Currency      string    `json:"currency"`
+	ToAmount      int64     `json:"to_amount"`
+	ToCurrency    string    `json:"to_currency"`
+	Status        string    `json:"status"`
+	StatusReason  string    `json:"status_reason"`
 	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	transferDetails
 }
 
 func (server *Server) createTransfer(ctx *gin.Context) {
@@ -40,37 +106,167 @@ func (server *Server) createTransfer(ctx *gin.Context) {
 		return
 	}
 
-	fromAccount, valid := server.validAccount(ctx, req.FromAccountID, req.Currency)
-	if !valid {
+	if err := req.transferDetails.validate(); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if err := req.validateRecipient(); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
 
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-	if fromAccount.Owner != authPayload.Username {
-		err := errors.New("from account doesn't belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+
+	idempotencyKey, ok := server.checkIdempotencyKey(ctx, authPayload.Username, req)
+	if !ok {
 		return
 	}
-	_, valid = server.validAccount(ctx, req.ToAccountID, req.Currency)
+
+	if req.ExecuteAt != nil && !req.ExecuteAt.After(time.Now()) {
+		err := errors.New("execute_at must be in the future")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	toCurrency, recipient, valid := server.resolveTransferAccounts(ctx, &req, authPayload.Username)
 	if !valid {
 		return
 	}
 
+	if req.ExecuteAt != nil {
+		server.scheduleTransfer(ctx, db.CreateScheduledTransferParams{
+			Owner:           authPayload.Username,
+			FromAccountID:   req.FromAccountID,
+			ToAccountID:     req.ToAccountID,
+			Amount:          req.Amount,
+			ToCurrency:      toCurrency,
+			ExecuteAt:       *req.ExecuteAt,
+			Description:     req.Description,
+			ClientReference: req.ClientReference,
+			Metadata:        req.Metadata,
+		}, idempotencyKey)
+		return
+	}
+
 	arg := db.TransferTxParams{
-		FromAccountID: req.FromAccountID,
-		ToAccountID:   req.ToAccountID,
-		Amount:        req.Amount,
+		FromAccountID:   req.FromAccountID,
+		ToAccountID:     req.ToAccountID,
+		Amount:          req.Amount,
+		ToCurrency:      toCurrency,
+		Description:     req.Description,
+		ClientReference: req.ClientReference,
+		Metadata:        req.Metadata,
+		Limits:          server.transferLimits(),
+		IdempotencyKey:  idempotencyKey,
+	}
+	if recipient != nil {
+		arg.IdempotentResponse = func(result db.TransferTxResult) any {
+			return newRecipientTransferResponse(result, *recipient)
+		}
 	}
 
 	result, err := server.store.TransferTx(ctx, arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		if errors.Is(err, db.ErrIdempotencyKeyExists) {
+			server.replayConcurrentRequest(ctx, idempotencyKey, err)
+			return
+		}
+		ctx.JSON(transferErrorStatus(err), transferErrorResponse(err))
 		return
 	}
 
+	if recipient != nil {
+		ctx.JSON(http.StatusOK, newRecipientTransferResponse(result, *recipient))
+		return
+	}
 	ctx.JSON(http.StatusOK, result)
 }
 
+// resolveTransferAccounts checks that the from account belongs to the user and that both accounts hold the
+// currencies of the request. A recipient named by username or email is resolved to their account, which is
+// set as the request's ToAccountID and returned with the user. It returns the currency the to account is credited in
+func (server *Server) resolveTransferAccounts(ctx *gin.Context, req *transferRequest, username string) (toCurrency string, recipient *db.User, valid bool) {
+	fromAccount, valid := server.validAccount(ctx, req.FromAccountID, req.Currency)
+	if !valid {
+		return "", nil, false
+	}
+
+	if fromAccount.Owner != username {
+		err := errors.New("from account doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return "", nil, false
+	}
+	// the to account may hold another currency, in which case the amount is converted at the current fx rate
+	toCurrency = req.Currency
+	if req.ToCurrency != "" {
+		toCurrency = req.ToCurrency
+	}
+
+	if req.ToAccountID == 0 {
+		var toAccount db.Account
+		toAccount, recipient, valid = server.resolveRecipient(ctx, req.ToUsername, req.ToEmail, toCurrency)
+		if !valid {
+			return "", nil, false
+		}
+		if toAccount.ID == fromAccount.ID {
+			err := errors.New("cannot pay an account from itself")
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return "", nil, false
+		}
+		req.ToAccountID = toAccount.ID
+	} else {
+		_, valid = server.validAccount(ctx, req.ToAccountID, toCurrency)
+		if !valid {
+			return "", nil, false
+		}
+	}
+
+	return toCurrency, recipient, true
+}
+
+// transferLimits returns the default velocity limits. The transaction replaces them with the user's own limits if there are any
+func (server *Server) transferLimits() *db.TransferLimits {
+	return &db.TransferLimits{
+		Daily:   server.config.DailyTransferLimit,
+		Monthly: server.config.MonthlyTransferLimit,
+	}
+}
+
+// transferErrorResponse is errorResponse, plus the limit that was hit when a velocity limit stopped the transfer
+func transferErrorResponse(err error) gin.H {
+	response := errorResponse(err)
+	var limitErr *db.VelocityLimitError
+	if errors.As(err, &limitErr) {
+		response["limit"] = limitErr
+	}
+	return response
+}
+
+// transferErrorStatus maps an error returned by a money movement transaction to a HTTP status code
+func transferErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, db.ErrCurrencyMismatch):
+		return http.StatusBadRequest
+	case errors.Is(err, sql.ErrNoRows):
+		return http.StatusNotFound
+	case errors.Is(err, db.ErrInvalidTransferTransition):
+		return http.StatusConflict
+	case errors.Is(err, db.ErrInsufficientFunds),
+		errors.Is(err, db.ErrFxRateNotFound),
+		errors.Is(err, db.ErrInvalidAmount),
+		errors.Is(err, db.ErrTransferNotReversible),
+		errors.Is(err, db.ErrReversalExceedsAmount),
+		errors.Is(err, db.ErrVelocityLimitExceeded):
+		return http.StatusUnprocessableEntity
+	}
+
+	// the balance_within_overdraft_limit constraint is a backstop for the check done in the transaction
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "check_violation" {
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusInternalServerError
+}
+
 func (server *Server) validAccount(ctx *gin.Context, accountID int64, currency string) (db.Account, bool) {
 	account, err := server.store.GetAccount(ctx, accountID)
 	if err != nil {
@@ -99,18 +295,53 @@ func (server *Server) listTransfers(ctx *gin.Context) {
 		return
 	}
 
-	if req.PageID == 0 {
-		req.PageID = 1
+	if err := req.validate(); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
 	}
-	if req.PageSize == 0 {
-		req.PageSize = 5
+	cursor, err := req.parse()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
 	}
 
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 	arg := db.ListTransfersByUsernameParams{
-		Owner:  authPayload.Username,
-		Limit:  req.PageSize,
-		Offset: (req.PageID - 1) * req.PageSize,
+		Owner:           authPayload.Username,
+		CursorCreatedAt: cursor.CreatedAt,
+		CursorID:        cursor.ID,
+		LimitCount:      req.limit(),
+		OffsetCount:     req.offset(),
+	}
+	if req.ClientReference != "" {
+		arg.ClientReference = sql.NullString{String: req.ClientReference, Valid: true}
+	}
+	if req.Status != "" {
+		arg.Status = sql.NullString{String: req.Status, Valid: true}
+	}
+	if !req.From.IsZero() {
+		arg.CreatedFrom = sql.NullTime{Time: req.From, Valid: true}
+	}
+	if !req.To.IsZero() {
+		arg.CreatedTo = sql.NullTime{Time: req.To, Valid: true}
+	}
+	if req.MinAmount != 0 {
+		arg.MinAmount = sql.NullInt64{Int64: req.MinAmount, Valid: true}
+	}
+	if req.MaxAmount != 0 {
+		arg.MaxAmount = sql.NullInt64{Int64: req.MaxAmount, Valid: true}
+	}
+	if req.CounterpartyAccountID != 0 {
+		arg.CounterpartyAccountID = sql.NullInt64{Int64: req.CounterpartyAccountID, Valid: true}
+	}
+	if req.Direction != "" {
+		arg.Direction = sql.NullString{String: req.Direction, Valid: true}
+	}
+	if req.Currency != "" {
+		arg.Currency = sql.NullString{String: req.Currency, Valid: true}
+	}
+	if req.AccountID != 0 {
+		arg.AccountID = sql.NullInt64{Int64: req.AccountID, Valid: true}
 	}
 
 	transfers, err := server.store.ListTransfersByUsername(ctx, arg)
@@ -127,9 +358,27 @@ func (server *Server) listTransfers(ctx *gin.Context) {
 			ToAccountID:   transfer.ToAccountID,
 			Amount:        transfer.Amount,
 			Currency:      transfer.Currency,
+			ToAmount:      transfer.ToAmount,
+			ToCurrency:    transfer.ToCurrency,
+			Status:        transfer.Status,
+			StatusReason:  transfer.StatusReason,
 			CreatedAt:     transfer.CreatedAt,
+			UpdatedAt:     transfer.UpdatedAt,
+			transferDetails: transferDetails{
+				Description:     transfer.Description,
+				ClientReference: transfer.ClientReference,
+				Metadata:        transfer.Metadata,
+			},
 		}
 	}
 
-	ctx.JSON(http.StatusOK, response)
+	if req.byOffset() {
+		ctx.JSON(http.StatusOK, response)
+		return
+	}
+
+	response, nextCursor := nextPage(req.pageRequest, response, func(transfer transferResponse) (time.Time, int64) {
+		return transfer.CreatedAt, transfer.ID
+	})
+	ctx.JSON(http.StatusOK, listTransfersResponse{Transfers: response, NextCursor: nextCursor})
 }
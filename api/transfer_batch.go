@@ -0,0 +1,122 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	db "github.com/AutomaticOrca/simplebank/db/sqlc"
+	"github.com/AutomaticOrca/simplebank/token"
+	"github.com/gin-gonic/gin"
+)
+
+type batchTransferLegRequest struct {
+	FromAccountID int64  `json:"from_account_id" binding:"required,min=1"`
+	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1,nefield=FromAccountID"`
+	Amount        int64  `json:"amount" binding:"required,gt=0"`
+	Currency      string `json:"currency" binding:"required,currency"`
+	ToCurrency    string `json:"to_currency" binding:"omitempty,currency"`
+	transferDetails
+}
+
+type batchTransferRequest struct {
+	// a batch is capped so that its account locks are held briefly
+	Legs []batchTransferLegRequest `json:"legs" binding:"required,min=1,max=100,dive"`
+}
+
+// createBatchTransfer makes all legs of the request in one transaction, or none of them.
+// When a leg fails, the response names it in the "leg" field (zero based)
+func (server *Server) createBatchTransfer(ctx *gin.Context) {
+	var req batchTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	idempotencyKey, ok := server.checkIdempotencyKey(ctx, authPayload.Username, req)
+	if !ok {
+		return
+	}
+
+	// the to accounts and their currencies are checked by the transaction, under lock
+	fromAccounts := make(map[int64]db.Account)
+	arg := db.BatchTransferTxParams{
+		Legs:           make([]db.TransferTxParams, len(req.Legs)),
+		Limits:         server.transferLimits(),
+		IdempotencyKey: idempotencyKey,
+	}
+	for i, leg := range req.Legs {
+		if err := leg.transferDetails.validate(); err != nil {
+			ctx.JSON(http.StatusBadRequest, batchLegErrorResponse(&db.BatchLegError{Leg: i, Err: err}))
+			return
+		}
+
+		fromAccount, ok := fromAccounts[leg.FromAccountID]
+		if !ok {
+			var err error
+			fromAccount, err = server.store.GetAccount(ctx, leg.FromAccountID)
+			if err != nil {
+				legErr := &db.BatchLegError{Leg: i, Err: err}
+				if errors.Is(err, sql.ErrNoRows) {
+					ctx.JSON(http.StatusNotFound, batchLegErrorResponse(legErr))
+					return
+				}
+				ctx.JSON(http.StatusInternalServerError, batchLegErrorResponse(legErr))
+				return
+			}
+			fromAccounts[fromAccount.ID] = fromAccount
+		}
+
+		if fromAccount.Owner != authPayload.Username {
+			err := errors.New("from account doesn't belong to the authenticated user")
+			ctx.JSON(http.StatusUnauthorized, batchLegErrorResponse(&db.BatchLegError{Leg: i, Err: err}))
+			return
+		}
+		if fromAccount.Currency != leg.Currency {
+			err := fmt.Errorf("account [%d] currency mismatch: %s vs %s", fromAccount.ID, fromAccount.Currency, leg.Currency)
+			ctx.JSON(http.StatusBadRequest, batchLegErrorResponse(&db.BatchLegError{Leg: i, Err: err}))
+			return
+		}
+
+		toCurrency := leg.Currency
+		if leg.ToCurrency != "" {
+			toCurrency = leg.ToCurrency
+		}
+		arg.Legs[i] = db.TransferTxParams{
+			FromAccountID:   leg.FromAccountID,
+			ToAccountID:     leg.ToAccountID,
+			Amount:          leg.Amount,
+			ToCurrency:      toCurrency,
+			Description:     leg.Description,
+			ClientReference: leg.ClientReference,
+			Metadata:        leg.Metadata,
+		}
+	}
+
+	result, err := server.store.BatchTransferTx(ctx, arg)
+	if err != nil {
+		if errors.Is(err, db.ErrIdempotencyKeyExists) {
+			server.replayConcurrentRequest(ctx, idempotencyKey, err)
+			return
+		}
+
+		var legErr *db.BatchLegError
+		if errors.As(err, &legErr) {
+			ctx.JSON(transferErrorStatus(legErr), batchLegErrorResponse(legErr))
+			return
+		}
+		ctx.JSON(transferErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func batchLegErrorResponse(err *db.BatchLegError) gin.H {
+	response := transferErrorResponse(err)
+	response["leg"] = err.Leg
+	return response
+}
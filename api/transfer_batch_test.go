@@ -0,0 +1,195 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/AutomaticOrca/simplebank/db/mock"
+	db "github.com/AutomaticOrca/simplebank/db/sqlc"
+	"github.com/AutomaticOrca/simplebank/util"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateBatchTransferAPI(t *testing.T) {
+	user1, _ := randomUserForTest(t)
+	user2, _ := randomUserForTest(t)
+
+	account1 := randomAccount(user1.Username)
+	account2 := randomAccount(user2.Username)
+	account3 := randomAccount(user2.Username)
+	account1.Currency = util.USD
+	account2.Currency = util.USD
+	account3.Currency = util.USD
+	limits := &db.TransferLimits{Daily: 1000, Monthly: 10000}
+
+	body := gin.H{
+		"legs": []gin.H{
+			{"from_account_id": account1.ID, "to_account_id": account2.ID, "amount": 10, "currency": util.USD},
+			{"from_account_id": account1.ID, "to_account_id": account3.ID, "amount": 20, "currency": util.USD},
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			body:     body,
+			username: user1.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				// the from account is shared by both legs, so it is looked up once
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().
+					BatchTransferTx(gomock.Any(), gomock.Eq(db.BatchTransferTxParams{
+						Legs: []db.TransferTxParams{
+							{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 10, ToCurrency: util.USD},
+							{FromAccountID: account1.ID, ToAccountID: account3.ID, Amount: 20, ToCurrency: util.USD},
+						},
+						Limits: limits,
+					})).
+					Times(1).
+					Return(db.BatchTransferTxResult{
+						Legs: []db.TransferTxResult{
+							{Transfer: db.Transfer{ID: 1, FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 10}},
+							{Transfer: db.Transfer{ID: 2, FromAccountID: account1.ID, ToAccountID: account3.ID, Amount: 20}},
+						},
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got db.BatchTransferTxResult
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Len(t, got.Legs, 2)
+				require.Equal(t, account3.ID, got.Legs[1].Transfer.ToAccountID)
+			},
+		},
+		{
+			name:     "LegInsufficientFunds",
+			body:     body,
+			username: user1.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().
+					BatchTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.BatchTransferTxResult{}, &db.BatchLegError{Leg: 1, Err: db.ErrInsufficientFunds})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireFailedLeg(t, recorder, 1)
+			},
+		},
+		{
+			name:     "LegToAccountNotFound",
+			body:     body,
+			username: user1.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().
+					BatchTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.BatchTransferTxResult{}, &db.BatchLegError{Leg: 0, Err: fmt.Errorf("account [%d]: %w", account2.ID, sql.ErrNoRows)})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireFailedLeg(t, recorder, 0)
+			},
+		},
+		{
+			name: "LegFromAccountNotOwned",
+			body: gin.H{
+				"legs": []gin.H{
+					{"from_account_id": account1.ID, "to_account_id": account2.ID, "amount": 10, "currency": util.USD},
+					{"from_account_id": account3.ID, "to_account_id": account2.ID, "amount": 20, "currency": util.USD},
+				},
+			},
+			username: user1.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account3.ID)).Times(1).Return(account3, nil)
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				requireFailedLeg(t, recorder, 1)
+			},
+		},
+		{
+			name: "SameAccountLeg",
+			body: gin.H{
+				"legs": []gin.H{
+					{"from_account_id": account1.ID, "to_account_id": account1.ID, "amount": 10, "currency": util.USD},
+				},
+			},
+			username: user1.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "NoLegs",
+			body:     gin.H{"legs": []gin.H{}},
+			username: user1.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/transfers/batch", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func requireFailedLeg(t *testing.T, recorder *httptest.ResponseRecorder, leg int) {
+	var got struct {
+		Error string `json:"error"`
+		Leg   int    `json:"leg"`
+	}
+	err := json.Unmarshal(recorder.Body.Bytes(), &got)
+	require.NoError(t, err)
+	require.Equal(t, leg, got.Leg)
+	require.Contains(t, got.Error, fmt.Sprintf("leg %d", leg))
+}
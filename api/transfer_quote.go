@@ -0,0 +1,42 @@
+package api
+
+import (
+	"net/http"
+
+	db "github.com/AutomaticOrca/simplebank/db/sqlc"
+	"github.com/AutomaticOrca/simplebank/token"
+	"github.com/gin-gonic/gin"
+)
+
+// quoteTransfer prices a transfer without making it. It takes the same body as createTransfer and returns
+// the fee, the total taken from the from account and what the recipient would get at the current rates
+func (server *Server) quoteTransfer(ctx *gin.Context) {
+	var req transferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if err := req.validateRecipient(); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	toCurrency, _, valid := server.resolveTransferAccounts(ctx, &req, authPayload.Username)
+	if !valid {
+		return
+	}
+
+	quote, err := server.store.QuoteTransferTx(ctx, db.QuoteTransferTxParams{
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		ToCurrency:    toCurrency,
+	})
+	if err != nil {
+		ctx.JSON(transferErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, quote)
+}
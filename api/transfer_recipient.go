@@ -0,0 +1,121 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	db "github.com/AutomaticOrca/simplebank/db/sqlc"
+	"github.com/AutomaticOrca/simplebank/util"
+	"github.com/AutomaticOrca/simplebank/val"
+	"github.com/gin-gonic/gin"
+)
+
+// recipientTransferResponse is returned for a transfer to a username or email.
+// The recipient's account is left out, and only a masked name tells the sender who was paid
+type recipientTransferResponse struct {
+	Transfer      recipientTransfer `json:"transfer"`
+	FromAccount   db.Account        `json:"from_account"`
+	FromEntry     db.Entry          `json:"from_entry"`
+	Fee           int64             `json:"fee"`
+	RecipientName string            `json:"recipient_name"`
+}
+
+// recipientTransfer is a transfer without the recipient's account
+type recipientTransfer struct {
+	ID            int64     `json:"id"`
+	FromAccountID int64     `json:"from_account_id"`
+	Amount        int64     `json:"amount"`
+	ToAmount      int64     `json:"to_amount"`
+	FxRate        string    `json:"fx_rate"`
+	Status        string    `json:"status"`
+	StatusReason  string    `json:"status_reason"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	transferDetails
+}
+
+func newRecipientTransferResponse(result db.TransferTxResult, recipient db.User) recipientTransferResponse {
+	transfer := result.Transfer
+	return recipientTransferResponse{
+		Transfer: recipientTransfer{
+			ID:            transfer.ID,
+			FromAccountID: transfer.FromAccountID,
+			Amount:        transfer.Amount,
+			ToAmount:      transfer.ToAmount,
+			FxRate:        transfer.FxRate,
+			Status:        transfer.Status,
+			StatusReason:  transfer.StatusReason,
+			CreatedAt:     transfer.CreatedAt,
+			UpdatedAt:     transfer.UpdatedAt,
+			transferDetails: transferDetails{
+				Description:     transfer.Description,
+				ClientReference: transfer.ClientReference,
+				Metadata:        transfer.Metadata,
+			},
+		},
+		FromAccount:   result.FromAccount,
+		FromEntry:     result.FromEntry,
+		Fee:           result.Fee,
+		RecipientName: util.MaskName(recipient.FullName),
+	}
+}
+
+// validateRecipient makes sure the request names the recipient in exactly one way
+func (req transferRequest) validateRecipient() error {
+	n := 0
+	for _, set := range []bool{req.ToAccountID != 0, req.ToUsername != "", req.ToEmail != ""} {
+		if set {
+			n++
+		}
+	}
+	if n != 1 {
+		return errors.New("exactly one of to_account_id, to_username or to_email is required")
+	}
+
+	if req.ToUsername != "" {
+		if err := val.ValidateUsername(req.ToUsername); err != nil {
+			return fmt.Errorf("invalid to_username: %w", err)
+		}
+	}
+	return nil
+}
+
+// resolveRecipient finds the user named by username or email, and their account in currency.
+// A user may hold several accounts in a currency, so their oldest checking account is used,
+// or their oldest account of any product if they have no checking account
+func (server *Server) resolveRecipient(ctx *gin.Context, username, email, currency string) (db.Account, *db.User, bool) {
+	var user db.User
+	var err error
+	if username != "" {
+		user, err = server.store.GetUser(ctx, username)
+	} else {
+		user, err = server.store.GetUserByEmail(ctx, email)
+	}
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(errors.New("recipient not found")))
+			return db.Account{}, nil, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return db.Account{}, nil, false
+	}
+
+	account, err := server.store.GetAccountByOwnerCurrency(ctx, db.GetAccountByOwnerCurrencyParams{
+		Owner:    user.Username,
+		Currency: currency,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err := fmt.Errorf("recipient has no %s account", currency)
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return db.Account{}, nil, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return db.Account{}, nil, false
+	}
+
+	return account, &user, true
+}
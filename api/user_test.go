@@ -16,6 +16,7 @@ import (
 	"github.com/AutomaticOrca/simplebank/mail"
 	mockmail "github.com/AutomaticOrca/simplebank/mail/mock"
 	"github.com/AutomaticOrca/simplebank/util"
+	"github.com/AutomaticOrca/simplebank/worker"
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
 	"github.com/lib/pq"
@@ -38,18 +39,24 @@ func randomUserForTest(t *testing.T) (user db.User, password string) {
 
 // newTestServer 是一个辅助函数，用于创建带有 mock 依赖的 api.Server 实例
 // 这样我们可以在不同的测试用例中复用服务器的创建逻辑
-func newTestServer(t *testing.T, store db.Store, mailer mail.EmailSender) *Server {
+func newTestServer(t *testing.T, store db.Store, mailer mail.EmailSender, taskDistributor worker.TaskDistributor) *Server {
 	// 为测试创建一个最小化但足够使用的配置
 	config := util.Config{
-		TokenSymmetricKey:   util.RandomString(32),     // PasetoMaker 创建时需要
-		AccessTokenDuration: time.Minute,               // TokenMaker 创建时需要
-		FrontendBaseURL:     "http://test.example.com", // sendVerificationEmailAsync 中会用到
+		TokenSymmetricKey:      util.RandomString(32),     // PasetoMaker 创建时需要
+		AccessTokenDuration:    time.Minute,               // TokenMaker 创建时需要
+		FrontendBaseURL:        "http://test.example.com", // sendVerificationEmailAsync 中会用到
+		IdempotencyKeyDuration: time.Minute,               // createTransfer 处理幂等键时需要
+		HoldDuration:           time.Hour,                 // authorizeHold 计算过期时间时需要
+		PaymentRequestDuration: time.Hour,                 // createPaymentRequest 计算过期时间时需要
+		DailyTransferLimit:     1000,                      // createTransfer 传给事务的默认限额
+		MonthlyTransferLimit:   10000,
+		RailSimulatorEnabled:   true, // 注册 top_ups 和 payouts 路由时需要
 		// 根据你的 NewServer 函数和被测 handler 的实际需求，添加其他必要的配置字段
 		// 例如，如果 NewServer 或 setupRouter 中用到了其他 config 值，也需要在这里提供
 	}
 
-	// 调用你 api 包中的 NewServer 函数，传入 mock 的 store 和 mailer
-	server, err := NewServer(config, store, mailer)
+	// 调用你 api 包中的 NewServer 函数，传入 mock 的 store、mailer 和 taskDistributor
+	server, err := NewServer(config, store, mailer, taskDistributor)
 	require.NoError(t, err) // 确保服务器实例创建成功
 	return server
 }
@@ -313,7 +320,7 @@ func TestCreateUserAPI(t *testing.T) {
 			}
 
 			// 创建测试服务器实例，注入 mock 依赖
-			server := newTestServer(t, storeMock, mailerMock)
+			server := newTestServer(t, storeMock, mailerMock, nil)
 
 			// 创建一个 HTTP 响应记录器
 			recorder := httptest.NewRecorder()
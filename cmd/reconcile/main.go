@@ -0,0 +1,51 @@
+// Command reconcile checks the ledger once, the same way as the nightly worker task.
+// It prints the run with its report as JSON and exits with status 2 if discrepancies were found
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"os"
+	"os/signal"
+	"syscall"
+
+	db "github.com/AutomaticOrca/simplebank/db/sqlc"
+	"github.com/AutomaticOrca/simplebank/util"
+	_ "github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+)
+
+func main() {
+	config, err := util.LoadConfig()
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot load config")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	conn, err := sql.Open(config.DBDriver, config.DBSource)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot connect to db")
+	}
+	defer conn.Close()
+
+	store := db.NewStore(conn)
+	result, err := store.ReconcileTx(ctx)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot reconcile ledger")
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result.Run); err != nil {
+		log.Fatal().Err(err).Msg("cannot write report")
+	}
+
+	if result.Run.Status != db.ReconciliationStatusBalanced {
+		stop()
+		conn.Close()
+		os.Exit(2)
+	}
+}
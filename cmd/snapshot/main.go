@@ -0,0 +1,74 @@
+// Command snapshot takes the end of day balances of a range of days, to backfill history
+// or to catch up after the worker was down. Without flags it covers the same days as the
+// nightly worker task: from the day after the last snapshot up to yesterday.
+// Days that already have a snapshot are left as they are
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	db "github.com/AutomaticOrca/simplebank/db/sqlc"
+	"github.com/AutomaticOrca/simplebank/util"
+	_ "github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+)
+
+func main() {
+	fromFlag := flag.String("from", "", "first day to snapshot, YYYY-MM-DD (default: day after the last snapshot)")
+	toFlag := flag.String("to", "", "last day to snapshot, YYYY-MM-DD (default: yesterday)")
+	flag.Parse()
+
+	config, err := util.LoadConfig()
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot load config")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	conn, err := sql.Open(config.DBDriver, config.DBSource)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot connect to db")
+	}
+	defer conn.Close()
+
+	store := db.NewStore(conn)
+
+	arg := db.SnapshotBalancesTxParams{
+		To: time.Now().UTC().AddDate(0, 0, -1),
+	}
+	if *fromFlag != "" {
+		arg.From, err = time.Parse(time.DateOnly, *fromFlag)
+	} else {
+		arg.From, err = store.GetBalanceSnapshotResumeDate(ctx)
+	}
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot get first day")
+	}
+	if *toFlag != "" {
+		arg.To, err = time.Parse(time.DateOnly, *toFlag)
+		if err != nil {
+			log.Fatal().Err(err).Msg("cannot parse last day")
+		}
+	}
+	if !arg.To.Before(time.Now().UTC().Truncate(24 * time.Hour)) {
+		log.Fatal().Msg("last day must be before today, its balances are not final yet")
+	}
+
+	result, err := store.SnapshotBalancesTx(ctx, arg)
+	if err != nil {
+		log.Fatal().Err(err).Int("days", result.Days).Msg("cannot snapshot balances")
+	}
+
+	log.Info().Str("from", arg.From.Format(time.DateOnly)).
+		Str("to", arg.To.Format(time.DateOnly)).
+		Int("days", result.Days).
+		Int64("snapshots", result.Snapshots).
+		Msg("balances snapshotted")
+}
@@ -0,0 +1,7 @@
+ALTER TABLE "transfers" DROP COLUMN "fx_spread_bps";
+
+ALTER TABLE "transfers" DROP COLUMN "fx_rate";
+
+ALTER TABLE "transfers" DROP COLUMN "to_amount";
+
+DROP TABLE IF EXISTS "fx_rates";
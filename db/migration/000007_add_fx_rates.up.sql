@@ -0,0 +1,28 @@
+CREATE TABLE "fx_rates" (
+  "base_currency" varchar NOT NULL,
+  "quote_currency" varchar NOT NULL,
+  "rate" numeric(20, 10) NOT NULL,
+  "spread_bps" integer NOT NULL DEFAULT 0,
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("base_currency", "quote_currency")
+);
+
+ALTER TABLE "fx_rates" ADD CONSTRAINT "fx_rate_positive" CHECK ("rate" > 0);
+
+ALTER TABLE "fx_rates" ADD CONSTRAINT "fx_spread_bps_range" CHECK ("spread_bps" >= 0 AND "spread_bps" < 10000);
+
+COMMENT ON COLUMN "fx_rates"."rate" IS 'units of quote currency for one unit of base currency';
+
+COMMENT ON COLUMN "fx_rates"."spread_bps" IS 'margin taken off the converted amount, in basis points';
+
+ALTER TABLE "transfers" ADD COLUMN "to_amount" bigint;
+
+UPDATE "transfers" SET "to_amount" = "amount";
+
+ALTER TABLE "transfers" ALTER COLUMN "to_amount" SET NOT NULL;
+
+ALTER TABLE "transfers" ADD COLUMN "fx_rate" numeric(20, 10) NOT NULL DEFAULT 1;
+
+ALTER TABLE "transfers" ADD COLUMN "fx_spread_bps" integer NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN "transfers"."to_amount" IS 'amount credited to the to account, in its own currency';
@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS "system_accounts";
+
+DELETE FROM "entries" WHERE "account_id" IN (SELECT "id" FROM "accounts" WHERE "owner" = 'simplebank_fx');
+
+DELETE FROM "accounts" WHERE "owner" = 'simplebank_fx';
+
+DELETE FROM "users" WHERE "username" = 'simplebank_fx';
+
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "journal_id";
+
+ALTER TABLE "entries" DROP COLUMN IF EXISTS "journal_id";
+
+DROP TABLE IF EXISTS "journals";
@@ -0,0 +1,41 @@
+CREATE TABLE "journals" (
+  "id" bigserial PRIMARY KEY,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "entries" ADD COLUMN "journal_id" bigint;
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("journal_id") REFERENCES "journals" ("id");
+
+CREATE INDEX ON "entries" ("journal_id");
+
+COMMENT ON COLUMN "entries"."journal_id" IS 'the balanced posting the entry belongs to, null for entries made before journals';
+
+ALTER TABLE "transfers" ADD COLUMN "journal_id" bigint;
+
+ALTER TABLE "transfers" ADD FOREIGN KEY ("journal_id") REFERENCES "journals" ("id");
+
+CREATE TABLE "system_accounts" (
+  "purpose" varchar NOT NULL,
+  "currency" varchar NOT NULL,
+  "account_id" bigint UNIQUE NOT NULL,
+  PRIMARY KEY ("purpose", "currency")
+);
+
+ALTER TABLE "system_accounts" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+COMMENT ON TABLE "system_accounts" IS 'accounts owned by the bank itself, looked up by what they are for';
+
+-- cross currency transfers sell one currency and buy the other through these accounts,
+-- so that every journal balances per currency. They can go as negative as needed.
+INSERT INTO "users" ("username", "hashed_password", "full_name", "email", "is_email_verified")
+VALUES ('simplebank_fx', '', 'Simple Bank FX position', 'fx@simplebank.invalid', true);
+
+INSERT INTO "accounts" ("owner", "balance", "currency", "overdraft_limit")
+SELECT 'simplebank_fx', 0, "currency", 9223372036854775807
+FROM unnest(ARRAY['USD', 'EUR', 'AUD', 'CAD']) AS "currency";
+
+INSERT INTO "system_accounts" ("purpose", "currency", "account_id")
+SELECT 'fx_position', "currency", "id"
+FROM "accounts"
+WHERE "owner" = 'simplebank_fx';
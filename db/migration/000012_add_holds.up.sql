@@ -0,0 +1,41 @@
+ALTER TABLE "accounts" ADD COLUMN "held_amount" bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE "accounts" ADD CONSTRAINT "held_amount_non_negative" CHECK ("held_amount" >= 0);
+
+COMMENT ON COLUMN "accounts"."held_amount" IS 'sum of the authorized holds on the account, the available balance is balance - held_amount';
+
+CREATE TABLE "holds" (
+  "id" bigserial PRIMARY KEY,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "to_currency" varchar NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'authorized',
+  "captured_amount" bigint NOT NULL DEFAULT 0,
+  "transfer_id" bigint,
+  "expires_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "holds" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "holds" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "holds" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "holds" ADD CONSTRAINT "hold_amount_positive" CHECK ("amount" > 0);
+
+ALTER TABLE "holds" ADD CONSTRAINT "hold_captured_amount_within_amount" CHECK ("captured_amount" >= 0 AND "captured_amount" <= "amount");
+
+ALTER TABLE "holds" ADD CONSTRAINT "hold_status" CHECK ("status" IN ('authorized', 'captured', 'voided', 'expired'));
+
+CREATE INDEX ON "holds" ("from_account_id");
+
+CREATE INDEX ON "holds" ("status", "expires_at");
+
+COMMENT ON COLUMN "holds"."status" IS 'authorized, captured, voided or expired';
+
+COMMENT ON COLUMN "holds"."captured_amount" IS 'amount taken by the capture, the rest of the hold is released';
+
+COMMENT ON COLUMN "holds"."transfer_id" IS 'the transfer made by the capture';
@@ -0,0 +1,5 @@
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "metadata";
+
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "client_reference";
+
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "description";
@@ -0,0 +1,13 @@
+ALTER TABLE "transfers" ADD COLUMN "description" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "transfers" ADD COLUMN "client_reference" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "transfers" ADD COLUMN "metadata" jsonb NOT NULL DEFAULT '{}';
+
+CREATE INDEX ON "transfers" ("client_reference") WHERE "client_reference" <> '';
+
+COMMENT ON COLUMN "transfers"."description" IS 'free text memo shown to both sides';
+
+COMMENT ON COLUMN "transfers"."client_reference" IS 'reference chosen by the client, e.g. an invoice number';
+
+COMMENT ON COLUMN "transfers"."metadata" IS 'arbitrary JSON object attached by the client';
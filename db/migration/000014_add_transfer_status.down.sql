@@ -0,0 +1,7 @@
+ALTER TABLE "transfers" DROP CONSTRAINT IF EXISTS "transfer_status";
+
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "updated_at";
+
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "status_reason";
+
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "status";
@@ -0,0 +1,13 @@
+ALTER TABLE "transfers" ADD COLUMN "status" varchar NOT NULL DEFAULT 'completed';
+
+ALTER TABLE "transfers" ADD COLUMN "status_reason" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "transfers" ADD COLUMN "updated_at" timestamptz NOT NULL DEFAULT (now());
+
+UPDATE "transfers" SET "updated_at" = "created_at";
+
+ALTER TABLE "transfers" ADD CONSTRAINT "transfer_status" CHECK ("status" IN ('pending', 'completed', 'failed', 'reversed'));
+
+COMMENT ON COLUMN "transfers"."status" IS 'pending, completed, failed or reversed';
+
+COMMENT ON COLUMN "transfers"."status_reason" IS 'why the transfer reached its status, e.g. the failure reason';
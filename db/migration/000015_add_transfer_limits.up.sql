@@ -0,0 +1,18 @@
+CREATE TABLE "transfer_limits" (
+  "username" varchar NOT NULL,
+  "currency" varchar NOT NULL,
+  "daily_limit" bigint NOT NULL,
+  "monthly_limit" bigint NOT NULL,
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("username", "currency")
+);
+
+ALTER TABLE "transfer_limits" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "transfer_limits" ADD CONSTRAINT "transfer_limits_non_negative" CHECK ("daily_limit" >= 0 AND "monthly_limit" >= 0);
+
+COMMENT ON TABLE "transfer_limits" IS 'per user overrides of the default velocity limits';
+
+COMMENT ON COLUMN "transfer_limits"."daily_limit" IS 'most the user can send per UTC day in this currency, 0 means no limit';
+
+COMMENT ON COLUMN "transfer_limits"."monthly_limit" IS 'most the user can send per UTC month in this currency, 0 means no limit';
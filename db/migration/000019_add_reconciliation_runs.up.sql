@@ -0,0 +1,20 @@
+CREATE TABLE "reconciliation_runs" (
+  "id" bigserial PRIMARY KEY,
+  "status" varchar NOT NULL,
+  "accounts_checked" bigint NOT NULL,
+  "transfers_checked" bigint NOT NULL,
+  "discrepancy_count" bigint NOT NULL,
+  "report" jsonb NOT NULL DEFAULT '{}',
+  "started_at" timestamptz NOT NULL,
+  "finished_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "reconciliation_runs" ADD CONSTRAINT "reconciliation_run_status" CHECK ("status" IN ('balanced', 'discrepancies'));
+
+CREATE INDEX ON "reconciliation_runs" ("started_at");
+
+COMMENT ON TABLE "reconciliation_runs" IS 'results of checking the stored balances against the ledger entries';
+
+COMMENT ON COLUMN "reconciliation_runs"."status" IS 'balanced or discrepancies';
+
+COMMENT ON COLUMN "reconciliation_runs"."report" IS 'the accounts whose balance drifted from their entries and the transfers whose entries do not net to zero';
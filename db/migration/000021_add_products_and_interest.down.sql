@@ -0,0 +1,15 @@
+DROP TABLE IF EXISTS "interest_accruals";
+
+DROP TABLE IF EXISTS "interest_postings";
+
+DELETE FROM "system_accounts" WHERE "purpose" = 'interest_expense';
+
+DELETE FROM "entries" WHERE "account_id" IN (SELECT "id" FROM "accounts" WHERE "owner" = 'simplebank_interest');
+
+DELETE FROM "accounts" WHERE "owner" = 'simplebank_interest';
+
+DELETE FROM "users" WHERE "username" = 'simplebank_interest';
+
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "product_code";
+
+DROP TABLE IF EXISTS "products";
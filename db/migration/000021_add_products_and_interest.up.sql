@@ -0,0 +1,77 @@
+CREATE TABLE "products" (
+  "code" varchar PRIMARY KEY,
+  "name" varchar NOT NULL,
+  "annual_interest_rate" numeric(9, 6) NOT NULL DEFAULT 0,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "products" ADD CONSTRAINT "product_interest_rate_range" CHECK ("annual_interest_rate" >= 0 AND "annual_interest_rate" < 1);
+
+COMMENT ON TABLE "products" IS 'the kinds of account the bank offers';
+
+COMMENT ON COLUMN "products"."annual_interest_rate" IS 'interest paid per year on positive balances, 0.02 is 2%';
+
+INSERT INTO "products" ("code", "name", "annual_interest_rate")
+VALUES ('checking', 'Checking', 0), ('savings', 'Savings', 0.02);
+
+ALTER TABLE "accounts" ADD COLUMN "product_code" varchar NOT NULL DEFAULT 'checking';
+
+ALTER TABLE "accounts" ADD FOREIGN KEY ("product_code") REFERENCES "products" ("code");
+
+CREATE TABLE "interest_postings" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "period" date NOT NULL,
+  "amount" bigint NOT NULL,
+  "journal_id" bigint,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "interest_postings" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "interest_postings" ADD FOREIGN KEY ("journal_id") REFERENCES "journals" ("id");
+
+CREATE UNIQUE INDEX ON "interest_postings" ("account_id", "period");
+
+COMMENT ON COLUMN "interest_postings"."period" IS 'the first day of the month the interest was accrued in';
+
+COMMENT ON COLUMN "interest_postings"."amount" IS 'the accruals of the month rounded to the minor unit';
+
+COMMENT ON COLUMN "interest_postings"."journal_id" IS 'null when the interest rounded to zero and nothing was paid';
+
+CREATE TABLE "interest_accruals" (
+  "account_id" bigint NOT NULL,
+  "accrual_date" date NOT NULL,
+  "balance" bigint NOT NULL,
+  "annual_interest_rate" numeric(9, 6) NOT NULL,
+  "amount" numeric(20, 6) NOT NULL,
+  "posting_id" bigint,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("account_id", "accrual_date")
+);
+
+ALTER TABLE "interest_accruals" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "interest_accruals" ADD FOREIGN KEY ("posting_id") REFERENCES "interest_postings" ("id");
+
+CREATE INDEX ON "interest_accruals" ("accrual_date") WHERE "posting_id" IS NULL;
+
+COMMENT ON TABLE "interest_accruals" IS 'interest earned by an account on one UTC day, paid out monthly';
+
+COMMENT ON COLUMN "interest_accruals"."balance" IS 'the end of day balance the interest was earned on';
+
+COMMENT ON COLUMN "interest_accruals"."amount" IS 'in minor units, kept unrounded until the month is posted';
+
+-- interest is paid out of these accounts, so every posting stays a balanced journal.
+-- Like the fx position accounts they can go as negative as needed.
+INSERT INTO "users" ("username", "hashed_password", "full_name", "email", "is_email_verified")
+VALUES ('simplebank_interest', '', 'Simple Bank interest expense', 'interest@simplebank.invalid', true);
+
+INSERT INTO "accounts" ("owner", "balance", "currency", "overdraft_limit")
+SELECT 'simplebank_interest', 0, "currency", 9223372036854775807
+FROM unnest(ARRAY['USD', 'EUR', 'AUD', 'CAD']) AS "currency";
+
+INSERT INTO "system_accounts" ("purpose", "currency", "account_id")
+SELECT 'interest_expense', "currency", "id"
+FROM "accounts"
+WHERE "owner" = 'simplebank_interest';
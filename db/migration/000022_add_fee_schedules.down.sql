@@ -0,0 +1,9 @@
+DELETE FROM "system_accounts" WHERE "purpose" = 'fee_income';
+
+DELETE FROM "entries" WHERE "account_id" IN (SELECT "id" FROM "accounts" WHERE "owner" = 'simplebank_fees');
+
+DELETE FROM "accounts" WHERE "owner" = 'simplebank_fees';
+
+DELETE FROM "users" WHERE "username" = 'simplebank_fees';
+
+DROP TABLE IF EXISTS "fee_schedules";
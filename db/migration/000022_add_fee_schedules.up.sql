@@ -0,0 +1,41 @@
+CREATE TABLE "fee_schedules" (
+  "transfer_type" varchar NOT NULL,
+  "currency" varchar NOT NULL,
+  "flat_fee" bigint NOT NULL DEFAULT 0,
+  "percentage_bps" integer NOT NULL DEFAULT 0,
+  "min_fee" bigint NOT NULL DEFAULT 0,
+  "max_fee" bigint,
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("transfer_type", "currency")
+);
+
+ALTER TABLE "fee_schedules" ADD CONSTRAINT "fee_transfer_type_valid" CHECK ("transfer_type" IN ('internal', 'p2p', 'fx'));
+
+ALTER TABLE "fee_schedules" ADD CONSTRAINT "fee_flat_fee_non_negative" CHECK ("flat_fee" >= 0);
+
+ALTER TABLE "fee_schedules" ADD CONSTRAINT "fee_percentage_bps_range" CHECK ("percentage_bps" >= 0 AND "percentage_bps" <= 10000);
+
+ALTER TABLE "fee_schedules" ADD CONSTRAINT "fee_min_max_valid" CHECK ("min_fee" >= 0 AND ("max_fee" IS NULL OR "max_fee" >= "min_fee"));
+
+COMMENT ON TABLE "fee_schedules" IS 'fees charged to the sender on top of the amount of a transfer, no row means no fee';
+
+COMMENT ON COLUMN "fee_schedules"."transfer_type" IS 'internal between accounts of one user, p2p to another user, fx across currencies';
+
+COMMENT ON COLUMN "fee_schedules"."currency" IS 'the currency of the from account, which the fee is charged in';
+
+COMMENT ON COLUMN "fee_schedules"."percentage_bps" IS 'share of the amount added to the flat fee, in basis points, rounded up to the minor unit';
+
+COMMENT ON COLUMN "fee_schedules"."max_fee" IS 'null for no cap';
+
+-- fees are credited to these accounts, so that charging a fee stays a balanced journal
+INSERT INTO "users" ("username", "hashed_password", "full_name", "email", "is_email_verified")
+VALUES ('simplebank_fees', '', 'Simple Bank fee income', 'fees@simplebank.invalid', true);
+
+INSERT INTO "accounts" ("owner", "balance", "currency")
+SELECT 'simplebank_fees', 0, "currency"
+FROM unnest(ARRAY['USD', 'EUR', 'AUD', 'CAD']) AS "currency";
+
+INSERT INTO "system_accounts" ("purpose", "currency", "account_id")
+SELECT 'fee_income', "currency", "id"
+FROM "accounts"
+WHERE "owner" = 'simplebank_fees';
@@ -0,0 +1,11 @@
+DELETE FROM "system_accounts" WHERE "purpose" IN ('cash_in', 'cash_out');
+
+DROP TABLE IF EXISTS "cash_movements";
+
+DELETE FROM "entries" WHERE "account_id" IN (SELECT "id" FROM "accounts" WHERE "owner" IN ('simplebank_cash_in', 'simplebank_cash_out'));
+
+DELETE FROM "accounts" WHERE "owner" IN ('simplebank_cash_in', 'simplebank_cash_out');
+
+DELETE FROM "users" WHERE "username" IN ('simplebank_cash_in', 'simplebank_cash_out');
+
+ALTER TABLE "users" DROP COLUMN IF EXISTS "role";
@@ -0,0 +1,50 @@
+ALTER TABLE "users" ADD COLUMN "role" varchar NOT NULL DEFAULT 'depositor';
+
+ALTER TABLE "users" ADD CONSTRAINT "user_role_valid" CHECK ("role" IN ('depositor', 'banker'));
+
+COMMENT ON COLUMN "users"."role" IS 'bankers can move cash in and out of any account, depositors only use their own';
+
+CREATE TABLE "cash_movements" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "kind" varchar NOT NULL,
+  "amount" bigint NOT NULL,
+  "reference" varchar NOT NULL DEFAULT '',
+  "journal_id" bigint NOT NULL,
+  "created_by" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "cash_movements" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "cash_movements" ADD FOREIGN KEY ("journal_id") REFERENCES "journals" ("id");
+
+ALTER TABLE "cash_movements" ADD FOREIGN KEY ("created_by") REFERENCES "users" ("username");
+
+ALTER TABLE "cash_movements" ADD CONSTRAINT "cash_movement_kind_valid" CHECK ("kind" IN ('deposit', 'withdrawal'));
+
+ALTER TABLE "cash_movements" ADD CONSTRAINT "cash_movement_amount_positive" CHECK ("amount" > 0);
+
+CREATE INDEX ON "cash_movements" ("account_id", "created_at");
+
+COMMENT ON TABLE "cash_movements" IS 'money paid into or out of the bank, posted against the cash in and cash out system accounts';
+
+COMMENT ON COLUMN "cash_movements"."created_by" IS 'the banker who recorded the movement';
+
+-- deposits are debited from cash in, which goes as negative as the money that came into the bank,
+-- and withdrawals are credited to cash out. Each purpose has its own owner, as a user can
+-- only hold one account per currency
+INSERT INTO "users" ("username", "hashed_password", "full_name", "email", "is_email_verified")
+VALUES
+  ('simplebank_cash_in', '', 'Simple Bank cash in', 'cash-in@simplebank.invalid', true),
+  ('simplebank_cash_out', '', 'Simple Bank cash out', 'cash-out@simplebank.invalid', true);
+
+INSERT INTO "accounts" ("owner", "balance", "currency", "overdraft_limit")
+SELECT "owner", 0, "currency", 9223372036854775807
+FROM unnest(ARRAY['simplebank_cash_in', 'simplebank_cash_out']) AS "owner",
+  unnest(ARRAY['USD', 'EUR', 'AUD', 'CAD']) AS "currency";
+
+INSERT INTO "system_accounts" ("purpose", "currency", "account_id")
+SELECT CASE "owner" WHEN 'simplebank_cash_in' THEN 'cash_in' ELSE 'cash_out' END, "currency", "id"
+FROM "accounts"
+WHERE "owner" IN ('simplebank_cash_in', 'simplebank_cash_out');
@@ -0,0 +1,15 @@
+DELETE FROM "cash_movements" WHERE "channel" = 'rail';
+
+ALTER TABLE "cash_movements" DROP COLUMN IF EXISTS "completed_at";
+
+ALTER TABLE "cash_movements" DROP COLUMN IF EXISTS "return_journal_id";
+
+ALTER TABLE "cash_movements" DROP COLUMN IF EXISTS "return_reason";
+
+ALTER TABLE "cash_movements" DROP COLUMN IF EXISTS "rail_reference";
+
+ALTER TABLE "cash_movements" DROP COLUMN IF EXISTS "status";
+
+ALTER TABLE "cash_movements" DROP COLUMN IF EXISTS "channel";
+
+ALTER TABLE "cash_movements" ALTER COLUMN "journal_id" SET NOT NULL;
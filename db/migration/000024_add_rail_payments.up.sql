@@ -0,0 +1,37 @@
+ALTER TABLE "cash_movements" ALTER COLUMN "journal_id" DROP NOT NULL;
+
+ALTER TABLE "cash_movements" ADD COLUMN "channel" varchar NOT NULL DEFAULT 'cash';
+
+ALTER TABLE "cash_movements" ADD COLUMN "status" varchar NOT NULL DEFAULT 'settled';
+
+ALTER TABLE "cash_movements" ADD COLUMN "rail_reference" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "cash_movements" ADD COLUMN "return_reason" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "cash_movements" ADD COLUMN "return_journal_id" bigint;
+
+ALTER TABLE "cash_movements" ADD COLUMN "completed_at" timestamptz;
+
+UPDATE "cash_movements" SET "completed_at" = "created_at";
+
+ALTER TABLE "cash_movements" ADD FOREIGN KEY ("return_journal_id") REFERENCES "journals" ("id");
+
+ALTER TABLE "cash_movements" ADD CONSTRAINT "cash_movement_channel_valid" CHECK ("channel" IN ('cash', 'rail'));
+
+ALTER TABLE "cash_movements" ADD CONSTRAINT "cash_movement_status_valid" CHECK ("status" IN ('pending', 'settled', 'returned'));
+
+-- a withdrawal is posted when it is made, a deposit only once it settles
+ALTER TABLE "cash_movements" ADD CONSTRAINT "cash_movement_journal_posted"
+  CHECK ("journal_id" IS NOT NULL OR ("kind" = 'deposit' AND "status" <> 'settled'));
+
+CREATE INDEX ON "cash_movements" ("status") WHERE "status" = 'pending';
+
+COMMENT ON COLUMN "cash_movements"."channel" IS 'cash at the counter, or rail for top-ups and payouts through an external payment rail';
+
+COMMENT ON COLUMN "cash_movements"."rail_reference" IS 'the reference the payment rail gave the payment when it was submitted';
+
+COMMENT ON COLUMN "cash_movements"."return_journal_id" IS 'the journal that gave a returned withdrawal back to the account';
+
+COMMENT ON COLUMN "cash_movements"."completed_at" IS 'when the movement was settled or returned';
+
+COMMENT ON COLUMN "cash_movements"."created_by" IS 'the banker who recorded a cash movement, or the owner who asked for a top-up or payout';
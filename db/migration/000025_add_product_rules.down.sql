@@ -0,0 +1,20 @@
+DELETE FROM "fee_schedules" WHERE "product_code" <> 'checking';
+
+ALTER TABLE "fee_schedules" DROP CONSTRAINT "fee_schedules_pkey";
+
+ALTER TABLE "fee_schedules" DROP COLUMN IF EXISTS "product_code";
+
+ALTER TABLE "fee_schedules" ADD PRIMARY KEY ("transfer_type", "currency");
+
+DROP INDEX IF EXISTS "accounts_owner_currency_product_code_idx";
+
+-- fails if a user has opened more than one account in a currency since
+ALTER TABLE "accounts" ADD CONSTRAINT "owner_currency_key" UNIQUE ("owner", "currency");
+
+UPDATE "accounts" SET "product_code" = 'checking' WHERE "product_code" = 'business';
+
+DELETE FROM "products" WHERE "code" = 'business';
+
+ALTER TABLE "products" DROP COLUMN IF EXISTS "max_accounts_per_currency";
+
+ALTER TABLE "products" DROP COLUMN IF EXISTS "overdraft_limit";
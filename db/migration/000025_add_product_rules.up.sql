@@ -0,0 +1,39 @@
+ALTER TABLE "products" ADD COLUMN "overdraft_limit" bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE "products" ADD COLUMN "max_accounts_per_currency" integer NOT NULL DEFAULT 1;
+
+ALTER TABLE "products" ADD CONSTRAINT "product_overdraft_limit_positive" CHECK ("overdraft_limit" >= 0);
+
+ALTER TABLE "products" ADD CONSTRAINT "product_max_accounts_positive" CHECK ("max_accounts_per_currency" > 0);
+
+COMMENT ON COLUMN "products"."overdraft_limit" IS 'the overdraft limit given to new accounts of the product';
+
+COMMENT ON COLUMN "products"."max_accounts_per_currency" IS 'how many accounts of the product a user may hold in each currency';
+
+UPDATE "products" SET "max_accounts_per_currency" = 5 WHERE "code" = 'savings';
+
+INSERT INTO "products" ("code", "name", "annual_interest_rate", "overdraft_limit", "max_accounts_per_currency")
+VALUES ('business', 'Business', 0, 100000, 3);
+
+-- the number of accounts a user may hold is now set per product, and checked when an account is opened
+ALTER TABLE "accounts" DROP CONSTRAINT IF EXISTS "owner_currency_key";
+
+CREATE INDEX ON "accounts" ("owner", "currency", "product_code");
+
+-- fee schedules are set per product, starting with the same fees for every product
+ALTER TABLE "fee_schedules" ADD COLUMN "product_code" varchar NOT NULL DEFAULT 'checking';
+
+ALTER TABLE "fee_schedules" ADD FOREIGN KEY ("product_code") REFERENCES "products" ("code");
+
+ALTER TABLE "fee_schedules" ALTER COLUMN "product_code" DROP DEFAULT;
+
+ALTER TABLE "fee_schedules" DROP CONSTRAINT "fee_schedules_pkey";
+
+ALTER TABLE "fee_schedules" ADD PRIMARY KEY ("product_code", "transfer_type", "currency");
+
+INSERT INTO "fee_schedules" ("product_code", "transfer_type", "currency", "flat_fee", "percentage_bps", "min_fee", "max_fee")
+SELECT "products"."code", "transfer_type", "currency", "flat_fee", "percentage_bps", "min_fee", "max_fee"
+FROM "fee_schedules", "products"
+WHERE "products"."code" <> 'checking';
+
+COMMENT ON COLUMN "fee_schedules"."product_code" IS 'the product of the account that pays the fee';
@@ -0,0 +1,5 @@
+ALTER TABLE "scheduled_transfers" DROP COLUMN IF EXISTS "metadata";
+
+ALTER TABLE "scheduled_transfers" DROP COLUMN IF EXISTS "client_reference";
+
+ALTER TABLE "scheduled_transfers" DROP COLUMN IF EXISTS "description";
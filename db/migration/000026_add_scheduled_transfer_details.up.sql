@@ -0,0 +1,11 @@
+ALTER TABLE "scheduled_transfers" ADD COLUMN "description" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "scheduled_transfers" ADD COLUMN "client_reference" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "scheduled_transfers" ADD COLUMN "metadata" jsonb NOT NULL DEFAULT '{}';
+
+COMMENT ON COLUMN "scheduled_transfers"."description" IS 'copied to the transfer when it is made';
+
+COMMENT ON COLUMN "scheduled_transfers"."client_reference" IS 'copied to the transfer when it is made';
+
+COMMENT ON COLUMN "scheduled_transfers"."metadata" IS 'copied to the transfer when it is made';
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS "cash_movements_created_at_idx";
+
+UPDATE "cash_movements" SET "status" = 'returned' WHERE "status" = 'failed';
+
+ALTER TABLE "cash_movements" DROP CONSTRAINT "cash_movement_status_valid";
+
+ALTER TABLE "cash_movements" ADD CONSTRAINT "cash_movement_status_valid" CHECK ("status" IN ('pending', 'settled', 'returned'));
+
+COMMENT ON COLUMN "cash_movements"."return_journal_id" IS 'the journal that gave a returned withdrawal back to the account';
+
+COMMENT ON COLUMN "cash_movements"."completed_at" IS 'when the movement was settled or returned';
@@ -0,0 +1,9 @@
+ALTER TABLE "cash_movements" DROP CONSTRAINT "cash_movement_status_valid";
+
+ALTER TABLE "cash_movements" ADD CONSTRAINT "cash_movement_status_valid" CHECK ("status" IN ('pending', 'settled', 'returned', 'failed'));
+
+CREATE INDEX ON "cash_movements" ("created_at") WHERE "channel" = 'rail' AND "status" = 'pending';
+
+COMMENT ON COLUMN "cash_movements"."return_journal_id" IS 'the journal that gave a returned or failed withdrawal back to the account';
+
+COMMENT ON COLUMN "cash_movements"."completed_at" IS 'when the movement was settled, returned or failed';
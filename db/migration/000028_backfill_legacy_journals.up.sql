@@ -0,0 +1,43 @@
+-- transfers made before journals were posted as a transfer and two entries in one transaction,
+-- so all three share created_at. Each such transfer whose entries can be told apart gets its own journal,
+-- so that statements show its counterparty and reconciliation checks it.
+-- Cross currency transfers from before journals have no fx position legs and are left as they are
+CREATE TEMPORARY TABLE "legacy_journals" AS
+SELECT
+  t."id" AS "transfer_id",
+  t."created_at",
+  (SELECT min(e."id") FROM "entries" e
+    WHERE e."journal_id" IS NULL AND e."account_id" = t."from_account_id"
+      AND e."amount" = -t."amount" AND e."created_at" = t."created_at") AS "from_entry_id",
+  (SELECT count(*) FROM "entries" e
+    WHERE e."journal_id" IS NULL AND e."account_id" = t."from_account_id"
+      AND e."amount" = -t."amount" AND e."created_at" = t."created_at") AS "from_entries",
+  (SELECT min(e."id") FROM "entries" e
+    WHERE e."journal_id" IS NULL AND e."account_id" = t."to_account_id"
+      AND e."amount" = t."to_amount" AND e."created_at" = t."created_at") AS "to_entry_id",
+  (SELECT count(*) FROM "entries" e
+    WHERE e."journal_id" IS NULL AND e."account_id" = t."to_account_id"
+      AND e."amount" = t."to_amount" AND e."created_at" = t."created_at") AS "to_entries",
+  NULL::bigint AS "journal_id"
+FROM "transfers" t
+JOIN "accounts" fa ON fa."id" = t."from_account_id"
+JOIN "accounts" ta ON ta."id" = t."to_account_id"
+WHERE t."journal_id" IS NULL AND fa."currency" = ta."currency";
+
+-- two transfers between the same accounts for the same amount at the same instant cannot be told apart
+DELETE FROM "legacy_journals" WHERE "from_entries" <> 1 OR "to_entries" <> 1;
+
+UPDATE "legacy_journals" SET "journal_id" = nextval(pg_get_serial_sequence('journals', 'id'));
+
+INSERT INTO "journals" ("id", "created_at")
+SELECT "journal_id", "created_at" FROM "legacy_journals";
+
+UPDATE "entries" e SET "journal_id" = l."journal_id"
+FROM "legacy_journals" l
+WHERE e."id" IN (l."from_entry_id", l."to_entry_id");
+
+UPDATE "transfers" t SET "journal_id" = l."journal_id"
+FROM "legacy_journals" l
+WHERE t."id" = l."transfer_id";
+
+DROP TABLE "legacy_journals";
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS "standing_orders_due_idx";
+
+ALTER TABLE "standing_orders" DROP COLUMN IF EXISTS "retry_at";
+
+ALTER TABLE "standing_orders" DROP COLUMN IF EXISTS "failed_attempts";
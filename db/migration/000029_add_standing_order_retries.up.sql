@@ -0,0 +1,9 @@
+ALTER TABLE "standing_orders" ADD COLUMN "failed_attempts" integer NOT NULL DEFAULT 0;
+
+ALTER TABLE "standing_orders" ADD COLUMN "retry_at" timestamptz;
+
+CREATE INDEX "standing_orders_due_idx" ON "standing_orders" ((COALESCE("retry_at", "next_run_at"))) WHERE "status" = 'active';
+
+COMMENT ON COLUMN "standing_orders"."failed_attempts" IS 'how many times running the next occurrence failed with an error that may go away';
+
+COMMENT ON COLUMN "standing_orders"."retry_at" IS 'when the next occurrence is tried again after such an error, null if it has not failed';
@@ -2,11 +2,17 @@
 INSERT INTO accounts (
   owner,
   balance,
-  currency
+  currency,
+  product_code,
+  overdraft_limit
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $3, $4, $5
 ) RETURNING *;
 
+-- name: CountAccountsByProduct :one
+SELECT count(*) FROM accounts
+WHERE owner = $1 AND currency = $2 AND product_code = $3;
+
 -- name: GetAccount :one
 SELECT * FROM accounts
 WHERE id = $1 LIMIT 1;
@@ -16,18 +22,22 @@ SELECT * FROM accounts
 WHERE id = $1 LIMIT 1
 FOR UPDATE;
 
--- name: ListAccounts :many
+-- name: GetAccountByOwnerCurrency :one
+-- A user may hold several accounts in a currency. Money sent to the user goes to their oldest checking account,
+-- or to their oldest account of any product if they have no checking account in the currency
 SELECT * FROM accounts
-WHERE owner = $1
-ORDER BY id
-LIMIT $2
-OFFSET $3;
+WHERE owner = $1 AND currency = $2
+ORDER BY product_code = 'checking' DESC, created_at, id
+LIMIT 1;
 
--- name: UpdateAccount :exec
-UPDATE accounts
-SET balance = $2
-WHERE id = $1
-RETURNING *;
+-- name: ListAccounts :many
+SELECT * FROM accounts
+WHERE owner = sqlc.arg(owner)
+  AND (sqlc.narg(cursor_created_at)::timestamptz IS NULL
+    OR (created_at, id) > (sqlc.narg(cursor_created_at), sqlc.narg(cursor_id)::bigint))
+ORDER BY created_at, id
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
 
 -- name: AddAccountBalance :one
 UPDATE accounts
@@ -39,3 +49,9 @@ RETURNING *;
 DELETE FROM accounts
 WHERE id = $1;
 
+
+-- name: AddAccountHeldAmount :one
+UPDATE accounts
+SET held_amount = held_amount + sqlc.arg(amount)
+WHERE id = sqlc.arg(id)
+RETURNING *;
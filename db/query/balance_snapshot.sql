@@ -0,0 +1,33 @@
+-- name: CreateBalanceSnapshots :execrows
+-- takes the end of day balance of every account open by then, worked back from the current balance
+INSERT INTO balance_snapshots (
+  account_id,
+  snapshot_date,
+  balance
+)
+SELECT
+  a.id,
+  sqlc.arg(snapshot_date)::date,
+  (a.balance - COALESCE(SUM(e.amount), 0))::bigint
+FROM accounts a
+LEFT JOIN entries e ON e.account_id = a.id
+  AND e.created_at >= (sqlc.arg(snapshot_date)::date + 1)::timestamp AT TIME ZONE 'UTC'
+WHERE a.created_at < (sqlc.arg(snapshot_date)::date + 1)::timestamp AT TIME ZONE 'UTC'
+GROUP BY a.id
+ON CONFLICT (account_id, snapshot_date) DO NOTHING;
+
+-- name: GetBalanceSnapshotResumeDate :one
+-- the first day after the latest snapshots, or the day the first account was opened
+SELECT COALESCE(
+  (SELECT MAX(snapshot_date) + 1 FROM balance_snapshots),
+  (SELECT MIN(created_at AT TIME ZONE 'UTC')::date FROM accounts),
+  CURRENT_DATE
+)::date AS resume_date;
+
+-- name: GetLatestBalanceSnapshot :one
+-- the latest snapshot of the account taken at or before as_of
+SELECT * FROM balance_snapshots
+WHERE account_id = sqlc.arg(account_id)
+  AND (snapshot_date + 1)::timestamp AT TIME ZONE 'UTC' <= sqlc.arg(as_of)::timestamptz
+ORDER BY snapshot_date DESC
+LIMIT 1;
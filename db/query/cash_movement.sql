@@ -0,0 +1,52 @@
+-- name: CreateCashMovement :one
+INSERT INTO cash_movements (
+  account_id,
+  kind,
+  amount,
+  reference,
+  journal_id,
+  created_by,
+  channel,
+  status,
+  completed_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9
+)
+RETURNING *;
+
+-- name: GetCashMovement :one
+SELECT * FROM cash_movements
+WHERE id = $1 LIMIT 1;
+
+-- name: GetCashMovementForUpdate :one
+SELECT * FROM cash_movements
+WHERE id = $1 LIMIT 1
+FOR UPDATE;
+
+-- name: SetCashMovementRailReference :one
+UPDATE cash_movements
+SET rail_reference = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: CompleteCashMovement :one
+UPDATE cash_movements
+SET
+  status = sqlc.arg(status),
+  journal_id = COALESCE(sqlc.narg(journal_id), journal_id),
+  return_journal_id = sqlc.narg(return_journal_id),
+  return_reason = sqlc.arg(return_reason),
+  completed_at = now()
+WHERE
+  id = sqlc.arg(id)
+  AND status = 'pending'
+RETURNING *;
+
+-- name: ListStaleRailPayments :many
+-- rail payments still pending that were created before the given time, oldest first
+SELECT * FROM cash_movements
+WHERE channel = 'rail'
+  AND status = 'pending'
+  AND created_at < sqlc.arg(created_before)
+ORDER BY created_at, id
+LIMIT sqlc.arg(max_count);
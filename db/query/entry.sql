@@ -1,9 +1,10 @@
 -- name: CreateEntry :one
 INSERT INTO entries (
   account_id,
-  amount
+  amount,
+  journal_id
 ) VALUES (
-  $1, $2
+  $1, $2, $3
 ) RETURNING *;
 
 -- name: GetEntry :one
@@ -12,7 +13,91 @@ WHERE id = $1 LIMIT 1;
 
 -- name: ListEntries :many
 SELECT * FROM entries
-WHERE account_id = $1
-ORDER BY id
-LIMIT $2
-OFFSET $3;
\ No newline at end of file
+WHERE account_id = sqlc.arg(account_id)
+  AND (sqlc.narg(cursor_created_at)::timestamptz IS NULL
+    OR (created_at, id) > (sqlc.narg(cursor_created_at), sqlc.narg(cursor_id)::bigint))
+ORDER BY created_at, id
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: ListJournalEntries :many
+SELECT * FROM entries
+WHERE journal_id = $1
+ORDER BY id;
+-- name: ListAccountStatement :many
+SELECT
+    s.id,
+    s.account_id,
+    s.amount,
+    s.created_at,
+    s.balance,
+    t.id AS transfer_id,
+    t.from_account_id,
+    t.to_account_id,
+    t.description
+FROM (
+    -- the balance after each entry is worked back from the current balance,
+    -- so accounts opened with a balance and no entry still add up
+    SELECT
+        e.id,
+        e.account_id,
+        e.amount,
+        e.created_at,
+        e.journal_id,
+        (a.balance - COALESCE(SUM(e.amount) OVER (
+            ORDER BY e.created_at DESC, e.id DESC
+            ROWS BETWEEN UNBOUNDED PRECEDING AND 1 PRECEDING
+        ), 0))::bigint AS balance
+    FROM entries e
+    JOIN accounts a ON a.id = e.account_id
+    WHERE e.account_id = @account_id
+        AND (sqlc.narg(created_from)::timestamptz IS NULL OR e.created_at >= sqlc.narg(created_from))
+) s
+LEFT JOIN transfers t ON t.journal_id = s.journal_id
+WHERE (sqlc.narg(created_to)::timestamptz IS NULL OR s.created_at < sqlc.narg(created_to))
+    AND (sqlc.narg(cursor_created_at)::timestamptz IS NULL
+        OR (s.created_at, s.id) > (sqlc.narg(cursor_created_at), sqlc.narg(cursor_id)::bigint))
+ORDER BY s.created_at, s.id
+LIMIT @limit_count
+OFFSET @offset_count;
+
+-- name: ListAccountStatementEntries :many
+-- the entries of a statement without their balance, for exports that carry the balance forward themselves
+SELECT
+    e.id,
+    e.account_id,
+    e.amount,
+    e.created_at,
+    t.id AS transfer_id,
+    t.from_account_id,
+    t.to_account_id,
+    t.description
+FROM entries e
+LEFT JOIN transfers t ON t.journal_id = e.journal_id
+WHERE e.account_id = @account_id
+    AND (sqlc.narg(created_from)::timestamptz IS NULL OR e.created_at >= sqlc.narg(created_from))
+    AND (sqlc.narg(created_to)::timestamptz IS NULL OR e.created_at < sqlc.narg(created_to))
+    AND (sqlc.narg(cursor_created_at)::timestamptz IS NULL
+        OR (e.created_at, e.id) > (sqlc.narg(cursor_created_at), sqlc.narg(cursor_id)::bigint))
+ORDER BY e.created_at, e.id
+LIMIT @limit_count;
+
+-- name: GetAccountStatementBalances :one
+SELECT
+    (a.balance - COALESCE(SUM(e.amount) FILTER (
+        WHERE sqlc.narg(created_from)::timestamptz IS NULL OR e.created_at >= sqlc.narg(created_from)
+    ), 0))::bigint AS opening_balance,
+    (a.balance - COALESCE(SUM(e.amount) FILTER (
+        WHERE e.created_at >= sqlc.narg(created_to)::timestamptz
+    ), 0))::bigint AS closing_balance
+FROM accounts a
+LEFT JOIN entries e ON e.account_id = a.id
+WHERE a.id = @account_id
+GROUP BY a.id;
+
+-- name: SumAccountEntries :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS total
+FROM entries
+WHERE account_id = sqlc.arg(account_id)
+  AND (sqlc.narg(created_from)::timestamptz IS NULL OR created_at >= sqlc.narg(created_from))
+  AND (sqlc.narg(created_to)::timestamptz IS NULL OR created_at < sqlc.narg(created_to));
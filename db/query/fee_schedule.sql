@@ -0,0 +1,34 @@
+-- name: GetFeeSchedule :one
+SELECT * FROM fee_schedules
+WHERE product_code = $1 AND transfer_type = $2 AND currency = $3
+LIMIT 1;
+
+-- name: ListFeeSchedules :many
+SELECT * FROM fee_schedules
+WHERE product_code = $1 AND currency = $2
+ORDER BY transfer_type;
+
+-- name: UpsertFeeSchedule :one
+INSERT INTO fee_schedules (
+  product_code,
+  transfer_type,
+  currency,
+  flat_fee,
+  percentage_bps,
+  min_fee,
+  max_fee
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+)
+ON CONFLICT (product_code, transfer_type, currency) DO UPDATE
+SET
+  flat_fee = EXCLUDED.flat_fee,
+  percentage_bps = EXCLUDED.percentage_bps,
+  min_fee = EXCLUDED.min_fee,
+  max_fee = EXCLUDED.max_fee,
+  updated_at = now()
+RETURNING *;
+
+-- name: DeleteFeeSchedule :exec
+DELETE FROM fee_schedules
+WHERE product_code = $1 AND transfer_type = $2 AND currency = $3;
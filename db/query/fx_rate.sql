@@ -0,0 +1,20 @@
+-- name: GetFxRate :one
+SELECT * FROM fx_rates
+WHERE base_currency = $1 AND quote_currency = $2
+LIMIT 1;
+
+-- name: UpsertFxRate :one
+INSERT INTO fx_rates (
+  base_currency,
+  quote_currency,
+  rate,
+  spread_bps
+) VALUES (
+  $1, $2, $3, $4
+)
+ON CONFLICT (base_currency, quote_currency) DO UPDATE
+SET
+  rate = EXCLUDED.rate,
+  spread_bps = EXCLUDED.spread_bps,
+  updated_at = now()
+RETURNING *;
@@ -0,0 +1,37 @@
+-- name: CreateHold :one
+INSERT INTO holds (
+  from_account_id,
+  to_account_id,
+  amount,
+  to_currency,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetHold :one
+SELECT * FROM holds
+WHERE id = $1 LIMIT 1;
+
+-- name: GetHoldForUpdate :one
+SELECT * FROM holds
+WHERE id = $1 LIMIT 1
+FOR UPDATE;
+
+-- name: ListExpiredHolds :many
+SELECT * FROM holds
+WHERE status = 'authorized' AND expires_at <= sqlc.arg(expired_at)
+ORDER BY expires_at
+LIMIT sqlc.arg(max_count);
+
+-- name: UpdateHold :one
+UPDATE holds
+SET
+  status = sqlc.arg(status),
+  captured_amount = sqlc.arg(captured_amount),
+  transfer_id = sqlc.narg(transfer_id),
+  updated_at = now()
+WHERE
+  id = sqlc.arg(id)
+  AND status = 'authorized'
+RETURNING *;
@@ -0,0 +1,80 @@
+-- name: ListInterestBearingBalances :many
+-- the positive end of day balances of the accounts open by then whose product pays interest,
+-- worked back from the current balance
+SELECT
+  a.id AS account_id,
+  p.annual_interest_rate,
+  (a.balance - COALESCE(SUM(e.amount), 0))::bigint AS balance
+FROM accounts a
+JOIN products p ON p.code = a.product_code
+LEFT JOIN entries e ON e.account_id = a.id
+  AND e.created_at >= (sqlc.arg(accrual_date)::date + 1)::timestamp AT TIME ZONE 'UTC'
+WHERE p.annual_interest_rate > 0
+  AND a.created_at < (sqlc.arg(accrual_date)::date + 1)::timestamp AT TIME ZONE 'UTC'
+GROUP BY a.id, p.annual_interest_rate
+HAVING a.balance - COALESCE(SUM(e.amount), 0) > 0
+ORDER BY a.id;
+
+-- name: CreateInterestAccrual :execrows
+-- does nothing if the account already accrued interest for the day
+INSERT INTO interest_accruals (
+  account_id,
+  accrual_date,
+  balance,
+  annual_interest_rate,
+  amount
+) VALUES (
+  $1, $2, $3, $4, $5
+) ON CONFLICT (account_id, accrual_date) DO NOTHING;
+
+-- name: GetInterestAccrualResumeDate :one
+-- the first day after the latest accruals, or yesterday in UTC when interest has never been accrued
+SELECT COALESCE(
+  MAX(accrual_date) + 1,
+  (now() AT TIME ZONE 'UTC')::date - 1
+)::date AS resume_date
+FROM interest_accruals;
+
+-- name: ListUnpostedInterestPeriods :many
+-- the months before the given day that accrued interest not posted yet, per account
+SELECT
+  account_id,
+  date_trunc('month', accrual_date)::date AS period
+FROM interest_accruals
+WHERE posting_id IS NULL
+  AND accrual_date < sqlc.arg(before)::date
+GROUP BY account_id, period
+ORDER BY period, account_id;
+
+-- name: SumUnpostedInterestAccruals :one
+SELECT COALESCE(SUM(amount), 0)::numeric AS amount
+FROM interest_accruals
+WHERE account_id = sqlc.arg(account_id)
+  AND posting_id IS NULL
+  AND accrual_date >= sqlc.arg(period)::date
+  AND accrual_date < (sqlc.arg(period)::date + interval '1 month');
+
+-- name: MarkInterestAccrualsPosted :execrows
+UPDATE interest_accruals
+SET posting_id = sqlc.arg(posting_id)
+WHERE account_id = sqlc.arg(account_id)
+  AND posting_id IS NULL
+  AND accrual_date >= sqlc.arg(period)::date
+  AND accrual_date < (sqlc.arg(period)::date + interval '1 month');
+
+-- name: CreateInterestPosting :one
+-- returns no row if the month was already posted for the account
+INSERT INTO interest_postings (
+  account_id,
+  period,
+  amount
+) VALUES (
+  $1, $2, $3
+) ON CONFLICT (account_id, period) DO NOTHING
+RETURNING *;
+
+-- name: SetInterestPostingJournal :one
+UPDATE interest_postings
+SET journal_id = $2
+WHERE id = $1
+RETURNING *;
@@ -0,0 +1,3 @@
+-- name: CreateJournal :one
+INSERT INTO journals DEFAULT VALUES
+RETURNING *;
@@ -0,0 +1,60 @@
+-- name: CreatePaymentRequest :one
+INSERT INTO payment_requests (
+  requester,
+  payer,
+  to_account_id,
+  amount,
+  currency,
+  description,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: GetPaymentRequest :one
+SELECT * FROM payment_requests
+WHERE id = $1 LIMIT 1;
+
+-- name: GetPaymentRequestForUpdate :one
+SELECT * FROM payment_requests
+WHERE id = $1 LIMIT 1
+FOR UPDATE;
+
+-- name: ListPaymentRequestsByPayer :many
+SELECT * FROM payment_requests
+WHERE payer = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: ListPaymentRequestsByRequester :many
+SELECT * FROM payment_requests
+WHERE requester = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: UpdatePaymentRequest :one
+UPDATE payment_requests
+SET
+  status = sqlc.arg(status),
+  transfer_id = sqlc.narg(transfer_id),
+  updated_at = now()
+WHERE
+  id = sqlc.arg(id)
+  AND status = 'pending'
+RETURNING *;
+
+-- name: ExpirePaymentRequests :many
+UPDATE payment_requests
+SET
+  status = 'expired',
+  updated_at = now()
+WHERE id IN (
+  SELECT pr.id FROM payment_requests pr
+  WHERE pr.status = 'pending' AND pr.expires_at <= sqlc.arg(expired_at)
+  ORDER BY pr.expires_at
+  LIMIT sqlc.arg(max_count)
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
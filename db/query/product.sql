@@ -0,0 +1,7 @@
+-- name: GetProduct :one
+SELECT * FROM products
+WHERE code = $1 LIMIT 1;
+
+-- name: ListProducts :many
+SELECT * FROM products
+ORDER BY code;
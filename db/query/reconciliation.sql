@@ -0,0 +1,46 @@
+-- name: CreateReconciliationRun :one
+INSERT INTO reconciliation_runs (
+  status,
+  accounts_checked,
+  transfers_checked,
+  discrepancy_count,
+  report,
+  started_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetReconciliationRun :one
+SELECT * FROM reconciliation_runs
+WHERE id = $1 LIMIT 1;
+
+-- name: CountReconciliationScope :one
+SELECT
+  (SELECT count(*) FROM accounts) AS accounts,
+  (SELECT count(*) FROM transfers WHERE journal_id IS NOT NULL) AS transfers,
+  (SELECT count(*) FROM transfers WHERE journal_id IS NULL) AS unchecked_transfers;
+
+-- name: ListBalanceDrifts :many
+SELECT
+  a.id AS account_id,
+  a.currency,
+  a.balance,
+  COALESCE(SUM(e.amount), 0)::bigint AS entry_sum
+FROM accounts a
+LEFT JOIN entries e ON e.account_id = a.id
+GROUP BY a.id
+HAVING a.balance <> COALESCE(SUM(e.amount), 0)
+ORDER BY a.id;
+
+-- name: ListUnbalancedTransfers :many
+-- cross currency transfers go through the fx position accounts, so the entries net to zero per currency
+SELECT
+  t.id AS transfer_id,
+  a.currency,
+  SUM(e.amount)::bigint AS net_amount
+FROM transfers t
+JOIN entries e ON e.journal_id = t.journal_id
+JOIN accounts a ON a.id = e.account_id
+GROUP BY t.id, a.currency
+HAVING SUM(e.amount) <> 0
+ORDER BY t.id, a.currency;
@@ -0,0 +1,49 @@
+-- name: CreateScheduledTransfer :one
+INSERT INTO scheduled_transfers (
+  owner,
+  from_account_id,
+  to_account_id,
+  amount,
+  to_currency,
+  execute_at,
+  description,
+  client_reference,
+  metadata
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9
+) RETURNING *;
+
+-- name: GetScheduledTransfer :one
+SELECT * FROM scheduled_transfers
+WHERE id = $1 LIMIT 1;
+
+-- name: GetScheduledTransferForUpdate :one
+SELECT * FROM scheduled_transfers
+WHERE id = $1 LIMIT 1
+FOR UPDATE;
+
+-- name: ListScheduledTransfers :many
+SELECT * FROM scheduled_transfers
+WHERE owner = $1
+ORDER BY execute_at DESC, id DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: UpdateScheduledTransfer :one
+UPDATE scheduled_transfers
+SET
+  status = sqlc.arg(status),
+  failure_reason = sqlc.arg(failure_reason),
+  transfer_id = sqlc.narg(transfer_id),
+  updated_at = now()
+WHERE
+  id = sqlc.arg(id)
+  AND status = 'scheduled'
+RETURNING *;
+
+-- name: ListOverdueScheduledTransfers :many
+-- scheduled transfers that should have been executed before the given time, oldest first
+SELECT * FROM scheduled_transfers
+WHERE status = 'scheduled' AND execute_at < sqlc.arg(execute_before)
+ORDER BY execute_at, id
+LIMIT sqlc.arg(max_count);
@@ -0,0 +1,65 @@
+-- name: CreateStandingOrder :one
+INSERT INTO standing_orders (
+  owner,
+  from_account_id,
+  to_account_id,
+  amount,
+  to_currency,
+  schedule,
+  next_run_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: GetStandingOrder :one
+SELECT * FROM standing_orders
+WHERE id = $1 LIMIT 1;
+
+-- name: GetStandingOrderForUpdate :one
+SELECT * FROM standing_orders
+WHERE id = $1 LIMIT 1
+FOR UPDATE;
+
+-- name: ListStandingOrders :many
+SELECT * FROM standing_orders
+WHERE owner = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
+
+-- name: ListDueStandingOrders :many
+-- an occurrence that failed with an error that may go away is only due again at its retry_at
+SELECT * FROM standing_orders
+WHERE status = 'active' AND COALESCE(retry_at, next_run_at) <= sqlc.arg(due_at)::timestamptz
+ORDER BY COALESCE(retry_at, next_run_at)
+LIMIT sqlc.arg(max_count);
+
+-- name: UpdateStandingOrderNextRun :one
+UPDATE standing_orders
+SET
+  next_run_at = $2,
+  failed_attempts = 0,
+  retry_at = NULL,
+  updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: RetryStandingOrderRun :one
+UPDATE standing_orders
+SET
+  failed_attempts = failed_attempts + 1,
+  retry_at = sqlc.arg(retry_at)::timestamptz,
+  updated_at = now()
+WHERE
+  id = sqlc.arg(id)
+  AND status = 'active'
+  AND next_run_at = sqlc.arg(scheduled_for)
+RETURNING *;
+
+-- name: CancelStandingOrder :one
+UPDATE standing_orders
+SET
+  status = 'cancelled',
+  updated_at = now()
+WHERE id = $1 AND status = 'active'
+RETURNING *;
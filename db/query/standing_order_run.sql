@@ -0,0 +1,19 @@
+-- name: CreateStandingOrderRun :one
+INSERT INTO standing_order_runs (
+  standing_order_id,
+  scheduled_for,
+  status,
+  failure_reason,
+  transfer_id
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+ON CONFLICT (standing_order_id, scheduled_for) DO NOTHING
+RETURNING *;
+
+-- name: ListStandingOrderRuns :many
+SELECT * FROM standing_order_runs
+WHERE standing_order_id = $1
+ORDER BY scheduled_for DESC
+LIMIT $2
+OFFSET $3;
@@ -2,15 +2,33 @@
 INSERT INTO transfers (
   from_account_id,
   to_account_id,
-  amount
+  amount,
+  to_amount,
+  fx_rate,
+  fx_spread_bps,
+  reversed_transfer_id,
+  journal_id,
+  description,
+  client_reference,
+  metadata
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
 ) RETURNING *;
 
 -- name: GetTransfer :one
 SELECT * FROM transfers
 WHERE id = $1 LIMIT 1;
 
+-- name: GetTransferForUpdate :one
+SELECT * FROM transfers
+WHERE id = $1 LIMIT 1
+FOR UPDATE;
+
+-- name: GetTransferReversedAmount :one
+SELECT COALESCE(SUM(to_amount), 0)::bigint AS reversed_amount
+FROM transfers
+WHERE reversed_transfer_id = $1;
+
 -- name: ListTransfers :many
 SELECT * FROM transfers
 WHERE 
@@ -27,11 +45,51 @@ SELECT
     t.to_account_id,
     t.amount,
     t.created_at,
-    a_from.currency  -- Adding the currency from the 'from' account
+    a_from.currency,  -- Adding the currency from the 'from' account
+    t.to_amount,
+    a_to.currency AS to_currency,
+    t.description,
+    t.client_reference,
+    t.metadata,
+    t.status,
+    t.status_reason,
+    t.updated_at
 FROM transfers t
          JOIN accounts a_from ON t.from_account_id = a_from.id
-         JOIN accounts a_to ON t.to_account_id = a_to.id -- This join is still needed for the WHERE clause
-WHERE a_from.owner = $1 OR a_to.owner = $1
-ORDER BY t.created_at DESC
-LIMIT $2
-    OFFSET $3;
\ No newline at end of file
+         JOIN accounts a_to ON t.to_account_id = a_to.id
+WHERE (
+        -- transfers out of the caller's accounts
+        (a_from.owner = @owner
+            AND (sqlc.narg(direction)::varchar IS NULL OR sqlc.narg(direction) = 'outgoing')
+            AND (sqlc.narg(account_id)::bigint IS NULL OR t.from_account_id = sqlc.narg(account_id))
+            AND (sqlc.narg(currency)::varchar IS NULL OR a_from.currency = sqlc.narg(currency))
+            AND (sqlc.narg(counterparty_account_id)::bigint IS NULL OR t.to_account_id = sqlc.narg(counterparty_account_id))
+            AND (sqlc.narg(min_amount)::bigint IS NULL OR t.amount >= sqlc.narg(min_amount))
+            AND (sqlc.narg(max_amount)::bigint IS NULL OR t.amount <= sqlc.narg(max_amount)))
+        -- transfers into the caller's accounts, filtered on the received amount
+        OR (a_to.owner = @owner
+            AND (sqlc.narg(direction)::varchar IS NULL OR sqlc.narg(direction) = 'incoming')
+            AND (sqlc.narg(account_id)::bigint IS NULL OR t.to_account_id = sqlc.narg(account_id))
+            AND (sqlc.narg(currency)::varchar IS NULL OR a_to.currency = sqlc.narg(currency))
+            AND (sqlc.narg(counterparty_account_id)::bigint IS NULL OR t.from_account_id = sqlc.narg(counterparty_account_id))
+            AND (sqlc.narg(min_amount)::bigint IS NULL OR t.to_amount >= sqlc.narg(min_amount))
+            AND (sqlc.narg(max_amount)::bigint IS NULL OR t.to_amount <= sqlc.narg(max_amount)))
+    )
+    AND (sqlc.narg(client_reference)::varchar IS NULL OR t.client_reference = sqlc.narg(client_reference))
+    AND (sqlc.narg(status)::varchar IS NULL OR t.status = sqlc.narg(status))
+    AND (sqlc.narg(created_from)::timestamptz IS NULL OR t.created_at >= sqlc.narg(created_from))
+    AND (sqlc.narg(created_to)::timestamptz IS NULL OR t.created_at < sqlc.narg(created_to))
+    AND (sqlc.narg(cursor_created_at)::timestamptz IS NULL
+        OR (t.created_at, t.id) < (sqlc.narg(cursor_created_at), sqlc.narg(cursor_id)::bigint))
+ORDER BY t.created_at DESC, t.id DESC
+LIMIT @limit_count
+    OFFSET @offset_count;
+
+-- name: UpdateTransferStatus :one
+UPDATE transfers
+SET
+  status = $2,
+  status_reason = $3,
+  updated_at = now()
+WHERE id = $1
+RETURNING *;
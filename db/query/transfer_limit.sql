@@ -0,0 +1,47 @@
+-- name: GetTransferLimit :one
+SELECT * FROM transfer_limits
+WHERE username = $1 AND currency = $2
+LIMIT 1;
+
+-- name: UpsertTransferLimit :one
+INSERT INTO transfer_limits (
+  username,
+  currency,
+  daily_limit,
+  monthly_limit
+) VALUES (
+  $1, $2, $3, $4
+)
+ON CONFLICT (username, currency) DO UPDATE
+SET
+  daily_limit = EXCLUDED.daily_limit,
+  monthly_limit = EXCLUDED.monthly_limit,
+  updated_at = now()
+RETURNING *;
+
+-- name: LockTransferLimit :exec
+SELECT pg_advisory_xact_lock(hashtext(@owner::text || ':' || @currency::text));
+
+-- name: GetSentAmounts :one
+SELECT
+  COALESCE(SUM(t.amount) FILTER (WHERE t.created_at >= @day_start), 0)::bigint AS daily_amount,
+  COALESCE(SUM(t.amount), 0)::bigint AS monthly_amount
+FROM transfers t
+JOIN accounts a ON a.id = t.from_account_id
+WHERE a.owner = @owner
+  AND a.currency = @currency
+  AND t.created_at >= @month_start
+  AND t.reversed_transfer_id IS NULL
+  AND t.status <> 'failed';
+
+-- name: GetAuthorizedHoldAmounts :one
+-- holds still authorized count against the limits like sent transfers, until they are captured or released
+SELECT
+  COALESCE(SUM(h.amount) FILTER (WHERE h.created_at >= @day_start), 0)::bigint AS daily_amount,
+  COALESCE(SUM(h.amount), 0)::bigint AS monthly_amount
+FROM holds h
+JOIN accounts a ON a.id = h.from_account_id
+WHERE a.owner = @owner
+  AND a.currency = @currency
+  AND h.created_at >= @month_start
+  AND h.status = 'authorized';
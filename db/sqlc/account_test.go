@@ -18,9 +18,10 @@ func createRandomAccount(t *testing.T) Account {
 	user := createRandomUser(t)
 
 	arg := CreateAccountParams{
-		Owner:    user.Username,
-		Balance:  util.RandomMoney(),
-		Currency: util.RandomCurrency(),
+		Owner:       user.Username,
+		Balance:     util.RandomMoney(),
+		Currency:    util.RandomCurrency(),
+		ProductCode: ProductChecking,
 	}
 
 	account, err := testQueries.CreateAccount(context.Background(), arg)
@@ -30,6 +31,7 @@ func createRandomAccount(t *testing.T) Account {
 	require.Equal(t, arg.Owner, account.Owner)
 	require.Equal(t, arg.Balance, account.Balance)
 	require.Equal(t, arg.Currency, account.Currency)
+	require.Equal(t, arg.ProductCode, account.ProductCode)
 
 	require.NotZero(t, account.ID)
 	require.NotZero(t, account.CreatedAt)
@@ -50,17 +52,17 @@ func TestGetAccount(t *testing.T) {
 	require.WithinDuration(t, account1.CreatedAt, account2.CreatedAt, time.Second)
 }
 
-func TestUpdateAccount(t *testing.T) {
+func TestAddAccountBalance(t *testing.T) {
 	account1 := createRandomAccount(t)
 
-	arg := UpdateAccountParams{
-		ID:      account1.ID,
-		Balance: util.RandomMoney(),
-	}
-
-	err := testQueries.UpdateAccount(context.Background(), arg)
+	amount := util.RandomMoney()
+	account2, err := testQueries.AddAccountBalance(context.Background(), AddAccountBalanceParams{
+		ID:     account1.ID,
+		Amount: amount,
+	})
 
 	require.NoError(t, err)
+	require.Equal(t, account1.Balance+amount, account2.Balance)
 }
 
 func TestDeleteAccount(t *testing.T) {
@@ -7,36 +7,73 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
 const createEntry = `-- name: CreateEntry :one
 INSERT INTO entries (
   account_id,
-  amount
+  amount,
+  journal_id
 ) VALUES (
-  $1, $2
-) RETURNING id, account_id, amount, created_at
+  $1, $2, $3
+) RETURNING id, account_id, amount, created_at, journal_id
 `
 
 type CreateEntryParams struct {
-	AccountID int64 `json:"account_id"`
-	Amount    int64 `json:"amount"`
+	AccountID int64         `json:"account_id"`
+	Amount    int64         `json:"amount"`
+	JournalID sql.NullInt64 `json:"journal_id"`
 }
 
 func (q *Queries) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
-	row := q.db.QueryRowContext(ctx, createEntry, arg.AccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createEntry, arg.AccountID, arg.Amount, arg.JournalID)
 	var i Entry
 	err := row.Scan(
 		&i.ID,
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.JournalID,
 	)
 	return i, err
 }
 
+const getAccountStatementBalances = `-- name: GetAccountStatementBalances :one
+SELECT
+    (a.balance - COALESCE(SUM(e.amount) FILTER (
+        WHERE $1::timestamptz IS NULL OR e.created_at >= $1
+    ), 0))::bigint AS opening_balance,
+    (a.balance - COALESCE(SUM(e.amount) FILTER (
+        WHERE e.created_at >= $2::timestamptz
+    ), 0))::bigint AS closing_balance
+FROM accounts a
+LEFT JOIN entries e ON e.account_id = a.id
+WHERE a.id = $3
+GROUP BY a.id
+`
+
+type GetAccountStatementBalancesParams struct {
+	CreatedFrom sql.NullTime `json:"created_from"`
+	CreatedTo   sql.NullTime `json:"created_to"`
+	AccountID   int64        `json:"account_id"`
+}
+
+type GetAccountStatementBalancesRow struct {
+	OpeningBalance int64 `json:"opening_balance"`
+	ClosingBalance int64 `json:"closing_balance"`
+}
+
+func (q *Queries) GetAccountStatementBalances(ctx context.Context, arg GetAccountStatementBalancesParams) (GetAccountStatementBalancesRow, error) {
+	row := q.db.QueryRowContext(ctx, getAccountStatementBalances, arg.CreatedFrom, arg.CreatedTo, arg.AccountID)
+	var i GetAccountStatementBalancesRow
+	err := row.Scan(&i.OpeningBalance, &i.ClosingBalance)
+	return i, err
+}
+
 const getEntry = `-- name: GetEntry :one
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, journal_id FROM entries
 WHERE id = $1 LIMIT 1
 `
 
@@ -48,26 +85,254 @@ func (q *Queries) GetEntry(ctx context.Context, id int64) (Entry, error) {
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.JournalID,
 	)
 	return i, err
 }
 
+const listAccountStatement = `-- name: ListAccountStatement :many
+SELECT
+    s.id,
+    s.account_id,
+    s.amount,
+    s.created_at,
+    s.balance,
+    t.id AS transfer_id,
+    t.from_account_id,
+    t.to_account_id,
+    t.description
+FROM (
+    -- the balance after each entry is worked back from the current balance,
+    -- so accounts opened with a balance and no entry still add up
+    SELECT
+        e.id,
+        e.account_id,
+        e.amount,
+        e.created_at,
+        e.journal_id,
+        (a.balance - COALESCE(SUM(e.amount) OVER (
+            ORDER BY e.created_at DESC, e.id DESC
+            ROWS BETWEEN UNBOUNDED PRECEDING AND 1 PRECEDING
+        ), 0))::bigint AS balance
+    FROM entries e
+    JOIN accounts a ON a.id = e.account_id
+    WHERE e.account_id = $1
+        AND ($2::timestamptz IS NULL OR e.created_at >= $2)
+) s
+LEFT JOIN transfers t ON t.journal_id = s.journal_id
+WHERE ($3::timestamptz IS NULL OR s.created_at < $3)
+    AND ($4::timestamptz IS NULL
+        OR (s.created_at, s.id) > ($4, $5::bigint))
+ORDER BY s.created_at, s.id
+LIMIT $7
+OFFSET $6
+`
+
+type ListAccountStatementParams struct {
+	AccountID       int64         `json:"account_id"`
+	CreatedFrom     sql.NullTime  `json:"created_from"`
+	CreatedTo       sql.NullTime  `json:"created_to"`
+	CursorCreatedAt sql.NullTime  `json:"cursor_created_at"`
+	CursorID        sql.NullInt64 `json:"cursor_id"`
+	OffsetCount     int32         `json:"offset_count"`
+	LimitCount      int32         `json:"limit_count"`
+}
+
+type ListAccountStatementRow struct {
+	ID            int64          `json:"id"`
+	AccountID     int64          `json:"account_id"`
+	Amount        int64          `json:"amount"`
+	CreatedAt     time.Time      `json:"created_at"`
+	Balance       int64          `json:"balance"`
+	TransferID    sql.NullInt64  `json:"transfer_id"`
+	FromAccountID sql.NullInt64  `json:"from_account_id"`
+	ToAccountID   sql.NullInt64  `json:"to_account_id"`
+	Description   sql.NullString `json:"description"`
+}
+
+func (q *Queries) ListAccountStatement(ctx context.Context, arg ListAccountStatementParams) ([]ListAccountStatementRow, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountStatement,
+		arg.AccountID,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.OffsetCount,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAccountStatementRow
+	for rows.Next() {
+		var i ListAccountStatementRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.Balance,
+			&i.TransferID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Description,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountStatementEntries = `-- name: ListAccountStatementEntries :many
+SELECT
+    e.id,
+    e.account_id,
+    e.amount,
+    e.created_at,
+    t.id AS transfer_id,
+    t.from_account_id,
+    t.to_account_id,
+    t.description
+FROM entries e
+LEFT JOIN transfers t ON t.journal_id = e.journal_id
+WHERE e.account_id = $1
+    AND ($2::timestamptz IS NULL OR e.created_at >= $2)
+    AND ($3::timestamptz IS NULL OR e.created_at < $3)
+    AND ($4::timestamptz IS NULL
+        OR (e.created_at, e.id) > ($4, $5::bigint))
+ORDER BY e.created_at, e.id
+LIMIT $6
+`
+
+type ListAccountStatementEntriesParams struct {
+	AccountID       int64         `json:"account_id"`
+	CreatedFrom     sql.NullTime  `json:"created_from"`
+	CreatedTo       sql.NullTime  `json:"created_to"`
+	CursorCreatedAt sql.NullTime  `json:"cursor_created_at"`
+	CursorID        sql.NullInt64 `json:"cursor_id"`
+	LimitCount      int32         `json:"limit_count"`
+}
+
+type ListAccountStatementEntriesRow struct {
+	ID            int64          `json:"id"`
+	AccountID     int64          `json:"account_id"`
+	Amount        int64          `json:"amount"`
+	CreatedAt     time.Time      `json:"created_at"`
+	TransferID    sql.NullInt64  `json:"transfer_id"`
+	FromAccountID sql.NullInt64  `json:"from_account_id"`
+	ToAccountID   sql.NullInt64  `json:"to_account_id"`
+	Description   sql.NullString `json:"description"`
+}
+
+// the entries of a statement without their balance, for exports that carry the balance forward themselves
+func (q *Queries) ListAccountStatementEntries(ctx context.Context, arg ListAccountStatementEntriesParams) ([]ListAccountStatementEntriesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountStatementEntries,
+		arg.AccountID,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAccountStatementEntriesRow
+	for rows.Next() {
+		var i ListAccountStatementEntriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Description,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listEntries = `-- name: ListEntries :many
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, journal_id FROM entries
 WHERE account_id = $1
-ORDER BY id
-LIMIT $2
-OFFSET $3
+  AND ($2::timestamptz IS NULL
+    OR (created_at, id) > ($2, $3::bigint))
+ORDER BY created_at, id
+LIMIT $5
+OFFSET $4
 `
 
 type ListEntriesParams struct {
-	AccountID int64 `json:"account_id"`
-	Limit     int32 `json:"limit"`
-	Offset    int32 `json:"offset"`
+	AccountID       int64         `json:"account_id"`
+	CursorCreatedAt sql.NullTime  `json:"cursor_created_at"`
+	CursorID        sql.NullInt64 `json:"cursor_id"`
+	Offset          int32         `json:"offset"`
+	Limit           int32         `json:"limit"`
 }
 
 func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error) {
-	rows, err := q.db.QueryContext(ctx, listEntries, arg.AccountID, arg.Limit, arg.Offset)
+	rows, err := q.db.QueryContext(ctx, listEntries,
+		arg.AccountID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Entry
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.JournalID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listJournalEntries = `-- name: ListJournalEntries :many
+SELECT id, account_id, amount, created_at, journal_id FROM entries
+WHERE journal_id = $1
+ORDER BY id
+`
+
+func (q *Queries) ListJournalEntries(ctx context.Context, journalID sql.NullInt64) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, listJournalEntries, journalID)
 	if err != nil {
 		return nil, err
 	}
@@ -80,6 +345,7 @@ func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Ent
 			&i.AccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.JournalID,
 		); err != nil {
 			return nil, err
 		}
@@ -93,3 +359,24 @@ func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Ent
 	}
 	return items, nil
 }
+
+const sumAccountEntries = `-- name: SumAccountEntries :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS total
+FROM entries
+WHERE account_id = $1
+  AND ($2::timestamptz IS NULL OR created_at >= $2)
+  AND ($3::timestamptz IS NULL OR created_at < $3)
+`
+
+type SumAccountEntriesParams struct {
+	AccountID   int64        `json:"account_id"`
+	CreatedFrom sql.NullTime `json:"created_from"`
+	CreatedTo   sql.NullTime `json:"created_to"`
+}
+
+func (q *Queries) SumAccountEntries(ctx context.Context, arg SumAccountEntriesParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, sumAccountEntries, arg.AccountID, arg.CreatedFrom, arg.CreatedTo)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 	"time"
 
@@ -45,3 +46,22 @@ func TestGetEntry(t *testing.T) {
 	require.Equal(t, entry1.Amount, entry2.Amount)
 	require.WithinDuration(t, entry1.CreatedAt, entry2.CreatedAt, time.Second)
 }
+
+func TestListEntriesByCursor(t *testing.T) {
+	account := createRandomAccount(t)
+	entries := make([]Entry, 5)
+	for i := range entries {
+		entries[i] = createRandomEntry(t, account)
+	}
+
+	page, err := testQueries.ListEntries(context.Background(), ListEntriesParams{
+		AccountID:       account.ID,
+		CursorCreatedAt: sql.NullTime{Time: entries[1].CreatedAt, Valid: true},
+		CursorID:        sql.NullInt64{Int64: entries[1].ID, Valid: true},
+		Limit:           2,
+	})
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	require.Equal(t, entries[2].ID, page[0].ID)
+	require.Equal(t, entries[3].ID, page[1].ID)
+}
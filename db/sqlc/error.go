@@ -0,0 +1,50 @@
+package db
+
+import (
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrIdempotencyKeyExists        = errors.New("idempotency key has already been used")
+	ErrInsufficientFunds           = errors.New("insufficient funds")
+	ErrCurrencyMismatch            = errors.New("currency mismatch")
+	ErrFxRateNotFound              = errors.New("fx rate not found")
+	ErrInvalidAmount               = errors.New("invalid amount")
+	ErrTransferNotReversible       = errors.New("transfer cannot be reversed")
+	ErrReversalExceedsAmount       = errors.New("reversal exceeds the amount left to reverse")
+	ErrScheduledTransferNotPending = errors.New("scheduled transfer is no longer pending")
+	ErrStandingOrderNotActive      = errors.New("standing order is not active")
+	ErrStandingOrderRunExists      = errors.New("standing order occurrence has already been run")
+	ErrUnbalancedJournal           = errors.New("journal does not balance")
+	ErrHoldNotActive               = errors.New("hold is no longer authorized")
+	ErrCaptureExceedsHold          = errors.New("capture exceeds the held amount")
+	ErrInvalidTransferTransition   = errors.New("invalid transfer status transition")
+	ErrVelocityLimitExceeded       = errors.New("transfer limit exceeded")
+	ErrPaymentRequestNotPending    = errors.New("payment request is no longer pending")
+	ErrInterestAlreadyPosted       = errors.New("interest has already been posted for the month")
+	ErrCashMovementNotPending      = errors.New("cash movement is no longer pending")
+	ErrUnknownProduct              = errors.New("unknown product")
+	ErrAccountLimitReached         = errors.New("account limit reached")
+)
+
+// IsRejected reports whether err means a money movement was refused by the rules of the ledger,
+// as opposed to a database failure that may go away when retried
+func IsRejected(err error) bool {
+	switch {
+	case errors.Is(err, ErrInsufficientFunds),
+		errors.Is(err, ErrCurrencyMismatch),
+		errors.Is(err, ErrFxRateNotFound),
+		errors.Is(err, ErrInvalidAmount),
+		errors.Is(err, ErrTransferNotReversible),
+		errors.Is(err, ErrReversalExceedsAmount),
+		errors.Is(err, ErrUnbalancedJournal),
+		errors.Is(err, ErrCaptureExceedsHold),
+		errors.Is(err, ErrVelocityLimitExceeded):
+		return true
+	}
+
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code.Name() == "check_violation"
+}
@@ -5,6 +5,8 @@
 package db
 
 import (
+	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,6 +18,43 @@ type Account struct {
 	Balance   int64     `json:"balance"`
 	Currency  string    `json:"currency"`
 	CreatedAt time.Time `json:"created_at"`
+	// how far below zero the balance may go
+	OverdraftLimit int64 `json:"overdraft_limit"`
+	// sum of the authorized holds on the account, the available balance is balance - held_amount
+	HeldAmount  int64  `json:"held_amount"`
+	ProductCode string `json:"product_code"`
+}
+
+// end of day balances, so past balances can be found without replaying every entry
+type BalanceSnapshot struct {
+	AccountID int64 `json:"account_id"`
+	// the UTC day the balance was taken at the end of
+	SnapshotDate time.Time `json:"snapshot_date"`
+	Balance      int64     `json:"balance"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// money paid into or out of the bank, posted against the cash in and cash out system accounts
+type CashMovement struct {
+	ID        int64         `json:"id"`
+	AccountID int64         `json:"account_id"`
+	Kind      string        `json:"kind"`
+	Amount    int64         `json:"amount"`
+	Reference string        `json:"reference"`
+	JournalID sql.NullInt64 `json:"journal_id"`
+	// the banker who recorded a cash movement, or the owner who asked for a top-up or payout
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	// cash at the counter, or rail for top-ups and payouts through an external payment rail
+	Channel string `json:"channel"`
+	Status  string `json:"status"`
+	// the reference the payment rail gave the payment when it was submitted
+	RailReference string `json:"rail_reference"`
+	ReturnReason  string `json:"return_reason"`
+	// the journal that gave a returned or failed withdrawal back to the account
+	ReturnJournalID sql.NullInt64 `json:"return_journal_id"`
+	// when the movement was settled, returned or failed
+	CompletedAt sql.NullTime `json:"completed_at"`
 }
 
 type Entry struct {
@@ -24,6 +63,159 @@ type Entry struct {
 	// can be negative or positive
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	// the balanced posting the entry belongs to, null for entries made before journals
+	JournalID sql.NullInt64 `json:"journal_id"`
+}
+
+// fees charged to the sender on top of the amount of a transfer, no row means no fee
+type FeeSchedule struct {
+	// internal between accounts of one user, p2p to another user, fx across currencies
+	TransferType string `json:"transfer_type"`
+	// the currency of the from account, which the fee is charged in
+	Currency string `json:"currency"`
+	FlatFee  int64  `json:"flat_fee"`
+	// share of the amount added to the flat fee, in basis points, rounded up to the minor unit
+	PercentageBps int32 `json:"percentage_bps"`
+	MinFee        int64 `json:"min_fee"`
+	// null for no cap
+	MaxFee    sql.NullInt64 `json:"max_fee"`
+	UpdatedAt time.Time     `json:"updated_at"`
+	// the product of the account that pays the fee
+	ProductCode string `json:"product_code"`
+}
+
+type FxRate struct {
+	BaseCurrency  string `json:"base_currency"`
+	QuoteCurrency string `json:"quote_currency"`
+	// units of quote currency for one unit of base currency
+	Rate string `json:"rate"`
+	// margin taken off the converted amount, in basis points
+	SpreadBps int32     `json:"spread_bps"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type Hold struct {
+	ID            int64  `json:"id"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	ToCurrency    string `json:"to_currency"`
+	// authorized, captured, voided or expired
+	Status string `json:"status"`
+	// amount taken by the capture, the rest of the hold is released
+	CapturedAmount int64 `json:"captured_amount"`
+	// the transfer made by the capture
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	ExpiresAt  time.Time     `json:"expires_at"`
+	CreatedAt  time.Time     `json:"created_at"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+}
+
+type IdempotencyKey struct {
+	Username    string          `json:"username"`
+	Key         string          `json:"key"`
+	RequestHash string          `json:"request_hash"`
+	Response    json.RawMessage `json:"response"`
+	CreatedAt   time.Time       `json:"created_at"`
+	ExpiresAt   time.Time       `json:"expires_at"`
+}
+
+// interest earned by an account on one UTC day, paid out monthly
+type InterestAccrual struct {
+	AccountID   int64     `json:"account_id"`
+	AccrualDate time.Time `json:"accrual_date"`
+	// the end of day balance the interest was earned on
+	Balance            int64  `json:"balance"`
+	AnnualInterestRate string `json:"annual_interest_rate"`
+	// in minor units, kept unrounded until the month is posted
+	Amount    string        `json:"amount"`
+	PostingID sql.NullInt64 `json:"posting_id"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+type InterestPosting struct {
+	ID        int64 `json:"id"`
+	AccountID int64 `json:"account_id"`
+	// the first day of the month the interest was accrued in
+	Period time.Time `json:"period"`
+	// the accruals of the month rounded to the minor unit
+	Amount int64 `json:"amount"`
+	// null when the interest rounded to zero and nothing was paid
+	JournalID sql.NullInt64 `json:"journal_id"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+type Journal struct {
+	ID        int64     `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type PaymentRequest struct {
+	ID        int64  `json:"id"`
+	Requester string `json:"requester"`
+	Payer     string `json:"payer"`
+	// the requester's account that receives the money
+	ToAccountID int64  `json:"to_account_id"`
+	Amount      int64  `json:"amount"`
+	Currency    string `json:"currency"`
+	Description string `json:"description"`
+	// pending, accepted, declined or expired
+	Status string `json:"status"`
+	// the transfer made when the payer accepted
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	ExpiresAt  time.Time     `json:"expires_at"`
+	CreatedAt  time.Time     `json:"created_at"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+}
+
+// the kinds of account the bank offers
+type Product struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+	// interest paid per year on positive balances, 0.02 is 2%
+	AnnualInterestRate string    `json:"annual_interest_rate"`
+	CreatedAt          time.Time `json:"created_at"`
+	// the overdraft limit given to new accounts of the product
+	OverdraftLimit int64 `json:"overdraft_limit"`
+	// how many accounts of the product a user may hold in each currency
+	MaxAccountsPerCurrency int32 `json:"max_accounts_per_currency"`
+}
+
+// results of checking the stored balances against the ledger entries
+type ReconciliationRun struct {
+	ID int64 `json:"id"`
+	// balanced or discrepancies
+	Status           string `json:"status"`
+	AccountsChecked  int64  `json:"accounts_checked"`
+	TransfersChecked int64  `json:"transfers_checked"`
+	DiscrepancyCount int64  `json:"discrepancy_count"`
+	// the accounts whose balance drifted from their entries and the transfers whose entries do not net to zero
+	Report     json.RawMessage `json:"report"`
+	StartedAt  time.Time       `json:"started_at"`
+	FinishedAt time.Time       `json:"finished_at"`
+}
+
+type ScheduledTransfer struct {
+	ID            int64     `json:"id"`
+	Owner         string    `json:"owner"`
+	FromAccountID int64     `json:"from_account_id"`
+	ToAccountID   int64     `json:"to_account_id"`
+	Amount        int64     `json:"amount"`
+	ToCurrency    string    `json:"to_currency"`
+	ExecuteAt     time.Time `json:"execute_at"`
+	// scheduled, completed, failed or cancelled
+	Status        string `json:"status"`
+	FailureReason string `json:"failure_reason"`
+	// the transfer made when the schedule completed
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	CreatedAt  time.Time     `json:"created_at"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+	// copied to the transfer when it is made
+	Description string `json:"description"`
+	// copied to the transfer when it is made
+	ClientReference string `json:"client_reference"`
+	// copied to the transfer when it is made
+	Metadata json.RawMessage `json:"metadata"`
 }
 
 type Session struct {
@@ -37,6 +229,44 @@ type Session struct {
 	CreatedAt    time.Time `json:"created_at"`
 }
 
+type StandingOrder struct {
+	ID            int64  `json:"id"`
+	Owner         string `json:"owner"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	ToCurrency    string `json:"to_currency"`
+	// standard 5 field cron spec, evaluated in UTC
+	Schedule string `json:"schedule"`
+	// the next occurrence of the schedule that has not been run yet
+	NextRunAt time.Time `json:"next_run_at"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// how many times running the next occurrence failed with an error that may go away
+	FailedAttempts int32 `json:"failed_attempts"`
+	// when the next occurrence is tried again after such an error, null if it has not failed
+	RetryAt sql.NullTime `json:"retry_at"`
+}
+
+type StandingOrderRun struct {
+	ID              int64 `json:"id"`
+	StandingOrderID int64 `json:"standing_order_id"`
+	// the occurrence of the schedule, each one is run at most once
+	ScheduledFor  time.Time     `json:"scheduled_for"`
+	Status        string        `json:"status"`
+	FailureReason string        `json:"failure_reason"`
+	TransferID    sql.NullInt64 `json:"transfer_id"`
+	CreatedAt     time.Time     `json:"created_at"`
+}
+
+// accounts owned by the bank itself, looked up by what they are for
+type SystemAccount struct {
+	Purpose   string `json:"purpose"`
+	Currency  string `json:"currency"`
+	AccountID int64  `json:"account_id"`
+}
+
 type Transfer struct {
 	ID            int64 `json:"id"`
 	FromAccountID int64 `json:"from_account_id"`
@@ -44,6 +274,35 @@ type Transfer struct {
 	// must be positive
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	// amount credited to the to account, in its own currency
+	ToAmount    int64  `json:"to_amount"`
+	FxRate      string `json:"fx_rate"`
+	FxSpreadBps int32  `json:"fx_spread_bps"`
+	// set on reversals, points at the transfer being reversed
+	ReversedTransferID sql.NullInt64 `json:"reversed_transfer_id"`
+	JournalID          sql.NullInt64 `json:"journal_id"`
+	// free text memo shown to both sides
+	Description string `json:"description"`
+	// reference chosen by the client, e.g. an invoice number
+	ClientReference string `json:"client_reference"`
+	// arbitrary JSON object attached by the client
+	Metadata json.RawMessage `json:"metadata"`
+	// pending, completed, failed or reversed
+	Status string `json:"status"`
+	// why the transfer reached its status, e.g. the failure reason
+	StatusReason string    `json:"status_reason"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// per user overrides of the default velocity limits
+type TransferLimit struct {
+	Username string `json:"username"`
+	Currency string `json:"currency"`
+	// most the user can send per UTC day in this currency, 0 means no limit
+	DailyLimit int64 `json:"daily_limit"`
+	// most the user can send per UTC month in this currency, 0 means no limit
+	MonthlyLimit int64     `json:"monthly_limit"`
+	UpdatedAt    time.Time `json:"updated_at"`
 }
 
 type User struct {
@@ -54,6 +313,8 @@ type User struct {
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreatedAt         time.Time `json:"created_at"`
 	IsEmailVerified   bool      `json:"is_email_verified"`
+	// bankers can move cash in and out of any account, depositors only use their own
+	Role string `json:"role"`
 }
 
 type VerifyEmail struct {
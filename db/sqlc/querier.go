@@ -6,32 +6,130 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type Querier interface {
 	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error)
+	AddAccountHeldAmount(ctx context.Context, arg AddAccountHeldAmountParams) (Account, error)
+	CancelStandingOrder(ctx context.Context, id int64) (StandingOrder, error)
+	CompleteCashMovement(ctx context.Context, arg CompleteCashMovementParams) (CashMovement, error)
+	CountAccountsByProduct(ctx context.Context, arg CountAccountsByProductParams) (int64, error)
+	CountReconciliationScope(ctx context.Context) (CountReconciliationScopeRow, error)
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
+	// takes the end of day balance of every account open by then, worked back from the current balance
+	CreateBalanceSnapshots(ctx context.Context, snapshotDate time.Time) (int64, error)
+	CreateCashMovement(ctx context.Context, arg CreateCashMovementParams) (CashMovement, error)
 	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	CreateHold(ctx context.Context, arg CreateHoldParams) (Hold, error)
+	CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error)
+	// does nothing if the account already accrued interest for the day
+	CreateInterestAccrual(ctx context.Context, arg CreateInterestAccrualParams) (int64, error)
+	// returns no row if the month was already posted for the account
+	CreateInterestPosting(ctx context.Context, arg CreateInterestPostingParams) (InterestPosting, error)
+	CreateJournal(ctx context.Context) (Journal, error)
+	CreatePaymentRequest(ctx context.Context, arg CreatePaymentRequestParams) (PaymentRequest, error)
+	CreateReconciliationRun(ctx context.Context, arg CreateReconciliationRunParams) (ReconciliationRun, error)
+	CreateScheduledTransfer(ctx context.Context, arg CreateScheduledTransferParams) (ScheduledTransfer, error)
 	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
+	CreateStandingOrder(ctx context.Context, arg CreateStandingOrderParams) (StandingOrder, error)
+	CreateStandingOrderRun(ctx context.Context, arg CreateStandingOrderRunParams) (StandingOrderRun, error)
 	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
 	CreateVerifyEmail(ctx context.Context, arg CreateVerifyEmailParams) (VerifyEmail, error)
 	DeleteAccount(ctx context.Context, id int64) error
+	DeleteFeeSchedule(ctx context.Context, arg DeleteFeeScheduleParams) error
+	ExpirePaymentRequests(ctx context.Context, arg ExpirePaymentRequestsParams) ([]PaymentRequest, error)
 	GetAccount(ctx context.Context, id int64) (Account, error)
+	// A user may hold several accounts in a currency. Money sent to the user goes to their oldest checking account,
+	// or to their oldest account of any product if they have no checking account in the currency
+	GetAccountByOwnerCurrency(ctx context.Context, arg GetAccountByOwnerCurrencyParams) (Account, error)
 	GetAccountForUpdate(ctx context.Context, id int64) (Account, error)
+	GetAccountStatementBalances(ctx context.Context, arg GetAccountStatementBalancesParams) (GetAccountStatementBalancesRow, error)
+	// holds still authorized count against the limits like sent transfers, until they are captured or released
+	GetAuthorizedHoldAmounts(ctx context.Context, arg GetAuthorizedHoldAmountsParams) (GetAuthorizedHoldAmountsRow, error)
+	// the first day after the latest snapshots, or the day the first account was opened
+	GetBalanceSnapshotResumeDate(ctx context.Context) (time.Time, error)
+	GetCashMovement(ctx context.Context, id int64) (CashMovement, error)
+	GetCashMovementForUpdate(ctx context.Context, id int64) (CashMovement, error)
 	GetEntry(ctx context.Context, id int64) (Entry, error)
+	GetFeeSchedule(ctx context.Context, arg GetFeeScheduleParams) (FeeSchedule, error)
+	GetFxRate(ctx context.Context, arg GetFxRateParams) (FxRate, error)
+	GetHold(ctx context.Context, id int64) (Hold, error)
+	GetHoldForUpdate(ctx context.Context, id int64) (Hold, error)
+	GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error)
+	// the first day after the latest accruals, or yesterday in UTC when interest has never been accrued
+	GetInterestAccrualResumeDate(ctx context.Context) (time.Time, error)
+	// the latest snapshot of the account taken at or before as_of
+	GetLatestBalanceSnapshot(ctx context.Context, arg GetLatestBalanceSnapshotParams) (BalanceSnapshot, error)
+	GetPaymentRequest(ctx context.Context, id int64) (PaymentRequest, error)
+	GetPaymentRequestForUpdate(ctx context.Context, id int64) (PaymentRequest, error)
+	GetProduct(ctx context.Context, code string) (Product, error)
+	GetReconciliationRun(ctx context.Context, id int64) (ReconciliationRun, error)
+	GetScheduledTransfer(ctx context.Context, id int64) (ScheduledTransfer, error)
+	GetScheduledTransferForUpdate(ctx context.Context, id int64) (ScheduledTransfer, error)
+	GetSentAmounts(ctx context.Context, arg GetSentAmountsParams) (GetSentAmountsRow, error)
 	GetSession(ctx context.Context, id uuid.UUID) (Session, error)
+	GetStandingOrder(ctx context.Context, id int64) (StandingOrder, error)
+	GetStandingOrderForUpdate(ctx context.Context, id int64) (StandingOrder, error)
+	GetSystemAccount(ctx context.Context, arg GetSystemAccountParams) (SystemAccount, error)
 	GetTransfer(ctx context.Context, id int64) (Transfer, error)
+	GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error)
+	GetTransferLimit(ctx context.Context, arg GetTransferLimitParams) (TransferLimit, error)
+	GetTransferReversedAmount(ctx context.Context, reversedTransferID sql.NullInt64) (int64, error)
 	GetUser(ctx context.Context, username string) (User, error)
+	GetUserByEmail(ctx context.Context, email string) (User, error)
+	GetUserForUpdate(ctx context.Context, username string) (User, error)
+	ListAccountStatement(ctx context.Context, arg ListAccountStatementParams) ([]ListAccountStatementRow, error)
+	// the entries of a statement without their balance, for exports that carry the balance forward themselves
+	ListAccountStatementEntries(ctx context.Context, arg ListAccountStatementEntriesParams) ([]ListAccountStatementEntriesRow, error)
 	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
+	ListBalanceDrifts(ctx context.Context) ([]ListBalanceDriftsRow, error)
+	// an occurrence that failed with an error that may go away is only due again at its retry_at
+	ListDueStandingOrders(ctx context.Context, arg ListDueStandingOrdersParams) ([]StandingOrder, error)
 	ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error)
+	ListExpiredHolds(ctx context.Context, arg ListExpiredHoldsParams) ([]Hold, error)
+	ListFeeSchedules(ctx context.Context, arg ListFeeSchedulesParams) ([]FeeSchedule, error)
+	// the positive end of day balances of the accounts open by then whose product pays interest,
+	// worked back from the current balance
+	ListInterestBearingBalances(ctx context.Context, accrualDate time.Time) ([]ListInterestBearingBalancesRow, error)
+	ListJournalEntries(ctx context.Context, journalID sql.NullInt64) ([]Entry, error)
+	// scheduled transfers that should have been executed before the given time, oldest first
+	ListOverdueScheduledTransfers(ctx context.Context, arg ListOverdueScheduledTransfersParams) ([]ScheduledTransfer, error)
+	ListPaymentRequestsByPayer(ctx context.Context, arg ListPaymentRequestsByPayerParams) ([]PaymentRequest, error)
+	ListPaymentRequestsByRequester(ctx context.Context, arg ListPaymentRequestsByRequesterParams) ([]PaymentRequest, error)
+	ListProducts(ctx context.Context) ([]Product, error)
+	ListScheduledTransfers(ctx context.Context, arg ListScheduledTransfersParams) ([]ScheduledTransfer, error)
+	// rail payments still pending that were created before the given time, oldest first
+	ListStaleRailPayments(ctx context.Context, arg ListStaleRailPaymentsParams) ([]CashMovement, error)
+	ListStandingOrderRuns(ctx context.Context, arg ListStandingOrderRunsParams) ([]StandingOrderRun, error)
+	ListStandingOrders(ctx context.Context, arg ListStandingOrdersParams) ([]StandingOrder, error)
 	ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error)
 	ListTransfersByUsername(ctx context.Context, arg ListTransfersByUsernameParams) ([]ListTransfersByUsernameRow, error)
-	UpdateAccount(ctx context.Context, arg UpdateAccountParams) error
+	// cross currency transfers go through the fx position accounts, so the entries net to zero per currency
+	ListUnbalancedTransfers(ctx context.Context) ([]ListUnbalancedTransfersRow, error)
+	// the months before the given day that accrued interest not posted yet, per account
+	ListUnpostedInterestPeriods(ctx context.Context, before time.Time) ([]ListUnpostedInterestPeriodsRow, error)
+	LockTransferLimit(ctx context.Context, arg LockTransferLimitParams) error
+	MarkInterestAccrualsPosted(ctx context.Context, arg MarkInterestAccrualsPostedParams) (int64, error)
+	RetryStandingOrderRun(ctx context.Context, arg RetryStandingOrderRunParams) (StandingOrder, error)
+	SetCashMovementRailReference(ctx context.Context, arg SetCashMovementRailReferenceParams) (CashMovement, error)
+	SetInterestPostingJournal(ctx context.Context, arg SetInterestPostingJournalParams) (InterestPosting, error)
+	SumAccountEntries(ctx context.Context, arg SumAccountEntriesParams) (int64, error)
+	SumUnpostedInterestAccruals(ctx context.Context, arg SumUnpostedInterestAccrualsParams) (string, error)
+	UpdateHold(ctx context.Context, arg UpdateHoldParams) (Hold, error)
+	UpdatePaymentRequest(ctx context.Context, arg UpdatePaymentRequestParams) (PaymentRequest, error)
+	UpdateScheduledTransfer(ctx context.Context, arg UpdateScheduledTransferParams) (ScheduledTransfer, error)
+	UpdateStandingOrderNextRun(ctx context.Context, arg UpdateStandingOrderNextRunParams) (StandingOrder, error)
+	UpdateTransferStatus(ctx context.Context, arg UpdateTransferStatusParams) (Transfer, error)
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
 	UpdateVerifyEmail(ctx context.Context, arg UpdateVerifyEmailParams) (VerifyEmail, error)
+	UpsertFeeSchedule(ctx context.Context, arg UpsertFeeScheduleParams) (FeeSchedule, error)
+	UpsertFxRate(ctx context.Context, arg UpsertFxRateParams) (FxRate, error)
+	UpsertTransferLimit(ctx context.Context, arg UpsertTransferLimitParams) (TransferLimit, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -4,14 +4,41 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 )
 
 // Store defines all functions to execute db queries and transactions
 type Store interface {
 	Querier
+	PostJournalTx(ctx context.Context, arg PostJournalTxParams) (PostJournalTxResult, error)
 	TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error)
+	QuoteTransferTx(ctx context.Context, arg QuoteTransferTxParams) (TransferQuote, error)
+	BatchTransferTx(ctx context.Context, arg BatchTransferTxParams) (BatchTransferTxResult, error)
+	ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParams) (ReverseTransferTxResult, error)
+	AuthorizeHoldTx(ctx context.Context, arg AuthorizeHoldTxParams) (AuthorizeHoldTxResult, error)
+	CaptureHoldTx(ctx context.Context, arg CaptureHoldTxParams) (CaptureHoldTxResult, error)
+	VoidHoldTx(ctx context.Context, holdID int64) (ReleaseHoldTxResult, error)
+	ExpireHoldTx(ctx context.Context, holdID int64) (ReleaseHoldTxResult, error)
+	CreateAccountTx(ctx context.Context, arg CreateAccountTxParams) (CreateAccountTxResult, error)
 	CreateUserTx(ctx context.Context, arg CreateUserTxParams) (CreateUserTxResult, error)
 	VerifyEmailTx(ctx context.Context, arg VerifyEmailTxParams) (VerifyEmailTxResult, error)
+	CreateScheduledTransferTx(ctx context.Context, arg CreateScheduledTransferTxParams) (CreateScheduledTransferTxResult, error)
+	ExecuteScheduledTransferTx(ctx context.Context, arg ExecuteScheduledTransferTxParams) (ExecuteScheduledTransferTxResult, error)
+	CreatePaymentRequestTx(ctx context.Context, arg CreatePaymentRequestTxParams) (PaymentRequest, error)
+	AcceptPaymentRequestTx(ctx context.Context, arg AcceptPaymentRequestTxParams) (AcceptPaymentRequestTxResult, error)
+	DeclinePaymentRequestTx(ctx context.Context, arg DeclinePaymentRequestTxParams) (PaymentRequest, error)
+	RunStandingOrderTx(ctx context.Context, arg RunStandingOrderTxParams) (RunStandingOrderTxResult, error)
+	FailStandingOrderRunTx(ctx context.Context, arg FailStandingOrderRunTxParams) (RunStandingOrderTxResult, error)
+	AccountStatementTx(ctx context.Context, arg AccountStatementTxParams) (AccountStatementTxResult, error)
+	ExportAccountStatementTx(ctx context.Context, arg ExportAccountStatementTxParams) error
+	ReconcileTx(ctx context.Context) (ReconcileTxResult, error)
+	SnapshotBalancesTx(ctx context.Context, arg SnapshotBalancesTxParams) (SnapshotBalancesTxResult, error)
+	BalanceAsOfTx(ctx context.Context, arg BalanceAsOfTxParams) (BalanceAsOfTxResult, error)
+	AccrueInterestTx(ctx context.Context, accrualDate time.Time) (AccrueInterestTxResult, error)
+	PostInterestTx(ctx context.Context, arg PostInterestTxParams) (PostInterestTxResult, error)
+	CashMovementTx(ctx context.Context, arg CashMovementTxParams) (CashMovementTxResult, error)
+	CreateRailPaymentTx(ctx context.Context, arg CreateRailPaymentTxParams) (CashMovementTxResult, error)
+	CompleteRailPaymentTx(ctx context.Context, arg CompleteRailPaymentTxParams) (CashMovementTxResult, error)
 }
 
 // SQLStore provides all functions to execute SQL queries and transactions
@@ -30,7 +57,12 @@ func NewStore(db *sql.DB) Store {
 
 // ExecTx executes a function within a database transaction
 func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) error {
-	tx, err := store.db.BeginTx(ctx, nil)
+	return store.execTxWithOptions(ctx, nil, fn)
+}
+
+// execTxWithOptions executes a function within a database transaction started with the given options
+func (store *SQLStore) execTxWithOptions(ctx context.Context, opts *sql.TxOptions, fn func(*Queries) error) error {
+	tx, err := store.db.BeginTx(ctx, opts)
 	if err != nil {
 		return err
 	}
@@ -2,21 +2,27 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
 	"fmt"
 	"testing"
+	"time"
 
+	"github.com/AutomaticOrca/simplebank/util"
 	"github.com/stretchr/testify/require"
 )
 
 func TestTransferTxDeadlock(t *testing.T) {
 	store := NewStore(testDB)
 
-	account1 := createRandomAccount(t)
-	account2 := createRandomAccount(t)
-	fmt.Println(">> before:", account1.Balance, account2.Balance)
 	n := 10             // the number of concurrent transactions
 	amount := int64(10) // transfer amount
 
+	// both accounts must be able to cover every transfer, whatever order they run in
+	account1 := createFundedAccount(t, int64(n)*amount)
+	account2 := createFundedAccount(t, int64(n)*amount)
+	fmt.Println(">> before:", account1.Balance, account2.Balance)
+
 	// Create channels to receive errors and results from each transaction
 	errs := make(chan error)
 
@@ -60,3 +66,224 @@ func TestTransferTxDeadlock(t *testing.T) {
 	require.Equal(t, account1.Balance, updatedAccount1.Balance)
 	require.Equal(t, account2.Balance, updatedAccount2.Balance)
 }
+
+func TestTransferTxIdempotencyKey(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createFundedAccount(t, 10)
+	account2 := createRandomAccount(t)
+
+	key := CreateIdempotencyKeyParams{
+		Username:    account1.Owner,
+		Key:         util.RandomString(16),
+		RequestHash: util.RandomString(64),
+		ExpiresAt:   time.Now().Add(time.Minute),
+	}
+	arg := TransferTxParams{
+		FromAccountID:  account1.ID,
+		ToAccountID:    account2.ID,
+		Amount:         10,
+		IdempotencyKey: &key,
+	}
+
+	result, err := store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	record, err := store.GetIdempotencyKey(context.Background(), GetIdempotencyKeyParams{
+		Username: key.Username,
+		Key:      key.Key,
+	})
+	require.NoError(t, err)
+	require.Equal(t, key.RequestHash, record.RequestHash)
+
+	var storedResult TransferTxResult
+	require.NoError(t, json.Unmarshal(record.Response, &storedResult))
+	require.Equal(t, result.Transfer.ID, storedResult.Transfer.ID)
+
+	// a second transfer with the same key must be rolled back
+	_, err = store.TransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrIdempotencyKeyExists)
+
+	updatedAccount1, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, result.FromAccount.Balance, updatedAccount1.Balance)
+}
+
+func TestTransferTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.Balance + account1.OverdraftLimit + 1,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// nothing must have been written
+	updatedAccount1, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updatedAccount1.Balance)
+
+	updatedAccount2, err := store.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, account2.Balance, updatedAccount2.Balance)
+}
+
+func createFundedAccount(t *testing.T, minBalance int64) Account {
+	account := createRandomAccount(t)
+
+	account, err := testQueries.AddAccountBalance(context.Background(), AddAccountBalanceParams{
+		ID:     account.ID,
+		Amount: minBalance,
+	})
+	require.NoError(t, err)
+	return account
+}
+
+func TestTransferTxCrossCurrency(t *testing.T) {
+	store := NewStore(testDB)
+
+	fromAccount := createFundedAccount(t, 1000)
+	toAccount := createRandomAccount(t)
+	for toAccount.Currency == fromAccount.Currency {
+		toAccount = createRandomAccount(t)
+	}
+
+	rate, err := testQueries.UpsertFxRate(context.Background(), UpsertFxRateParams{
+		BaseCurrency:  fromAccount.Currency,
+		QuoteCurrency: toAccount.Currency,
+		Rate:          "0.5",
+		SpreadBps:     100,
+	})
+	require.NoError(t, err)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        1000,
+		ToCurrency:    toAccount.Currency,
+	})
+	require.NoError(t, err)
+
+	// 1000 * 0.5, less 1% spread
+	require.Equal(t, int64(1000), result.Transfer.Amount)
+	require.Equal(t, int64(495), result.Transfer.ToAmount)
+	require.Equal(t, rate.SpreadBps, result.Transfer.FxSpreadBps)
+	require.Equal(t, int64(-1000), result.FromEntry.Amount)
+	require.Equal(t, int64(495), result.ToEntry.Amount)
+	require.Equal(t, fromAccount.Balance-1000, result.FromAccount.Balance)
+	require.Equal(t, toAccount.Balance+495, result.ToAccount.Balance)
+
+	// the fx position accounts take the other side of each currency
+	entries, err := testQueries.ListJournalEntries(context.Background(), result.Transfer.JournalID)
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        10,
+		ToCurrency:    fromAccount.Currency,
+	})
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+}
+
+func TestTransferTxDetails(t *testing.T) {
+	store := NewStore(testDB)
+
+	fromAccount := createFundedAccount(t, 100)
+	toAccount := createRandomAccount(t)
+	for toAccount.Currency != fromAccount.Currency {
+		toAccount = createRandomAccount(t)
+	}
+
+	clientReference := "INV-" + util.RandomString(8)
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID:   fromAccount.ID,
+		ToAccountID:     toAccount.ID,
+		Amount:          10,
+		Description:     "rent for May",
+		ClientReference: clientReference,
+		Metadata:        json.RawMessage(`{"category": "housing"}`),
+	})
+	require.NoError(t, err)
+	require.Equal(t, "rent for May", result.Transfer.Description)
+	require.Equal(t, clientReference, result.Transfer.ClientReference)
+	require.JSONEq(t, `{"category": "housing"}`, string(result.Transfer.Metadata))
+
+	// a transfer without metadata stores an empty object
+	plain, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+	require.JSONEq(t, `{}`, string(plain.Transfer.Metadata))
+
+	transfers, err := testQueries.ListTransfersByUsername(context.Background(), ListTransfersByUsernameParams{
+		Owner:           fromAccount.Owner,
+		ClientReference: sql.NullString{String: clientReference, Valid: true},
+		LimitCount:      5,
+		OffsetCount:     0,
+	})
+	require.NoError(t, err)
+	require.Len(t, transfers, 1)
+	require.Equal(t, result.Transfer.ID, transfers[0].ID)
+	require.Equal(t, "rent for May", transfers[0].Description)
+}
+
+func TestListTransfersByUsernameFilters(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createFundedAccount(t, 1000)
+	account2 := createFundedAccount(t, 1000)
+	for account2.Currency != account1.Currency {
+		account2 = createFundedAccount(t, 1000)
+	}
+
+	outgoing, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        50,
+	})
+	require.NoError(t, err)
+	incoming, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account2.ID,
+		ToAccountID:   account1.ID,
+		Amount:        200,
+	})
+	require.NoError(t, err)
+
+	list := func(arg ListTransfersByUsernameParams) []int64 {
+		arg.Owner = account1.Owner
+		arg.LimitCount = 10
+		transfers, err := testQueries.ListTransfersByUsername(context.Background(), arg)
+		require.NoError(t, err)
+
+		ids := make([]int64, len(transfers))
+		for i, transfer := range transfers {
+			ids[i] = transfer.ID
+		}
+		return ids
+	}
+
+	require.Equal(t, []int64{incoming.Transfer.ID, outgoing.Transfer.ID}, list(ListTransfersByUsernameParams{}))
+	require.Equal(t, []int64{outgoing.Transfer.ID}, list(ListTransfersByUsernameParams{
+		Direction: sql.NullString{String: "outgoing", Valid: true},
+	}))
+	require.Equal(t, []int64{incoming.Transfer.ID}, list(ListTransfersByUsernameParams{
+		CounterpartyAccountID: sql.NullInt64{Int64: account2.ID, Valid: true},
+		MinAmount:             sql.NullInt64{Int64: 100, Valid: true},
+	}))
+	require.Empty(t, list(ListTransfersByUsernameParams{
+		CreatedFrom: sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true},
+	}))
+
+	// the other user's account cannot be used to see their transfers
+	require.Empty(t, list(ListTransfersByUsernameParams{
+		AccountID: sql.NullInt64{Int64: account2.ID, Valid: true},
+	}))
+}
@@ -7,6 +7,8 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
 	"time"
 )
 
@@ -14,20 +16,48 @@ const createTransfer = `-- name: CreateTransfer :one
 INSERT INTO transfers (
   from_account_id,
   to_account_id,
-  amount
+  amount,
+  to_amount,
+  fx_rate,
+  fx_spread_bps,
+  reversed_transfer_id,
+  journal_id,
+  description,
+  client_reference,
+  metadata
 ) VALUES (
-  $1, $2, $3
-) RETURNING id, from_account_id, to_account_id, amount, created_at
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+) RETURNING id, from_account_id, to_account_id, amount, created_at, to_amount, fx_rate, fx_spread_bps, reversed_transfer_id, journal_id, description, client_reference, metadata, status, status_reason, updated_at
 `
 
 type CreateTransferParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Amount        int64 `json:"amount"`
+	FromAccountID      int64           `json:"from_account_id"`
+	ToAccountID        int64           `json:"to_account_id"`
+	Amount             int64           `json:"amount"`
+	ToAmount           int64           `json:"to_amount"`
+	FxRate             string          `json:"fx_rate"`
+	FxSpreadBps        int32           `json:"fx_spread_bps"`
+	ReversedTransferID sql.NullInt64   `json:"reversed_transfer_id"`
+	JournalID          sql.NullInt64   `json:"journal_id"`
+	Description        string          `json:"description"`
+	ClientReference    string          `json:"client_reference"`
+	Metadata           json.RawMessage `json:"metadata"`
 }
 
 func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
-	row := q.db.QueryRowContext(ctx, createTransfer, arg.FromAccountID, arg.ToAccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.ToAmount,
+		arg.FxRate,
+		arg.FxSpreadBps,
+		arg.ReversedTransferID,
+		arg.JournalID,
+		arg.Description,
+		arg.ClientReference,
+		arg.Metadata,
+	)
 	var i Transfer
 	err := row.Scan(
 		&i.ID,
@@ -35,12 +65,23 @@ func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams)
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ToAmount,
+		&i.FxRate,
+		&i.FxSpreadBps,
+		&i.ReversedTransferID,
+		&i.JournalID,
+		&i.Description,
+		&i.ClientReference,
+		&i.Metadata,
+		&i.Status,
+		&i.StatusReason,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
 
 const getTransfer = `-- name: GetTransfer :one
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, fx_rate, fx_spread_bps, reversed_transfer_id, journal_id, description, client_reference, metadata, status, status_reason, updated_at FROM transfers
 WHERE id = $1 LIMIT 1
 `
 
@@ -53,12 +94,66 @@ func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ToAmount,
+		&i.FxRate,
+		&i.FxSpreadBps,
+		&i.ReversedTransferID,
+		&i.JournalID,
+		&i.Description,
+		&i.ClientReference,
+		&i.Metadata,
+		&i.Status,
+		&i.StatusReason,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getTransferForUpdate = `-- name: GetTransferForUpdate :one
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, fx_rate, fx_spread_bps, reversed_transfer_id, journal_id, description, client_reference, metadata, status, status_reason, updated_at FROM transfers
+WHERE id = $1 LIMIT 1
+FOR UPDATE
+`
+
+func (q *Queries) GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, getTransferForUpdate, id)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ToAmount,
+		&i.FxRate,
+		&i.FxSpreadBps,
+		&i.ReversedTransferID,
+		&i.JournalID,
+		&i.Description,
+		&i.ClientReference,
+		&i.Metadata,
+		&i.Status,
+		&i.StatusReason,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
 
+const getTransferReversedAmount = `-- name: GetTransferReversedAmount :one
+SELECT COALESCE(SUM(to_amount), 0)::bigint AS reversed_amount
+FROM transfers
+WHERE reversed_transfer_id = $1
+`
+
+func (q *Queries) GetTransferReversedAmount(ctx context.Context, reversedTransferID sql.NullInt64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getTransferReversedAmount, reversedTransferID)
+	var reversed_amount int64
+	err := row.Scan(&reversed_amount)
+	return reversed_amount, err
+}
+
 const listTransfers = `-- name: ListTransfers :many
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, fx_rate, fx_spread_bps, reversed_transfer_id, journal_id, description, client_reference, metadata, status, status_reason, updated_at FROM transfers
 WHERE 
     from_account_id = $1 OR
     to_account_id = $2
@@ -94,6 +189,17 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 			&i.ToAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.ToAmount,
+			&i.FxRate,
+			&i.FxSpreadBps,
+			&i.ReversedTransferID,
+			&i.JournalID,
+			&i.Description,
+			&i.ClientReference,
+			&i.Metadata,
+			&i.Status,
+			&i.StatusReason,
+			&i.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -115,33 +221,100 @@ SELECT
     t.to_account_id,
     t.amount,
     t.created_at,
-    a_from.currency  -- Adding the currency from the 'from' account
+    a_from.currency,  -- Adding the currency from the 'from' account
+    t.to_amount,
+    a_to.currency AS to_currency,
+    t.description,
+    t.client_reference,
+    t.metadata,
+    t.status,
+    t.status_reason,
+    t.updated_at
 FROM transfers t
          JOIN accounts a_from ON t.from_account_id = a_from.id
-         JOIN accounts a_to ON t.to_account_id = a_to.id -- This join is still needed for the WHERE clause
-WHERE a_from.owner = $1 OR a_to.owner = $1
-ORDER BY t.created_at DESC
-LIMIT $2
-    OFFSET $3
+         JOIN accounts a_to ON t.to_account_id = a_to.id
+WHERE (
+        -- transfers out of the caller's accounts
+        (a_from.owner = $1
+            AND ($2::varchar IS NULL OR $2 = 'outgoing')
+            AND ($3::bigint IS NULL OR t.from_account_id = $3)
+            AND ($4::varchar IS NULL OR a_from.currency = $4)
+            AND ($5::bigint IS NULL OR t.to_account_id = $5)
+            AND ($6::bigint IS NULL OR t.amount >= $6)
+            AND ($7::bigint IS NULL OR t.amount <= $7))
+        -- transfers into the caller's accounts, filtered on the received amount
+        OR (a_to.owner = $1
+            AND ($2::varchar IS NULL OR $2 = 'incoming')
+            AND ($3::bigint IS NULL OR t.to_account_id = $3)
+            AND ($4::varchar IS NULL OR a_to.currency = $4)
+            AND ($5::bigint IS NULL OR t.from_account_id = $5)
+            AND ($6::bigint IS NULL OR t.to_amount >= $6)
+            AND ($7::bigint IS NULL OR t.to_amount <= $7))
+    )
+    AND ($8::varchar IS NULL OR t.client_reference = $8)
+    AND ($9::varchar IS NULL OR t.status = $9)
+    AND ($10::timestamptz IS NULL OR t.created_at >= $10)
+    AND ($11::timestamptz IS NULL OR t.created_at < $11)
+    AND ($12::timestamptz IS NULL
+        OR (t.created_at, t.id) < ($12, $13::bigint))
+ORDER BY t.created_at DESC, t.id DESC
+LIMIT $15
+    OFFSET $14
 `
 
 type ListTransfersByUsernameParams struct {
-	Owner  string `json:"owner"`
-	Limit  int32  `json:"limit"`
-	Offset int32  `json:"offset"`
+	Owner                 string         `json:"owner"`
+	Direction             sql.NullString `json:"direction"`
+	AccountID             sql.NullInt64  `json:"account_id"`
+	Currency              sql.NullString `json:"currency"`
+	CounterpartyAccountID sql.NullInt64  `json:"counterparty_account_id"`
+	MinAmount             sql.NullInt64  `json:"min_amount"`
+	MaxAmount             sql.NullInt64  `json:"max_amount"`
+	ClientReference       sql.NullString `json:"client_reference"`
+	Status                sql.NullString `json:"status"`
+	CreatedFrom           sql.NullTime   `json:"created_from"`
+	CreatedTo             sql.NullTime   `json:"created_to"`
+	CursorCreatedAt       sql.NullTime   `json:"cursor_created_at"`
+	CursorID              sql.NullInt64  `json:"cursor_id"`
+	OffsetCount           int32          `json:"offset_count"`
+	LimitCount            int32          `json:"limit_count"`
 }
 
 type ListTransfersByUsernameRow struct {
-	ID            int64     `json:"id"`
-	FromAccountID int64     `json:"from_account_id"`
-	ToAccountID   int64     `json:"to_account_id"`
-	Amount        int64     `json:"amount"`
-	CreatedAt     time.Time `json:"created_at"`
-	Currency      string    `json:"currency"`
+	ID              int64           `json:"id"`
+	FromAccountID   int64           `json:"from_account_id"`
+	ToAccountID     int64           `json:"to_account_id"`
+	Amount          int64           `json:"amount"`
+	CreatedAt       time.Time       `json:"created_at"`
+	Currency        string          `json:"currency"`
+	ToAmount        int64           `json:"to_amount"`
+	ToCurrency      string          `json:"to_currency"`
+	Description     string          `json:"description"`
+	ClientReference string          `json:"client_reference"`
+	Metadata        json.RawMessage `json:"metadata"`
+	Status          string          `json:"status"`
+	StatusReason    string          `json:"status_reason"`
+	UpdatedAt       time.Time       `json:"updated_at"`
 }
 
 func (q *Queries) ListTransfersByUsername(ctx context.Context, arg ListTransfersByUsernameParams) ([]ListTransfersByUsernameRow, error) {
-	rows, err := q.db.QueryContext(ctx, listTransfersByUsername, arg.Owner, arg.Limit, arg.Offset)
+	rows, err := q.db.QueryContext(ctx, listTransfersByUsername,
+		arg.Owner,
+		arg.Direction,
+		arg.AccountID,
+		arg.Currency,
+		arg.CounterpartyAccountID,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.ClientReference,
+		arg.Status,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.OffsetCount,
+		arg.LimitCount,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -156,6 +329,14 @@ func (q *Queries) ListTransfersByUsername(ctx context.Context, arg ListTransfers
 			&i.Amount,
 			&i.CreatedAt,
 			&i.Currency,
+			&i.ToAmount,
+			&i.ToCurrency,
+			&i.Description,
+			&i.ClientReference,
+			&i.Metadata,
+			&i.Status,
+			&i.StatusReason,
+			&i.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -169,3 +350,43 @@ func (q *Queries) ListTransfersByUsername(ctx context.Context, arg ListTransfers
 	}
 	return items, nil
 }
+
+const updateTransferStatus = `-- name: UpdateTransferStatus :one
+UPDATE transfers
+SET
+  status = $2,
+  status_reason = $3,
+  updated_at = now()
+WHERE id = $1
+RETURNING id, from_account_id, to_account_id, amount, created_at, to_amount, fx_rate, fx_spread_bps, reversed_transfer_id, journal_id, description, client_reference, metadata, status, status_reason, updated_at
+`
+
+type UpdateTransferStatusParams struct {
+	ID           int64  `json:"id"`
+	Status       string `json:"status"`
+	StatusReason string `json:"status_reason"`
+}
+
+func (q *Queries) UpdateTransferStatus(ctx context.Context, arg UpdateTransferStatusParams) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, updateTransferStatus, arg.ID, arg.Status, arg.StatusReason)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ToAmount,
+		&i.FxRate,
+		&i.FxSpreadBps,
+		&i.ReversedTransferID,
+		&i.JournalID,
+		&i.Description,
+		&i.ClientReference,
+		&i.Metadata,
+		&i.Status,
+		&i.StatusReason,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
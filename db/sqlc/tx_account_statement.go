@@ -0,0 +1,132 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+// AccountStatementTxParams selects a page of an account's entries between CreatedFrom and CreatedTo.
+// Either bound can be left null for an open ended period
+type AccountStatementTxParams struct {
+	AccountID       int64
+	CreatedFrom     sql.NullTime
+	CreatedTo       sql.NullTime
+	CursorCreatedAt sql.NullTime
+	CursorID        sql.NullInt64
+	Limit           int32
+	Offset          int32
+}
+
+// AccountStatementTxResult holds the balances at the start and end of the period,
+// and the entries of the page with the balance after each of them
+type AccountStatementTxResult struct {
+	OpeningBalance int64
+	ClosingBalance int64
+	Lines          []ListAccountStatementRow
+}
+
+// AccountStatementTx reads the balances and the lines of a statement from one snapshot,
+// so a transfer made in between cannot make them disagree
+func (store *SQLStore) AccountStatementTx(ctx context.Context, arg AccountStatementTxParams) (AccountStatementTxResult, error) {
+	var result AccountStatementTxResult
+
+	opts := &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+	err := store.execTxWithOptions(ctx, opts, func(q *Queries) error {
+		balances, err := q.GetAccountStatementBalances(ctx, GetAccountStatementBalancesParams{
+			CreatedFrom: arg.CreatedFrom,
+			CreatedTo:   arg.CreatedTo,
+			AccountID:   arg.AccountID,
+		})
+		if err != nil {
+			return err
+		}
+		result.OpeningBalance = balances.OpeningBalance
+		result.ClosingBalance = balances.ClosingBalance
+
+		result.Lines, err = q.ListAccountStatement(ctx, ListAccountStatementParams{
+			AccountID:       arg.AccountID,
+			CreatedFrom:     arg.CreatedFrom,
+			CreatedTo:       arg.CreatedTo,
+			CursorCreatedAt: arg.CursorCreatedAt,
+			CursorID:        arg.CursorID,
+			LimitCount:      arg.Limit,
+			OffsetCount:     arg.Offset,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+// ExportAccountStatementTxParams selects all entries of an account between CreatedFrom and CreatedTo
+type ExportAccountStatementTxParams struct {
+	AccountID   int64
+	CreatedFrom sql.NullTime
+	CreatedTo   sql.NullTime
+	// BatchSize is the number of entries read per query
+	BatchSize int32
+	// Begin is called with the balances of the period before any line
+	Begin func(openingBalance, closingBalance int64) error
+	// Line is called for each entry of the period, oldest first
+	Line func(line ListAccountStatementRow) error
+}
+
+// ExportAccountStatementTx reads a whole statement from one snapshot in batches and hands each line
+// to the caller as soon as it is read, so large periods can be streamed without holding them in memory
+func (store *SQLStore) ExportAccountStatementTx(ctx context.Context, arg ExportAccountStatementTxParams) error {
+	opts := &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+	return store.execTxWithOptions(ctx, opts, func(q *Queries) error {
+		balances, err := q.GetAccountStatementBalances(ctx, GetAccountStatementBalancesParams{
+			CreatedFrom: arg.CreatedFrom,
+			CreatedTo:   arg.CreatedTo,
+			AccountID:   arg.AccountID,
+		})
+		if err != nil {
+			return err
+		}
+		err = arg.Begin(balances.OpeningBalance, balances.ClosingBalance)
+		if err != nil {
+			return err
+		}
+
+		// the running balance is carried forward from the opening balance, rather than worked out
+		// again by the database for every batch, so each batch only reads its own entries
+		balance := balances.OpeningBalance
+		page := ListAccountStatementEntriesParams{
+			AccountID:   arg.AccountID,
+			CreatedFrom: arg.CreatedFrom,
+			CreatedTo:   arg.CreatedTo,
+			LimitCount:  arg.BatchSize,
+		}
+		for {
+			entries, err := q.ListAccountStatementEntries(ctx, page)
+			if err != nil {
+				return err
+			}
+			for _, entry := range entries {
+				balance += entry.Amount
+				err := arg.Line(ListAccountStatementRow{
+					ID:            entry.ID,
+					AccountID:     entry.AccountID,
+					Amount:        entry.Amount,
+					CreatedAt:     entry.CreatedAt,
+					Balance:       balance,
+					TransferID:    entry.TransferID,
+					FromAccountID: entry.FromAccountID,
+					ToAccountID:   entry.ToAccountID,
+					Description:   entry.Description,
+				})
+				if err != nil {
+					return err
+				}
+			}
+			if len(entries) < int(arg.BatchSize) {
+				return nil
+			}
+
+			last := entries[len(entries)-1]
+			page.CursorCreatedAt = sql.NullTime{Time: last.CreatedAt, Valid: true}
+			page.CursorID = sql.NullInt64{Int64: last.ID, Valid: true}
+		}
+	})
+}
@@ -0,0 +1,122 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountStatementTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createFundedAccount(t, 1000)
+	account2 := createRandomAccount(t)
+	for account2.Currency != account1.Currency {
+		account2 = createRandomAccount(t)
+	}
+
+	out, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        30,
+		Description:   "lunch",
+	})
+	require.NoError(t, err)
+	in, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account2.ID,
+		ToAccountID:   account1.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	balance := in.ToAccount.Balance
+	statement, err := store.AccountStatementTx(context.Background(), AccountStatementTxParams{
+		AccountID: account1.ID,
+		Limit:     10,
+	})
+	require.NoError(t, err)
+	require.Equal(t, balance-10+30, statement.OpeningBalance)
+	require.Equal(t, balance, statement.ClosingBalance)
+
+	require.Len(t, statement.Lines, 2)
+	require.Equal(t, balance-10, statement.Lines[0].Balance)
+	require.Equal(t, out.Transfer.ID, statement.Lines[0].TransferID.Int64)
+	require.Equal(t, account2.ID, statement.Lines[0].ToAccountID.Int64)
+	require.Equal(t, "lunch", statement.Lines[0].Description.String)
+	require.Equal(t, balance, statement.Lines[1].Balance)
+	require.Equal(t, in.Transfer.ID, statement.Lines[1].TransferID.Int64)
+
+	// a period that ends before the second transfer closes at the balance after the first
+	statement, err = store.AccountStatementTx(context.Background(), AccountStatementTxParams{
+		AccountID: account1.ID,
+		CreatedTo: sql.NullTime{Time: in.Transfer.CreatedAt, Valid: true},
+		Limit:     10,
+	})
+	require.NoError(t, err)
+	require.Equal(t, balance-10, statement.ClosingBalance)
+	require.Len(t, statement.Lines, 1)
+
+	// a period in the future is empty and opens and closes at the current balance
+	statement, err = store.AccountStatementTx(context.Background(), AccountStatementTxParams{
+		AccountID:   account1.ID,
+		CreatedFrom: sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true},
+		Limit:       10,
+	})
+	require.NoError(t, err)
+	require.Equal(t, balance, statement.OpeningBalance)
+	require.Equal(t, balance, statement.ClosingBalance)
+	require.Empty(t, statement.Lines)
+}
+
+func TestExportAccountStatementTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createFundedAccount(t, 1000)
+	account2 := createRandomAccount(t)
+	for account2.Currency != account1.Currency {
+		account2 = createRandomAccount(t)
+	}
+	for i := 0; i < 3; i++ {
+		_, err := store.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        10,
+		})
+		require.NoError(t, err)
+	}
+
+	var opening, closing int64
+	var lines []ListAccountStatementRow
+	err := store.ExportAccountStatementTx(context.Background(), ExportAccountStatementTxParams{
+		AccountID: account1.ID,
+		BatchSize: 2,
+		Begin: func(openingBalance, closingBalance int64) error {
+			require.Empty(t, lines)
+			opening, closing = openingBalance, closingBalance
+			return nil
+		},
+		Line: func(line ListAccountStatementRow) error {
+			lines = append(lines, line)
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, opening-30, closing)
+
+	// the batches join up without gaps or repeats
+	require.Len(t, lines, 3)
+	for i, line := range lines {
+		require.Equal(t, opening-int64(i+1)*10, line.Balance)
+	}
+
+	// the balances carried forward match the ones worked out by the database
+	statement, err := store.AccountStatementTx(context.Background(), AccountStatementTxParams{
+		AccountID: account1.ID,
+		Limit:     10,
+	})
+	require.NoError(t, err)
+	require.Equal(t, statement.Lines, lines)
+}
@@ -0,0 +1,96 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// SnapshotBalancesTxParams selects the UTC days to take end of day balances for, both included
+type SnapshotBalancesTxParams struct {
+	From time.Time
+	To   time.Time
+}
+
+type SnapshotBalancesTxResult struct {
+	Days      int   `json:"days"`
+	Snapshots int64 `json:"snapshots"`
+}
+
+// SnapshotBalancesTx takes the end of day balances of every day in the range, one transaction per day,
+// so a long backfill keeps its progress if it is stopped. Days that already have a snapshot are kept
+func (store *SQLStore) SnapshotBalancesTx(ctx context.Context, arg SnapshotBalancesTxParams) (SnapshotBalancesTxResult, error) {
+	var result SnapshotBalancesTxResult
+
+	for day := utcDay(arg.From); !day.After(utcDay(arg.To)); day = day.AddDate(0, 0, 1) {
+		err := store.execTx(ctx, func(q *Queries) error {
+			count, err := q.CreateBalanceSnapshots(ctx, day)
+			result.Snapshots += count
+			return err
+		})
+		if err != nil {
+			return result, err
+		}
+		result.Days++
+	}
+
+	return result, nil
+}
+
+type BalanceAsOfTxParams struct {
+	AccountID int64
+	AsOf      time.Time
+}
+
+type BalanceAsOfTxResult struct {
+	Balance int64
+	// SnapshotDate is the day of the snapshot the balance was worked out from, if there was one
+	SnapshotDate sql.NullTime
+}
+
+// BalanceAsOfTx finds the balance of an account at a point in time. It starts from the latest
+// snapshot before that time and adds the entries made since. Without a snapshot it works back
+// from the current balance instead
+func (store *SQLStore) BalanceAsOfTx(ctx context.Context, arg BalanceAsOfTxParams) (BalanceAsOfTxResult, error) {
+	var result BalanceAsOfTxResult
+
+	opts := &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+	err := store.execTxWithOptions(ctx, opts, func(q *Queries) error {
+		snapshot, err := q.GetLatestBalanceSnapshot(ctx, GetLatestBalanceSnapshotParams{
+			AccountID: arg.AccountID,
+			AsOf:      arg.AsOf,
+		})
+		if err == nil {
+			since, err := q.SumAccountEntries(ctx, SumAccountEntriesParams{
+				AccountID:   arg.AccountID,
+				CreatedFrom: sql.NullTime{Time: utcDay(snapshot.SnapshotDate).AddDate(0, 0, 1), Valid: true},
+				CreatedTo:   sql.NullTime{Time: arg.AsOf, Valid: true},
+			})
+			result.Balance = snapshot.Balance + since
+			result.SnapshotDate = sql.NullTime{Time: snapshot.SnapshotDate, Valid: true}
+			return err
+		}
+		if err != sql.ErrNoRows {
+			return err
+		}
+
+		account, err := q.GetAccount(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+		after, err := q.SumAccountEntries(ctx, SumAccountEntriesParams{
+			AccountID:   arg.AccountID,
+			CreatedFrom: sql.NullTime{Time: arg.AsOf, Valid: true},
+		})
+		result.Balance = account.Balance - after
+		return err
+	})
+
+	return result, err
+}
+
+// utcDay returns the start of the UTC day of t
+func utcDay(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,110 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBalanceAsOfTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createFundedAccount(t, 1000)
+	account2 := createRandomAccount(t)
+	for account2.Currency != account1.Currency {
+		account2 = createRandomAccount(t)
+	}
+
+	out, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        30,
+	})
+	require.NoError(t, err)
+	balance := out.FromAccount.Balance
+
+	// without a snapshot the balance is worked back from the current balance
+	result, err := store.BalanceAsOfTx(context.Background(), BalanceAsOfTxParams{
+		AccountID: account1.ID,
+		AsOf:      out.Transfer.CreatedAt.Add(-time.Microsecond),
+	})
+	require.NoError(t, err)
+	require.Equal(t, balance+30, result.Balance)
+	require.False(t, result.SnapshotDate.Valid)
+
+	result, err = store.BalanceAsOfTx(context.Background(), BalanceAsOfTxParams{
+		AccountID: account1.ID,
+		AsOf:      time.Now(),
+	})
+	require.NoError(t, err)
+	require.Equal(t, balance, result.Balance)
+
+	// with a snapshot the entries made since it are added to it
+	snapshotDate := utcDay(time.Now()).AddDate(0, 0, -2)
+	_, err = testDB.Exec(
+		"INSERT INTO balance_snapshots (account_id, snapshot_date, balance) VALUES ($1, $2, $3)",
+		account1.ID, snapshotDate, 500,
+	)
+	require.NoError(t, err)
+
+	result, err = store.BalanceAsOfTx(context.Background(), BalanceAsOfTxParams{
+		AccountID: account1.ID,
+		AsOf:      time.Now(),
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(500-30), result.Balance)
+	require.True(t, result.SnapshotDate.Valid)
+	require.True(t, snapshotDate.Equal(result.SnapshotDate.Time))
+}
+
+func TestSnapshotBalancesTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createFundedAccount(t, 1000)
+	today := utcDay(time.Now())
+
+	result, err := store.SnapshotBalancesTx(context.Background(), SnapshotBalancesTxParams{
+		From: today,
+		To:   today,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Days)
+	require.Positive(t, result.Snapshots)
+
+	var balance int64
+	err = testDB.QueryRow(
+		"SELECT balance FROM balance_snapshots WHERE account_id = $1 AND snapshot_date = $2",
+		account.ID, today,
+	).Scan(&balance)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance, balance)
+
+	// days that already have a snapshot are kept
+	_, err = store.SnapshotBalancesTx(context.Background(), SnapshotBalancesTxParams{
+		From: today,
+		To:   today,
+	})
+	require.NoError(t, err)
+
+	// an account opened after the day has no snapshot for it
+	yesterday := today.AddDate(0, 0, -1)
+	_, err = store.SnapshotBalancesTx(context.Background(), SnapshotBalancesTxParams{
+		From: yesterday,
+		To:   yesterday,
+	})
+	require.NoError(t, err)
+
+	var count int
+	err = testDB.QueryRow(
+		"SELECT COUNT(*) FROM balance_snapshots WHERE account_id = $1 AND snapshot_date = $2",
+		account.ID, yesterday,
+	).Scan(&count)
+	require.NoError(t, err)
+	require.Zero(t, count)
+
+	resumeDate, err := testQueries.GetBalanceSnapshotResumeDate(context.Background())
+	require.NoError(t, err)
+	require.False(t, resumeDate.Before(today.AddDate(0, 0, 1)))
+}
@@ -0,0 +1,118 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// BatchTransferTxParams contains the legs of a batch transfer, which are made all together or not at all
+type BatchTransferTxParams struct {
+	Legs []TransferTxParams `json:"legs"`
+	// Limits are the default velocity limits of the senders, checked for every leg so that the legs before it count.
+	// They replace the limits of the legs; nil leaves those as they are
+	Limits *TransferLimits `json:"-"`
+	// IdempotencyKey is optional. When set, the result is stored under this key within the same transaction
+	IdempotencyKey *CreateIdempotencyKeyParams `json:"-"`
+}
+
+// BatchTransferTxResult holds the result of every leg, in the order of the params
+type BatchTransferTxResult struct {
+	Legs []TransferTxResult `json:"legs"`
+}
+
+// BatchLegError names the leg that made a batch transfer fail.
+// It unwraps to the error of the leg, so errors.Is works as for a single transfer
+type BatchLegError struct {
+	Leg int
+	Err error
+}
+
+func (e *BatchLegError) Error() string {
+	return fmt.Sprintf("leg %d: %v", e.Leg, e.Err)
+}
+
+func (e *BatchLegError) Unwrap() error {
+	return e.Err
+}
+
+// BatchTransferTx makes several transfers within one database transaction.
+// All accounts of the batch are locked up front in ascending ID order, so batches cannot deadlock with each other
+// or with single transfers. Legs are applied in order, so a leg sees the balances left by the legs before it.
+// If a leg fails, nothing is written and the error is a *BatchLegError
+func (store *SQLStore) BatchTransferTx(ctx context.Context, arg BatchTransferTxParams) (BatchTransferTxResult, error) {
+	var result BatchTransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		result.Legs = make([]TransferTxResult, 0, len(arg.Legs))
+
+		accountIDs := make([]int64, 0, 2*len(arg.Legs))
+		for _, leg := range arg.Legs {
+			accountIDs = append(accountIDs, leg.FromAccountID, leg.ToAccountID)
+		}
+
+		accounts, err := lockAccounts(ctx, q, accountIDs)
+		if err != nil {
+			return err
+		}
+
+		for i, leg := range arg.Legs {
+			fromAccount, ok := accounts[leg.FromAccountID]
+			if !ok {
+				return &BatchLegError{Leg: i, Err: fmt.Errorf("account [%d]: %w", leg.FromAccountID, sql.ErrNoRows)}
+			}
+			toAccount, ok := accounts[leg.ToAccountID]
+			if !ok {
+				return &BatchLegError{Leg: i, Err: fmt.Errorf("account [%d]: %w", leg.ToAccountID, sql.ErrNoRows)}
+			}
+
+			if arg.Limits != nil {
+				leg.Limits = arg.Limits
+			}
+			legResult, err := transferLocked(ctx, q, fromAccount, toAccount, leg)
+			if err != nil {
+				return &BatchLegError{Leg: i, Err: err}
+			}
+
+			accounts[legResult.FromAccount.ID] = legResult.FromAccount
+			accounts[legResult.ToAccount.ID] = legResult.ToAccount
+			result.Legs = append(result.Legs, legResult)
+		}
+
+		if arg.IdempotencyKey != nil {
+			return saveIdempotencyKey(ctx, q, *arg.IdempotencyKey, result)
+		}
+		return nil
+	})
+
+	return result, err
+}
+
+// lockAccounts locks every account in accountIDs once, in ascending ID order to avoid deadlock.
+// Accounts that don't exist are left out of the returned map
+func lockAccounts(ctx context.Context, q *Queries, accountIDs []int64) (map[int64]Account, error) {
+	ids := make([]int64, 0, len(accountIDs))
+	seen := make(map[int64]bool, len(accountIDs))
+	for _, id := range accountIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	accounts := make(map[int64]Account, len(ids))
+	for _, id := range ids {
+		account, err := q.GetAccountForUpdate(ctx, id)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			return nil, err
+		}
+		accounts[id] = account
+	}
+	return accounts, nil
+}
@@ -0,0 +1,77 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	from := createFundedAccount(t, 100)
+	to := make([]Account, 3)
+	for i := range to {
+		to[i] = createRandomAccount(t)
+		for to[i].Currency != from.Currency {
+			to[i] = createRandomAccount(t)
+		}
+	}
+
+	arg := BatchTransferTxParams{}
+	for i, account := range to {
+		arg.Legs = append(arg.Legs, TransferTxParams{
+			FromAccountID: from.ID,
+			ToAccountID:   account.ID,
+			Amount:        int64(10 * (i + 1)),
+		})
+	}
+
+	result, err := store.BatchTransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, result.Legs, 3)
+	for i, leg := range result.Legs {
+		require.Equal(t, to[i].ID, leg.Transfer.ToAccountID)
+		require.Equal(t, to[i].Balance+arg.Legs[i].Amount, leg.ToAccount.Balance)
+	}
+	// each leg sees the balance left by the legs before it
+	require.Equal(t, from.Balance-60, result.Legs[2].FromAccount.Balance)
+}
+
+func TestBatchTransferTxAllOrNothing(t *testing.T) {
+	store := NewStore(testDB)
+
+	from := createFundedAccount(t, 100)
+	to := createRandomAccount(t)
+	for to.Currency != from.Currency {
+		to = createRandomAccount(t)
+	}
+
+	// the second leg only fails because of the first one
+	_, err := store.BatchTransferTx(context.Background(), BatchTransferTxParams{
+		Legs: []TransferTxParams{
+			{FromAccountID: from.ID, ToAccountID: to.ID, Amount: from.Balance},
+			{FromAccountID: from.ID, ToAccountID: to.ID, Amount: 1},
+		},
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	var legErr *BatchLegError
+	require.ErrorAs(t, err, &legErr)
+	require.Equal(t, 1, legErr.Leg)
+
+	// the first leg was rolled back too
+	account, err := store.GetAccount(context.Background(), from.ID)
+	require.NoError(t, err)
+	require.Equal(t, from.Balance, account.Balance)
+
+	_, err = store.BatchTransferTx(context.Background(), BatchTransferTxParams{
+		Legs: []TransferTxParams{
+			{FromAccountID: from.ID, ToAccountID: to.ID, Amount: 1},
+			{FromAccountID: from.ID, ToAccountID: -1, Amount: 1},
+		},
+	})
+	require.ErrorAs(t, err, &legErr)
+	require.Equal(t, 1, legErr.Leg)
+}
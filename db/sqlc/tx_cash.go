@@ -0,0 +1,249 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+const (
+	CashMovementDeposit    = "deposit"
+	CashMovementWithdrawal = "withdrawal"
+)
+
+const (
+	CashChannelCash = "cash"
+	CashChannelRail = "rail"
+)
+
+const (
+	CashMovementStatusPending  = "pending"
+	CashMovementStatusSettled  = "settled"
+	CashMovementStatusReturned = "returned"
+	// CashMovementStatusFailed is a payment cancelled at the rail before it completed, see CompleteRailPaymentTx
+	CashMovementStatusFailed = "failed"
+)
+
+// CashMovementTxParams contains the input parameters of the cash movement transaction
+type CashMovementTxParams struct {
+	AccountID int64  `json:"account_id"`
+	Kind      string `json:"kind"`
+	Amount    int64  `json:"amount"`
+	// Currency must be the currency of the account
+	Currency  string `json:"currency"`
+	Reference string `json:"reference"`
+	// CreatedBy is the username of the banker recording the movement
+	CreatedBy string `json:"created_by"`
+}
+
+// CashMovementTxResult is the result of the cash movement transactions
+type CashMovementTxResult struct {
+	CashMovement CashMovement `json:"cash_movement"`
+	Account      Account      `json:"account"`
+	// Entry is the entry on the account, empty when nothing was posted
+	Entry Entry `json:"entry"`
+}
+
+// CashMovementTx pays cash into or out of an account. A deposit is posted as a journal from the cash in
+// system account of the account's currency, a withdrawal as a journal to the cash out system account.
+// It fails with ErrCurrencyMismatch if the currency is not the account's,
+// and with ErrInsufficientFunds if a withdrawal would take the account past its overdraft limit
+func (store *SQLStore) CashMovementTx(ctx context.Context, arg CashMovementTxParams) (CashMovementTxResult, error) {
+	var result CashMovementTxResult
+
+	if arg.Amount <= 0 {
+		return result, fmt.Errorf("%w: %d", ErrInvalidAmount, arg.Amount)
+	}
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		account, err := cashMovementAccount(ctx, q, arg.AccountID, arg.Currency)
+		if err != nil {
+			return err
+		}
+
+		purpose, amount := SystemAccountPurposeCashIn, arg.Amount
+		if arg.Kind == CashMovementWithdrawal {
+			purpose, amount = SystemAccountPurposeCashOut, -arg.Amount
+		}
+		journal, err := postCash(ctx, q, account, purpose, amount)
+		if err != nil {
+			return err
+		}
+		result.Entry = journal.Entries[1]
+		result.Account = journal.account(account.ID)
+
+		result.CashMovement, err = q.CreateCashMovement(ctx, CreateCashMovementParams{
+			AccountID:   account.ID,
+			Kind:        arg.Kind,
+			Amount:      arg.Amount,
+			Reference:   arg.Reference,
+			JournalID:   sql.NullInt64{Int64: journal.Journal.ID, Valid: true},
+			CreatedBy:   arg.CreatedBy,
+			Channel:     CashChannelCash,
+			Status:      CashMovementStatusSettled,
+			CompletedAt: sql.NullTime{Time: time.Now(), Valid: true},
+		})
+		return err
+	})
+
+	return result, err
+}
+
+// CreateRailPaymentTxParams contains the input parameters of the create rail payment transaction
+type CreateRailPaymentTxParams struct {
+	AccountID int64  `json:"account_id"`
+	Kind      string `json:"kind"`
+	Amount    int64  `json:"amount"`
+	// Currency must be the currency of the account
+	Currency  string `json:"currency"`
+	Reference string `json:"reference"`
+	// CreatedBy is the username of the account owner asking for the top-up or payout
+	CreatedBy string `json:"created_by"`
+	// AfterCreate runs inside the transaction, so the payment is rolled back if it cannot be submitted to the rail
+	AfterCreate func(cashMovement CashMovement) error `json:"-"`
+}
+
+// CreateRailPaymentTx records a pending top-up (deposit) or payout (withdrawal) through a payment rail.
+// A payout is posted to the cash out system account straight away, so the money cannot be spent twice
+// while the rail processes it. A top-up is only posted once it settles, see CompleteRailPaymentTx.
+// It fails like CashMovementTx
+func (store *SQLStore) CreateRailPaymentTx(ctx context.Context, arg CreateRailPaymentTxParams) (CashMovementTxResult, error) {
+	var result CashMovementTxResult
+
+	if arg.Amount <= 0 {
+		return result, fmt.Errorf("%w: %d", ErrInvalidAmount, arg.Amount)
+	}
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		account, err := cashMovementAccount(ctx, q, arg.AccountID, arg.Currency)
+		if err != nil {
+			return err
+		}
+		result.Account = account
+
+		var journalID sql.NullInt64
+		if arg.Kind == CashMovementWithdrawal {
+			journal, err := postCash(ctx, q, account, SystemAccountPurposeCashOut, -arg.Amount)
+			if err != nil {
+				return err
+			}
+			result.Entry = journal.Entries[1]
+			result.Account = journal.account(account.ID)
+			journalID = sql.NullInt64{Int64: journal.Journal.ID, Valid: true}
+		}
+
+		result.CashMovement, err = q.CreateCashMovement(ctx, CreateCashMovementParams{
+			AccountID: account.ID,
+			Kind:      arg.Kind,
+			Amount:    arg.Amount,
+			Reference: arg.Reference,
+			JournalID: journalID,
+			CreatedBy: arg.CreatedBy,
+			Channel:   CashChannelRail,
+			Status:    CashMovementStatusPending,
+		})
+		if err != nil {
+			return err
+		}
+
+		return arg.AfterCreate(result.CashMovement)
+	})
+
+	return result, err
+}
+
+// CompleteRailPaymentTxParams contains the input parameters of the complete rail payment transaction
+type CompleteRailPaymentTxParams struct {
+	CashMovementID int64 `json:"cash_movement_id"`
+	// Status is settled, returned or failed
+	Status       string `json:"status"`
+	ReturnReason string `json:"return_reason"`
+}
+
+// CompleteRailPaymentTx records the outcome the rail reported for a pending payment, or that the payment
+// failed because it was cancelled at the rail. A settled top-up is posted from the cash in system account,
+// and a returned or failed payout is given back to the account from the cash out system account.
+// Nothing is posted in the other cases.
+// It fails with ErrCashMovementNotPending if the outcome was already recorded
+func (store *SQLStore) CompleteRailPaymentTx(ctx context.Context, arg CompleteRailPaymentTxParams) (CashMovementTxResult, error) {
+	var result CashMovementTxResult
+
+	if arg.Status != CashMovementStatusSettled && arg.Status != CashMovementStatusReturned && arg.Status != CashMovementStatusFailed {
+		return result, fmt.Errorf("cannot complete a rail payment as %q", arg.Status)
+	}
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		cashMovement, err := q.GetCashMovementForUpdate(ctx, arg.CashMovementID)
+		if err != nil {
+			return err
+		}
+		if cashMovement.Channel != CashChannelRail || cashMovement.Status != CashMovementStatusPending {
+			return ErrCashMovementNotPending
+		}
+
+		account, err := q.GetAccount(ctx, cashMovement.AccountID)
+		if err != nil {
+			return err
+		}
+		result.Account = account
+
+		update := CompleteCashMovementParams{
+			ID:     cashMovement.ID,
+			Status: arg.Status,
+		}
+		switch {
+		case cashMovement.Kind == CashMovementDeposit && arg.Status == CashMovementStatusSettled:
+			journal, err := postCash(ctx, q, account, SystemAccountPurposeCashIn, cashMovement.Amount)
+			if err != nil {
+				return err
+			}
+			result.Entry = journal.Entries[1]
+			result.Account = journal.account(account.ID)
+			update.JournalID = sql.NullInt64{Int64: journal.Journal.ID, Valid: true}
+		case cashMovement.Kind == CashMovementWithdrawal && arg.Status != CashMovementStatusSettled:
+			journal, err := postCash(ctx, q, account, SystemAccountPurposeCashOut, cashMovement.Amount)
+			if err != nil {
+				return err
+			}
+			result.Entry = journal.Entries[1]
+			result.Account = journal.account(account.ID)
+			update.ReturnJournalID = sql.NullInt64{Int64: journal.Journal.ID, Valid: true}
+		}
+		if arg.Status != CashMovementStatusSettled {
+			update.ReturnReason = arg.ReturnReason
+		}
+
+		result.CashMovement, err = q.CompleteCashMovement(ctx, update)
+		return err
+	})
+
+	return result, err
+}
+
+// cashMovementAccount returns the account of a cash movement, after checking that it is in the movement's currency.
+// The currency never changes, so the account is only locked later by postJournal, in ID order with the system account
+func cashMovementAccount(ctx context.Context, q *Queries, accountID int64, currency string) (Account, error) {
+	account, err := q.GetAccount(ctx, accountID)
+	if err != nil {
+		return account, err
+	}
+	if account.Currency != currency {
+		return account, fmt.Errorf("%w: account [%d] is in %s, not %s", ErrCurrencyMismatch, account.ID, account.Currency, currency)
+	}
+	return account, nil
+}
+
+// postCash posts a journal between the account and the system account for purpose in its currency.
+// A positive amount is paid into the account, a negative amount out of it. The account's entry is the second one
+func postCash(ctx context.Context, q *Queries, account Account, purpose string, amount int64) (PostJournalTxResult, error) {
+	cashAccountID, err := systemAccountID(ctx, q, purpose, account.Currency)
+	if err != nil {
+		return PostJournalTxResult{}, err
+	}
+
+	return postJournal(ctx, q, []JournalLeg{
+		{AccountID: cashAccountID, Amount: -amount},
+		{AccountID: account.ID, Amount: amount},
+	})
+}
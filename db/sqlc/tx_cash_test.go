@@ -0,0 +1,202 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/AutomaticOrca/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCashMovementTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	banker := createRandomUser(t)
+	account := createRandomAccount(t)
+
+	cashIn, err := testQueries.GetSystemAccount(context.Background(), GetSystemAccountParams{
+		Purpose:  SystemAccountPurposeCashIn,
+		Currency: account.Currency,
+	})
+	require.NoError(t, err)
+	cashInBefore, err := testQueries.GetAccount(context.Background(), cashIn.AccountID)
+	require.NoError(t, err)
+
+	deposit, err := store.CashMovementTx(context.Background(), CashMovementTxParams{
+		AccountID: account.ID,
+		Kind:      CashMovementDeposit,
+		Amount:    500,
+		Currency:  account.Currency,
+		Reference: "teller 12",
+		CreatedBy: banker.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, account.Balance+500, deposit.Account.Balance)
+	require.Equal(t, int64(500), deposit.Entry.Amount)
+	require.Equal(t, CashMovementDeposit, deposit.CashMovement.Kind)
+	require.Equal(t, banker.Username, deposit.CashMovement.CreatedBy)
+	require.Equal(t, deposit.Entry.JournalID, deposit.CashMovement.JournalID)
+	require.Equal(t, CashMovementStatusSettled, deposit.CashMovement.Status)
+
+	cashInAfter, err := testQueries.GetAccount(context.Background(), cashIn.AccountID)
+	require.NoError(t, err)
+	require.Equal(t, cashInBefore.Balance-500, cashInAfter.Balance)
+
+	withdrawal, err := store.CashMovementTx(context.Background(), CashMovementTxParams{
+		AccountID: account.ID,
+		Kind:      CashMovementWithdrawal,
+		Amount:    200,
+		Currency:  account.Currency,
+		CreatedBy: banker.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, account.Balance+300, withdrawal.Account.Balance)
+	require.Equal(t, int64(-200), withdrawal.Entry.Amount)
+
+	_, err = store.CashMovementTx(context.Background(), CashMovementTxParams{
+		AccountID: account.ID,
+		Kind:      CashMovementWithdrawal,
+		Amount:    account.Balance + account.OverdraftLimit + 301,
+		Currency:  account.Currency,
+		CreatedBy: banker.Username,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	otherCurrency := util.USD
+	if account.Currency == otherCurrency {
+		otherCurrency = util.EUR
+	}
+	_, err = store.CashMovementTx(context.Background(), CashMovementTxParams{
+		AccountID: account.ID,
+		Kind:      CashMovementDeposit,
+		Amount:    100,
+		Currency:  otherCurrency,
+		CreatedBy: banker.Username,
+	})
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+}
+
+func TestRailPaymentTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createFundedAccount(t, 1000)
+	afterCreate := func(cashMovement CashMovement) error { return nil }
+
+	topUp, err := store.CreateRailPaymentTx(context.Background(), CreateRailPaymentTxParams{
+		AccountID:   account.ID,
+		Kind:        CashMovementDeposit,
+		Amount:      500,
+		Currency:    account.Currency,
+		CreatedBy:   account.Owner,
+		AfterCreate: afterCreate,
+	})
+	require.NoError(t, err)
+	require.Equal(t, CashChannelRail, topUp.CashMovement.Channel)
+	require.Equal(t, CashMovementStatusPending, topUp.CashMovement.Status)
+	require.False(t, topUp.CashMovement.JournalID.Valid)
+	require.Equal(t, account.Balance, topUp.Account.Balance)
+
+	settled, err := store.CompleteRailPaymentTx(context.Background(), CompleteRailPaymentTxParams{
+		CashMovementID: topUp.CashMovement.ID,
+		Status:         CashMovementStatusSettled,
+	})
+	require.NoError(t, err)
+	require.Equal(t, CashMovementStatusSettled, settled.CashMovement.Status)
+	require.True(t, settled.CashMovement.JournalID.Valid)
+	require.True(t, settled.CashMovement.CompletedAt.Valid)
+	require.Equal(t, account.Balance+500, settled.Account.Balance)
+
+	_, err = store.CompleteRailPaymentTx(context.Background(), CompleteRailPaymentTxParams{
+		CashMovementID: topUp.CashMovement.ID,
+		Status:         CashMovementStatusReturned,
+	})
+	require.ErrorIs(t, err, ErrCashMovementNotPending)
+
+	payout, err := store.CreateRailPaymentTx(context.Background(), CreateRailPaymentTxParams{
+		AccountID:   account.ID,
+		Kind:        CashMovementWithdrawal,
+		Amount:      300,
+		Currency:    account.Currency,
+		CreatedBy:   account.Owner,
+		AfterCreate: afterCreate,
+	})
+	require.NoError(t, err)
+	require.Equal(t, CashMovementStatusPending, payout.CashMovement.Status)
+	require.True(t, payout.CashMovement.JournalID.Valid)
+	require.Equal(t, account.Balance+200, payout.Account.Balance)
+
+	returned, err := store.CompleteRailPaymentTx(context.Background(), CompleteRailPaymentTxParams{
+		CashMovementID: payout.CashMovement.ID,
+		Status:         CashMovementStatusReturned,
+		ReturnReason:   "R02 account closed",
+	})
+	require.NoError(t, err)
+	require.Equal(t, CashMovementStatusReturned, returned.CashMovement.Status)
+	require.Equal(t, "R02 account closed", returned.CashMovement.ReturnReason)
+	require.True(t, returned.CashMovement.ReturnJournalID.Valid)
+	require.Equal(t, account.Balance+500, returned.Account.Balance)
+	require.Equal(t, int64(300), returned.Entry.Amount)
+}
+
+func TestFailRailPaymentTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createFundedAccount(t, 1000)
+	afterCreate := func(cashMovement CashMovement) error { return nil }
+
+	topUp, err := store.CreateRailPaymentTx(context.Background(), CreateRailPaymentTxParams{
+		AccountID:   account.ID,
+		Kind:        CashMovementDeposit,
+		Amount:      500,
+		Currency:    account.Currency,
+		CreatedBy:   account.Owner,
+		AfterCreate: afterCreate,
+	})
+	require.NoError(t, err)
+
+	failedTopUp, err := store.CompleteRailPaymentTx(context.Background(), CompleteRailPaymentTxParams{
+		CashMovementID: topUp.CashMovement.ID,
+		Status:         CashMovementStatusFailed,
+		ReturnReason:   "timed out",
+	})
+	require.NoError(t, err)
+	require.Equal(t, CashMovementStatusFailed, failedTopUp.CashMovement.Status)
+	require.False(t, failedTopUp.CashMovement.JournalID.Valid)
+	require.Equal(t, account.Balance, failedTopUp.Account.Balance)
+
+	payout, err := store.CreateRailPaymentTx(context.Background(), CreateRailPaymentTxParams{
+		AccountID:   account.ID,
+		Kind:        CashMovementWithdrawal,
+		Amount:      300,
+		Currency:    account.Currency,
+		CreatedBy:   account.Owner,
+		AfterCreate: afterCreate,
+	})
+	require.NoError(t, err)
+	require.Equal(t, account.Balance-300, payout.Account.Balance)
+
+	stale, err := store.ListStaleRailPayments(context.Background(), ListStaleRailPaymentsParams{
+		CreatedBefore: time.Now().Add(time.Minute),
+		MaxCount:      1000,
+	})
+	require.NoError(t, err)
+	require.Contains(t, stale, payout.CashMovement)
+
+	failedPayout, err := store.CompleteRailPaymentTx(context.Background(), CompleteRailPaymentTxParams{
+		CashMovementID: payout.CashMovement.ID,
+		Status:         CashMovementStatusFailed,
+		ReturnReason:   "timed out",
+	})
+	require.NoError(t, err)
+	require.Equal(t, CashMovementStatusFailed, failedPayout.CashMovement.Status)
+	require.Equal(t, "timed out", failedPayout.CashMovement.ReturnReason)
+	require.True(t, failedPayout.CashMovement.ReturnJournalID.Valid)
+	require.Equal(t, account.Balance, failedPayout.Account.Balance)
+
+	_, err = store.CompleteRailPaymentTx(context.Background(), CompleteRailPaymentTxParams{
+		CashMovementID: payout.CashMovement.ID,
+		Status:         CashMovementStatusSettled,
+	})
+	require.ErrorIs(t, err, ErrCashMovementNotPending)
+}
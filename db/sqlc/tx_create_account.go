@@ -0,0 +1,87 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// Products accounts can be opened as, see the products table for their rules.
+// Accounts are checking accounts unless opened as another product
+const (
+	ProductChecking = "checking"
+	ProductSavings  = "savings"
+	ProductBusiness = "business"
+)
+
+// CreateAccountTxParams contains the input parameters of the create account transaction
+type CreateAccountTxParams struct {
+	Owner       string `json:"owner"`
+	Currency    string `json:"currency"`
+	ProductCode string `json:"product_code"`
+}
+
+// CreateAccountTxResult is the result of the create account transaction
+type CreateAccountTxResult struct {
+	Account Account `json:"account"`
+	Product Product `json:"product"`
+	// Fees are the fee schedules of the product in the account's currency, by transfer type
+	Fees []FeeSchedule `json:"fees"`
+}
+
+// CreateAccountTx opens an account of a product, with the product's overdraft limit.
+// It fails with ErrUnknownProduct if there is no such product,
+// and with ErrAccountLimitReached if the owner already holds as many accounts of the product in the currency as it allows
+func (store *SQLStore) CreateAccountTx(ctx context.Context, arg CreateAccountTxParams) (CreateAccountTxResult, error) {
+	var result CreateAccountTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.Product, err = q.GetProduct(ctx, arg.ProductCode)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("%w: %s", ErrUnknownProduct, arg.ProductCode)
+			}
+			return err
+		}
+
+		// the owner is locked so that accounts opened at the same time are counted one after the other
+		if _, err := q.GetUserForUpdate(ctx, arg.Owner); err != nil {
+			return err
+		}
+
+		count, err := q.CountAccountsByProduct(ctx, CountAccountsByProductParams{
+			Owner:       arg.Owner,
+			Currency:    arg.Currency,
+			ProductCode: arg.ProductCode,
+		})
+		if err != nil {
+			return err
+		}
+		if count >= int64(result.Product.MaxAccountsPerCurrency) {
+			return fmt.Errorf("%w: a user may hold %d %s accounts in %s",
+				ErrAccountLimitReached, result.Product.MaxAccountsPerCurrency, result.Product.Code, arg.Currency)
+		}
+
+		result.Account, err = q.CreateAccount(ctx, CreateAccountParams{
+			Owner:          arg.Owner,
+			Balance:        0,
+			Currency:       arg.Currency,
+			ProductCode:    result.Product.Code,
+			OverdraftLimit: result.Product.OverdraftLimit,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Fees, err = q.ListFeeSchedules(ctx, ListFeeSchedulesParams{
+			ProductCode: result.Product.Code,
+			Currency:    arg.Currency,
+		})
+		return err
+	})
+
+	return result, err
+}
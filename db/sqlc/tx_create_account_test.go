@@ -0,0 +1,81 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/AutomaticOrca/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateAccountTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := createRandomUser(t)
+	currency := util.RandomCurrency()
+
+	checking, err := store.CreateAccountTx(context.Background(), CreateAccountTxParams{
+		Owner:       user.Username,
+		Currency:    currency,
+		ProductCode: ProductChecking,
+	})
+	require.NoError(t, err)
+	require.Equal(t, ProductChecking, checking.Account.ProductCode)
+	require.Equal(t, ProductChecking, checking.Product.Code)
+	require.Equal(t, int64(0), checking.Account.Balance)
+
+	// one checking account per currency
+	_, err = store.CreateAccountTx(context.Background(), CreateAccountTxParams{
+		Owner:       user.Username,
+		Currency:    currency,
+		ProductCode: ProductChecking,
+	})
+	require.ErrorIs(t, err, ErrAccountLimitReached)
+
+	// but several savings accounts next to it
+	savings, err := store.CreateAccountTx(context.Background(), CreateAccountTxParams{
+		Owner:       user.Username,
+		Currency:    currency,
+		ProductCode: ProductSavings,
+	})
+	require.NoError(t, err)
+	_, err = store.CreateAccountTx(context.Background(), CreateAccountTxParams{
+		Owner:       user.Username,
+		Currency:    currency,
+		ProductCode: ProductSavings,
+	})
+	require.NoError(t, err)
+
+	business, err := store.CreateAccountTx(context.Background(), CreateAccountTxParams{
+		Owner:       user.Username,
+		Currency:    currency,
+		ProductCode: ProductBusiness,
+	})
+	require.NoError(t, err)
+	require.Equal(t, business.Product.OverdraftLimit, business.Account.OverdraftLimit)
+	require.Positive(t, business.Account.OverdraftLimit)
+
+	_, err = store.CreateAccountTx(context.Background(), CreateAccountTxParams{
+		Owner:       user.Username,
+		Currency:    currency,
+		ProductCode: "premium",
+	})
+	require.ErrorIs(t, err, ErrUnknownProduct)
+
+	// money sent to the user goes to their checking account
+	recipient, err := testQueries.GetAccountByOwnerCurrency(context.Background(), GetAccountByOwnerCurrencyParams{
+		Owner:    user.Username,
+		Currency: currency,
+	})
+	require.NoError(t, err)
+	require.Equal(t, checking.Account.ID, recipient.ID)
+
+	// and moving it between their own accounts is an internal transfer
+	quote, err := store.QuoteTransferTx(context.Background(), QuoteTransferTxParams{
+		FromAccountID: checking.Account.ID,
+		ToAccountID:   savings.Account.ID,
+		Amount:        100,
+	})
+	require.NoError(t, err)
+	require.Equal(t, TransferTypeInternal, quote.TransferType)
+}
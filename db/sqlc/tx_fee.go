@@ -0,0 +1,132 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+
+	"github.com/AutomaticOrca/simplebank/util"
+)
+
+// Transfer types fees are scheduled by
+const (
+	// TransferTypeInternal is a transfer between two accounts of the same user in the same currency
+	TransferTypeInternal = "internal"
+	// TransferTypeP2P is a transfer to another user in the same currency
+	TransferTypeP2P = "p2p"
+	// TransferTypeFx is a transfer across currencies, whoever the accounts belong to
+	TransferTypeFx = "fx"
+)
+
+// transferType returns the type of a transfer between two accounts
+func transferType(fromAccount, toAccount Account) string {
+	switch {
+	case fromAccount.Currency != toAccount.Currency:
+		return TransferTypeFx
+	case fromAccount.Owner == toAccount.Owner:
+		return TransferTypeInternal
+	}
+	return TransferTypeP2P
+}
+
+// transferFee is the fee charged to the from account of a transfer, and the system account credited with it
+type transferFee struct {
+	Amount    int64
+	AccountID int64
+}
+
+// quoteFee works out the fee of a transfer from the fee schedule of the from account's product and currency,
+// and of the transfer's type. A transfer without a schedule is free
+func quoteFee(ctx context.Context, q *Queries, fromAccount, toAccount Account, amount int64) (transferFee, error) {
+	schedule, err := q.GetFeeSchedule(ctx, GetFeeScheduleParams{
+		ProductCode:  fromAccount.ProductCode,
+		TransferType: transferType(fromAccount, toAccount),
+		Currency:     fromAccount.Currency,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return transferFee{}, nil
+		}
+		return transferFee{}, err
+	}
+
+	maxFee := int64(math.MaxInt64)
+	if schedule.MaxFee.Valid {
+		maxFee = schedule.MaxFee.Int64
+	}
+	fee, err := util.TransferFee(amount, schedule.FlatFee, schedule.PercentageBps, schedule.MinFee, maxFee)
+	if err != nil || fee == 0 {
+		return transferFee{}, err
+	}
+
+	feeAccountID, err := systemAccountID(ctx, q, SystemAccountPurposeFeeIncome, fromAccount.Currency)
+	if err != nil {
+		return transferFee{}, err
+	}
+	return transferFee{Amount: fee, AccountID: feeAccountID}, nil
+}
+
+// QuoteTransferTxParams describes a transfer to price, as it would be passed to TransferTx
+type QuoteTransferTxParams struct {
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	ToCurrency    string `json:"to_currency"`
+}
+
+// TransferQuote is what a transfer would cost the sender and bring the recipient at current rates and fees
+type TransferQuote struct {
+	TransferType string `json:"transfer_type"`
+	Amount       int64  `json:"amount"`
+	Currency     string `json:"currency"`
+	Fee          int64  `json:"fee"`
+	// TotalDebit is the amount plus the fee, taken from the from account
+	TotalDebit  int64  `json:"total_debit"`
+	ToAmount    int64  `json:"to_amount"`
+	ToCurrency  string `json:"to_currency"`
+	FxRate      string `json:"fx_rate"`
+	FxSpreadBps int32  `json:"fx_spread_bps"`
+}
+
+// QuoteTransferTx prices a transfer the way TransferTx would, without making it.
+// Rates and fees may change before the transfer is made, so the quote is not a promise
+func (store *SQLStore) QuoteTransferTx(ctx context.Context, arg QuoteTransferTxParams) (TransferQuote, error) {
+	var quote TransferQuote
+
+	opts := &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+	err := store.execTxWithOptions(ctx, opts, func(q *Queries) error {
+		fromAccount, err := q.GetAccount(ctx, arg.FromAccountID)
+		if err != nil {
+			return err
+		}
+		toAccount, err := q.GetAccount(ctx, arg.ToAccountID)
+		if err != nil {
+			return err
+		}
+
+		fx, err := quoteFx(ctx, q, fromAccount, toAccount, arg.Amount, arg.ToCurrency)
+		if err != nil {
+			return err
+		}
+		fee, err := quoteFee(ctx, q, fromAccount, toAccount, arg.Amount)
+		if err != nil {
+			return err
+		}
+
+		quote = TransferQuote{
+			TransferType: transferType(fromAccount, toAccount),
+			Amount:       arg.Amount,
+			Currency:     fromAccount.Currency,
+			Fee:          fee.Amount,
+			TotalDebit:   arg.Amount + fee.Amount,
+			ToAmount:     fx.ToAmount,
+			ToCurrency:   toAccount.Currency,
+			FxRate:       fx.Rate,
+			FxSpreadBps:  fx.SpreadBps,
+		}
+		return nil
+	})
+
+	return quote, err
+}
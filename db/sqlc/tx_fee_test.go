@@ -0,0 +1,118 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferTxWithFee(t *testing.T) {
+	store := NewStore(testDB)
+
+	fromAccount := createFundedAccount(t, 100000)
+	toAccount := createRandomAccount(t)
+	for toAccount.Currency != fromAccount.Currency {
+		toAccount = createRandomAccount(t)
+	}
+
+	// 1% plus 0.25, at most 1.00
+	_, err := testQueries.UpsertFeeSchedule(context.Background(), UpsertFeeScheduleParams{
+		ProductCode:   fromAccount.ProductCode,
+		TransferType:  TransferTypeP2P,
+		Currency:      fromAccount.Currency,
+		FlatFee:       25,
+		PercentageBps: 100,
+		MaxFee:        sql.NullInt64{Int64: 100, Valid: true},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		err := testQueries.DeleteFeeSchedule(context.Background(), DeleteFeeScheduleParams{
+			ProductCode:  fromAccount.ProductCode,
+			TransferType: TransferTypeP2P,
+			Currency:     fromAccount.Currency,
+		})
+		require.NoError(t, err)
+	})
+
+	quote, err := store.QuoteTransferTx(context.Background(), QuoteTransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        5000,
+	})
+	require.NoError(t, err)
+	require.Equal(t, TransferTypeP2P, quote.TransferType)
+	require.Equal(t, int64(75), quote.Fee)
+	require.Equal(t, int64(5075), quote.TotalDebit)
+	require.Equal(t, int64(5000), quote.ToAmount)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        5000,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(75), result.Fee)
+	require.Equal(t, fromAccount.Balance-5075, result.FromAccount.Balance)
+	require.Equal(t, toAccount.Balance+5000, result.ToAccount.Balance)
+	require.Equal(t, int64(-5000), result.FromEntry.Amount)
+
+	feeAccount, err := testQueries.GetSystemAccount(context.Background(), GetSystemAccountParams{
+		Purpose:  SystemAccountPurposeFeeIncome,
+		Currency: fromAccount.Currency,
+	})
+	require.NoError(t, err)
+
+	require.Len(t, result.FeeEntries, 2)
+	require.Equal(t, fromAccount.ID, result.FeeEntries[0].AccountID)
+	require.Equal(t, int64(-75), result.FeeEntries[0].Amount)
+	require.Equal(t, feeAccount.AccountID, result.FeeEntries[1].AccountID)
+	require.Equal(t, int64(75), result.FeeEntries[1].Amount)
+	for _, entry := range result.FeeEntries {
+		require.Equal(t, result.Transfer.JournalID, entry.JournalID)
+	}
+
+	// the cap applies to large transfers
+	result, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        50000,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(100), result.Fee)
+}
+
+func TestTransferTxFeeInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+
+	fromAccount := createFundedAccount(t, 100)
+	toAccount := createRandomAccount(t)
+	for toAccount.Currency != fromAccount.Currency {
+		toAccount = createRandomAccount(t)
+	}
+
+	_, err := testQueries.UpsertFeeSchedule(context.Background(), UpsertFeeScheduleParams{
+		ProductCode:  fromAccount.ProductCode,
+		TransferType: TransferTypeP2P,
+		Currency:     fromAccount.Currency,
+		FlatFee:      fromAccount.Balance + 1,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		err := testQueries.DeleteFeeSchedule(context.Background(), DeleteFeeScheduleParams{
+			ProductCode:  fromAccount.ProductCode,
+			TransferType: TransferTypeP2P,
+			Currency:     fromAccount.Currency,
+		})
+		require.NoError(t, err)
+	})
+
+	// the amount alone is covered, but not with the fee on top
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        1,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+}
@@ -0,0 +1,220 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+const (
+	HoldStatusAuthorized = "authorized"
+	HoldStatusCaptured   = "captured"
+	HoldStatusVoided     = "voided"
+	HoldStatusExpired    = "expired"
+)
+
+// AuthorizeHoldTxParams contains the input parameters of the authorize hold transaction
+type AuthorizeHoldTxParams struct {
+	FromAccountID int64 `json:"from_account_id"`
+	ToAccountID   int64 `json:"to_account_id"`
+	Amount        int64 `json:"amount"`
+	// ToCurrency is the currency expected on the to account when the hold is captured
+	ToCurrency string    `json:"to_currency"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	// Limits are the default velocity limits of the owner of the from account, see checkVelocityLimits. Nil skips the check
+	Limits *TransferLimits `json:"-"`
+}
+
+type AuthorizeHoldTxResult struct {
+	Hold        Hold    `json:"hold"`
+	FromAccount Account `json:"from_account"`
+}
+
+// AuthorizeHoldTx places a hold on the from account. The held amount is no longer available
+// to other transfers, but the balance does not change until the hold is captured.
+// The hold counts against the velocity limits from now on, so it fails with a *VelocityLimitError
+// if the owner would go past a limit, and its capture is not checked again
+func (store *SQLStore) AuthorizeHoldTx(ctx context.Context, arg AuthorizeHoldTxParams) (AuthorizeHoldTxResult, error) {
+	var result AuthorizeHoldTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		fromAccount, err := q.GetAccountForUpdate(ctx, arg.FromAccountID)
+		if err != nil {
+			return err
+		}
+
+		if err := checkSufficientFunds(fromAccount, arg.Amount); err != nil {
+			return err
+		}
+		if arg.Limits != nil {
+			if err := checkVelocityLimits(ctx, q, fromAccount, arg.Amount, *arg.Limits); err != nil {
+				return err
+			}
+		}
+
+		result.Hold, err = q.CreateHold(ctx, CreateHoldParams{
+			FromAccountID: arg.FromAccountID,
+			ToAccountID:   arg.ToAccountID,
+			Amount:        arg.Amount,
+			ToCurrency:    arg.ToCurrency,
+			ExpiresAt:     arg.ExpiresAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.FromAccount, err = q.AddAccountHeldAmount(ctx, AddAccountHeldAmountParams{
+			ID:     arg.FromAccountID,
+			Amount: arg.Amount,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+// CaptureHoldTxParams contains the input parameters of the capture hold transaction
+type CaptureHoldTxParams struct {
+	HoldID int64 `json:"hold_id"`
+	// Amount may be less than the hold, the rest is released. Zero captures the whole hold
+	Amount int64 `json:"amount"`
+}
+
+type CaptureHoldTxResult struct {
+	Hold Hold `json:"hold"`
+	TransferTxResult
+}
+
+// CaptureHoldTx releases an authorized hold and transfers the captured amount to the to account.
+// The velocity limits were checked when the hold was authorized, for at least the captured amount
+func (store *SQLStore) CaptureHoldTx(ctx context.Context, arg CaptureHoldTxParams) (CaptureHoldTxResult, error) {
+	var result CaptureHoldTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		hold, err := lockActiveHold(ctx, q, arg.HoldID)
+		if err != nil {
+			return err
+		}
+		if !hold.ExpiresAt.After(time.Now()) {
+			return fmt.Errorf("%w: hold [%d] expired at %s", ErrHoldNotActive, hold.ID, hold.ExpiresAt)
+		}
+
+		amount := arg.Amount
+		if amount == 0 {
+			amount = hold.Amount
+		}
+		if amount < 0 || amount > hold.Amount {
+			return fmt.Errorf("%w: hold [%d] is for %d, cannot capture %d", ErrCaptureExceedsHold, hold.ID, hold.Amount, amount)
+		}
+
+		fromAccount, toAccount, err := lockTransferAccounts(ctx, q, hold.FromAccountID, hold.ToAccountID)
+		if err != nil {
+			return err
+		}
+
+		// the held money is released first, so that it can pay for the transfer
+		fromAccount, err = q.AddAccountHeldAmount(ctx, AddAccountHeldAmountParams{
+			ID:     fromAccount.ID,
+			Amount: -hold.Amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.TransferTxResult, err = transferLocked(ctx, q, fromAccount, toAccount, TransferTxParams{
+			FromAccountID: hold.FromAccountID,
+			ToAccountID:   hold.ToAccountID,
+			Amount:        amount,
+			ToCurrency:    hold.ToCurrency,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Hold, err = q.UpdateHold(ctx, UpdateHoldParams{
+			ID:             hold.ID,
+			Status:         HoldStatusCaptured,
+			CapturedAmount: amount,
+			TransferID:     sql.NullInt64{Int64: result.Transfer.ID, Valid: true},
+		})
+		return err
+	})
+
+	return result, err
+}
+
+type ReleaseHoldTxResult struct {
+	Hold        Hold    `json:"hold"`
+	FromAccount Account `json:"from_account"`
+}
+
+// VoidHoldTx cancels an authorized hold and makes the held amount available again
+func (store *SQLStore) VoidHoldTx(ctx context.Context, holdID int64) (ReleaseHoldTxResult, error) {
+	var result ReleaseHoldTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		hold, err := lockActiveHold(ctx, q, holdID)
+		if err != nil {
+			return err
+		}
+
+		result, err = releaseHold(ctx, q, hold, HoldStatusVoided)
+		return err
+	})
+
+	return result, err
+}
+
+// ExpireHoldTx releases an authorized hold that is past its expiry time.
+// It fails with ErrHoldNotActive if the hold has not expired yet
+func (store *SQLStore) ExpireHoldTx(ctx context.Context, holdID int64) (ReleaseHoldTxResult, error) {
+	var result ReleaseHoldTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		hold, err := lockActiveHold(ctx, q, holdID)
+		if err != nil {
+			return err
+		}
+		if hold.ExpiresAt.After(time.Now()) {
+			return fmt.Errorf("%w: hold [%d] expires at %s", ErrHoldNotActive, hold.ID, hold.ExpiresAt)
+		}
+
+		result, err = releaseHold(ctx, q, hold, HoldStatusExpired)
+		return err
+	})
+
+	return result, err
+}
+
+// lockActiveHold locks a hold and makes sure it is still authorized.
+// The hold is always locked before its accounts
+func lockActiveHold(ctx context.Context, q *Queries, holdID int64) (Hold, error) {
+	hold, err := q.GetHoldForUpdate(ctx, holdID)
+	if err != nil {
+		return hold, err
+	}
+	if hold.Status != HoldStatusAuthorized {
+		return hold, fmt.Errorf("%w: hold [%d] is %s", ErrHoldNotActive, hold.ID, hold.Status)
+	}
+	return hold, nil
+}
+
+func releaseHold(ctx context.Context, q *Queries, hold Hold, status string) (ReleaseHoldTxResult, error) {
+	var result ReleaseHoldTxResult
+	var err error
+
+	result.FromAccount, err = q.AddAccountHeldAmount(ctx, AddAccountHeldAmountParams{
+		ID:     hold.FromAccountID,
+		Amount: -hold.Amount,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.Hold, err = q.UpdateHold(ctx, UpdateHoldParams{
+		ID:     hold.ID,
+		Status: status,
+	})
+	return result, err
+}
@@ -0,0 +1,109 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func authorizeTestHold(t *testing.T, from, to Account, amount int64, expiresAt time.Time) Hold {
+	store := NewStore(testDB)
+
+	result, err := store.AuthorizeHoldTx(context.Background(), AuthorizeHoldTxParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        amount,
+		ToCurrency:    to.Currency,
+		ExpiresAt:     expiresAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, HoldStatusAuthorized, result.Hold.Status)
+	require.Equal(t, from.HeldAmount+amount, result.FromAccount.HeldAmount)
+	require.Equal(t, from.Balance, result.FromAccount.Balance)
+	return result.Hold
+}
+
+func TestAuthorizeHoldTxReservesFunds(t *testing.T) {
+	account1 := createFundedAccount(t, 100)
+	account2 := createRandomAccount(t)
+	for account2.Currency != account1.Currency {
+		account2 = createRandomAccount(t)
+	}
+
+	authorizeTestHold(t, account1, account2, account1.Balance, time.Now().Add(time.Hour))
+
+	// the held money cannot be spent by another transfer
+	store := NewStore(testDB)
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1,
+		ToCurrency:    account2.Currency,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+}
+
+func TestCaptureHoldTxPartial(t *testing.T) {
+	account1 := createFundedAccount(t, 100)
+	account2 := createRandomAccount(t)
+	for account2.Currency != account1.Currency {
+		account2 = createRandomAccount(t)
+	}
+
+	hold := authorizeTestHold(t, account1, account2, 100, time.Now().Add(time.Hour))
+
+	store := NewStore(testDB)
+	_, err := store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{HoldID: hold.ID, Amount: 101})
+	require.ErrorIs(t, err, ErrCaptureExceedsHold)
+
+	result, err := store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{HoldID: hold.ID, Amount: 60})
+	require.NoError(t, err)
+	require.Equal(t, HoldStatusCaptured, result.Hold.Status)
+	require.Equal(t, int64(60), result.Hold.CapturedAmount)
+	require.Equal(t, result.Transfer.ID, result.Hold.TransferID.Int64)
+	require.Equal(t, account1.Balance-60, result.FromAccount.Balance)
+	require.Equal(t, account1.HeldAmount, result.FromAccount.HeldAmount)
+	require.Equal(t, account2.Balance+60, result.ToAccount.Balance)
+
+	// a hold is captured at most once
+	_, err = store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{HoldID: hold.ID})
+	require.ErrorIs(t, err, ErrHoldNotActive)
+}
+
+func TestVoidHoldTx(t *testing.T) {
+	account1 := createFundedAccount(t, 100)
+	account2 := createRandomAccount(t)
+
+	hold := authorizeTestHold(t, account1, account2, 100, time.Now().Add(time.Hour))
+
+	store := NewStore(testDB)
+	result, err := store.VoidHoldTx(context.Background(), hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, HoldStatusVoided, result.Hold.Status)
+	require.Equal(t, account1.Balance, result.FromAccount.Balance)
+	require.Equal(t, account1.HeldAmount, result.FromAccount.HeldAmount)
+
+	_, err = store.VoidHoldTx(context.Background(), hold.ID)
+	require.ErrorIs(t, err, ErrHoldNotActive)
+}
+
+func TestExpireHoldTx(t *testing.T) {
+	account1 := createFundedAccount(t, 100)
+	account2 := createRandomAccount(t)
+
+	store := NewStore(testDB)
+
+	active := authorizeTestHold(t, account1, account2, 10, time.Now().Add(time.Hour))
+	_, err := store.ExpireHoldTx(context.Background(), active.ID)
+	require.ErrorIs(t, err, ErrHoldNotActive)
+
+	expired := authorizeTestHold(t, account1, account2, 10, time.Now().Add(-time.Minute))
+	result, err := store.ExpireHoldTx(context.Background(), expired.ID)
+	require.NoError(t, err)
+	require.Equal(t, HoldStatusExpired, result.Hold.Status)
+
+	_, err = store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{HoldID: expired.ID})
+	require.ErrorIs(t, err, ErrHoldNotActive)
+}
@@ -0,0 +1,140 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/AutomaticOrca/simplebank/util"
+)
+
+type AccrueInterestTxResult struct {
+	// Accruals is the number of accounts that accrued interest, accounts already accrued for the day are not counted
+	Accruals int64 `json:"accruals"`
+}
+
+// AccrueInterestTx works out the interest every account earned on a UTC day at the rate of its product,
+// on its end of day balance. Running it again for the same day changes nothing
+func (store *SQLStore) AccrueInterestTx(ctx context.Context, accrualDate time.Time) (AccrueInterestTxResult, error) {
+	var result AccrueInterestTxResult
+	accrualDate = utcDay(accrualDate)
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		balances, err := q.ListInterestBearingBalances(ctx, accrualDate)
+		if err != nil {
+			return err
+		}
+
+		for _, balance := range balances {
+			amount, err := util.DailyInterest(balance.Balance, balance.AnnualInterestRate)
+			if err != nil {
+				return fmt.Errorf("account [%d]: %w", balance.AccountID, err)
+			}
+
+			count, err := q.CreateInterestAccrual(ctx, CreateInterestAccrualParams{
+				AccountID:          balance.AccountID,
+				AccrualDate:        accrualDate,
+				Balance:            balance.Balance,
+				AnnualInterestRate: balance.AnnualInterestRate,
+				Amount:             amount,
+			})
+			if err != nil {
+				return err
+			}
+			result.Accruals += count
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
+// PostInterestTxParams selects the month to pay the accrued interest of, by any day in it
+type PostInterestTxParams struct {
+	AccountID int64     `json:"account_id"`
+	Period    time.Time `json:"period"`
+}
+
+type PostInterestTxResult struct {
+	Posting InterestPosting `json:"posting"`
+	Account Account         `json:"account"`
+	// Entries are the entries of the interest journal, none when the interest rounded to zero
+	Entries []Entry `json:"entries"`
+}
+
+// PostInterestTx pays the interest an account accrued in a month, rounded to the minor unit of its currency,
+// out of the interest expense system account. A month is posted once: posting it again fails with ErrInterestAlreadyPosted
+func (store *SQLStore) PostInterestTx(ctx context.Context, arg PostInterestTxParams) (PostInterestTxResult, error) {
+	var result PostInterestTxResult
+	year, month, _ := arg.Period.UTC().Date()
+	period := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		result.Account, err = q.GetAccount(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		accrued, err := q.SumUnpostedInterestAccruals(ctx, SumUnpostedInterestAccrualsParams{
+			AccountID: arg.AccountID,
+			Period:    period,
+		})
+		if err != nil {
+			return err
+		}
+		amount, err := util.RoundInterest(accrued, result.Account.Currency)
+		if err != nil {
+			return err
+		}
+
+		result.Posting, err = q.CreateInterestPosting(ctx, CreateInterestPostingParams{
+			AccountID: arg.AccountID,
+			Period:    period,
+			Amount:    amount,
+		})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrInterestAlreadyPosted
+			}
+			return err
+		}
+
+		_, err = q.MarkInterestAccrualsPosted(ctx, MarkInterestAccrualsPostedParams{
+			PostingID: sql.NullInt64{Int64: result.Posting.ID, Valid: true},
+			AccountID: arg.AccountID,
+			Period:    period,
+		})
+		if err != nil {
+			return err
+		}
+
+		if amount == 0 {
+			return nil
+		}
+
+		expenseAccountID, err := systemAccountID(ctx, q, SystemAccountPurposeInterestExpense, result.Account.Currency)
+		if err != nil {
+			return err
+		}
+		journal, err := postJournal(ctx, q, []JournalLeg{
+			{AccountID: expenseAccountID, Amount: -amount},
+			{AccountID: arg.AccountID, Amount: amount},
+		})
+		if err != nil {
+			return err
+		}
+		result.Entries = journal.Entries
+		result.Account = journal.account(arg.AccountID)
+
+		result.Posting, err = q.SetInterestPostingJournal(ctx, SetInterestPostingJournalParams{
+			ID:        result.Posting.ID,
+			JournalID: sql.NullInt64{Int64: journal.Journal.ID, Valid: true},
+		})
+		return err
+	})
+
+	return result, err
+}
@@ -0,0 +1,94 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/AutomaticOrca/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createSavingsAccount(t *testing.T, balance int64) Account {
+	user := createRandomUser(t)
+
+	account, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:       user.Username,
+		Balance:     balance,
+		Currency:    util.RandomCurrency(),
+		ProductCode: ProductSavings,
+	})
+	require.NoError(t, err)
+	return account
+}
+
+func TestAccrueAndPostInterestTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	// 100,000.00 at 2% earns 5.479452 a day
+	account := createSavingsAccount(t, 10_000_000)
+	checking := createFundedAccount(t, 10_000_000)
+	today := utcDay(time.Now())
+
+	_, err := store.AccrueInterestTx(context.Background(), today)
+	require.NoError(t, err)
+
+	amount, err := testQueries.SumUnpostedInterestAccruals(context.Background(), SumUnpostedInterestAccrualsParams{
+		AccountID: account.ID,
+		Period:    today,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "547.945205", amount)
+
+	amount, err = testQueries.SumUnpostedInterestAccruals(context.Background(), SumUnpostedInterestAccrualsParams{
+		AccountID: checking.ID,
+		Period:    today,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "0", amount)
+
+	// accruing the same day again changes nothing
+	_, err = store.AccrueInterestTx(context.Background(), today)
+	require.NoError(t, err)
+	amount, err = testQueries.SumUnpostedInterestAccruals(context.Background(), SumUnpostedInterestAccrualsParams{
+		AccountID: account.ID,
+		Period:    today,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "547.945205", amount)
+
+	result, err := store.PostInterestTx(context.Background(), PostInterestTxParams{
+		AccountID: account.ID,
+		Period:    today,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(548), result.Posting.Amount)
+	require.True(t, result.Posting.JournalID.Valid)
+	require.Equal(t, account.Balance+548, result.Account.Balance)
+
+	require.Len(t, result.Entries, 2)
+	require.Equal(t, int64(-548), result.Entries[0].Amount)
+	require.Equal(t, account.ID, result.Entries[1].AccountID)
+	require.Equal(t, int64(548), result.Entries[1].Amount)
+
+	expenseAccount, err := testQueries.GetSystemAccount(context.Background(), GetSystemAccountParams{
+		Purpose:  SystemAccountPurposeInterestExpense,
+		Currency: account.Currency,
+	})
+	require.NoError(t, err)
+	require.Equal(t, expenseAccount.AccountID, result.Entries[0].AccountID)
+
+	// the accruals are marked as paid and the month cannot be posted twice
+	amount, err = testQueries.SumUnpostedInterestAccruals(context.Background(), SumUnpostedInterestAccrualsParams{
+		AccountID: account.ID,
+		Period:    today,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "0", amount)
+
+	_, err = store.PostInterestTx(context.Background(), PostInterestTxParams{
+		AccountID: account.ID,
+		Period:    today,
+	})
+	require.ErrorIs(t, err, ErrInterestAlreadyPosted)
+}
@@ -0,0 +1,162 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+)
+
+const (
+	// SystemAccountPurposeFxPosition is the system account that buys and sells each currency in cross currency transfers
+	SystemAccountPurposeFxPosition = "fx_position"
+	// SystemAccountPurposeInterestExpense is the system account interest is paid out of
+	SystemAccountPurposeInterestExpense = "interest_expense"
+	// SystemAccountPurposeFeeIncome is the system account transfer fees are credited to
+	SystemAccountPurposeFeeIncome = "fee_income"
+	// SystemAccountPurposeCashIn is the system account deposits are paid in from
+	SystemAccountPurposeCashIn = "cash_in"
+	// SystemAccountPurposeCashOut is the system account withdrawals are paid out to
+	SystemAccountPurposeCashOut = "cash_out"
+)
+
+// JournalLeg is one line of a journal. A positive amount credits the account, a negative amount debits it
+type JournalLeg struct {
+	AccountID int64 `json:"account_id"`
+	Amount    int64 `json:"amount"`
+}
+
+// PostJournalTxParams contains the legs of a journal, which must sum to zero in every currency
+type PostJournalTxParams struct {
+	Legs []JournalLeg `json:"legs"`
+}
+
+// PostJournalTxResult is the result of the post journal transaction
+type PostJournalTxResult struct {
+	Journal Journal `json:"journal"`
+	// Entries has one entry per leg, in the order of the legs
+	Entries []Entry `json:"entries"`
+	// Accounts has every account of the journal once, after posting, in ascending ID order
+	Accounts []Account `json:"accounts"`
+}
+
+// account returns the account with the given ID after posting
+func (result PostJournalTxResult) account(id int64) Account {
+	i := sort.Search(len(result.Accounts), func(i int) bool { return result.Accounts[i].ID >= id })
+	if i < len(result.Accounts) && result.Accounts[i].ID == id {
+		return result.Accounts[i]
+	}
+	return Account{}
+}
+
+// PostJournalTx writes a balanced set of entries under one journal and moves the balances.
+// It fails with ErrUnbalancedJournal if the legs don't sum to zero per currency,
+// and with ErrInsufficientFunds if an account would go past its overdraft limit
+func (store *SQLStore) PostJournalTx(ctx context.Context, arg PostJournalTxParams) (PostJournalTxResult, error) {
+	var result PostJournalTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		result, err = postJournal(ctx, q, arg.Legs)
+		return err
+	})
+
+	return result, err
+}
+
+// postJournal runs the steps of PostJournalTx within the caller's transaction.
+// Accounts are locked in ascending ID order; accounts already locked by the caller are not waited on again
+func postJournal(ctx context.Context, q *Queries, legs []JournalLeg) (PostJournalTxResult, error) {
+	var result PostJournalTxResult
+
+	if len(legs) == 0 {
+		return result, fmt.Errorf("%w: no legs", ErrUnbalancedJournal)
+	}
+
+	accountIDs := make([]int64, len(legs))
+	for i, leg := range legs {
+		accountIDs[i] = leg.AccountID
+	}
+	accounts, err := lockAccounts(ctx, q, accountIDs)
+	if err != nil {
+		return result, err
+	}
+
+	sums := make(map[string]int64)
+	nets := make(map[int64]int64)
+	for _, leg := range legs {
+		account, ok := accounts[leg.AccountID]
+		if !ok {
+			return result, fmt.Errorf("account [%d]: %w", leg.AccountID, sql.ErrNoRows)
+		}
+		if leg.Amount == 0 {
+			return result, fmt.Errorf("%w: leg on account [%d] has no amount", ErrInvalidAmount, leg.AccountID)
+		}
+		sums[account.Currency] += leg.Amount
+		nets[account.ID] += leg.Amount
+	}
+
+	for currency, sum := range sums {
+		if sum != 0 {
+			return result, fmt.Errorf("%w: %s legs sum to %d", ErrUnbalancedJournal, currency, sum)
+		}
+	}
+
+	ids := make([]int64, 0, len(nets))
+	for id := range nets {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	for _, id := range ids {
+		if nets[id] < 0 {
+			if err := checkSufficientFunds(accounts[id], -nets[id]); err != nil {
+				return result, err
+			}
+		}
+	}
+
+	result.Journal, err = q.CreateJournal(ctx)
+	if err != nil {
+		return result, err
+	}
+	journalID := sql.NullInt64{Int64: result.Journal.ID, Valid: true}
+
+	result.Entries = make([]Entry, len(legs))
+	for i, leg := range legs {
+		result.Entries[i], err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID: leg.AccountID,
+			Amount:    leg.Amount,
+			JournalID: journalID,
+		})
+		if err != nil {
+			return result, err
+		}
+	}
+
+	// balances are updated in ascending ID order too, so that row locks are never taken out of order
+	result.Accounts = make([]Account, len(ids))
+	for i, id := range ids {
+		result.Accounts[i], err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+			ID:     id,
+			Amount: nets[id],
+		})
+		if err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// systemAccountID returns the ID of the system account for purpose in currency
+func systemAccountID(ctx context.Context, q *Queries, purpose, currency string) (int64, error) {
+	systemAccount, err := q.GetSystemAccount(ctx, GetSystemAccountParams{
+		Purpose:  purpose,
+		Currency: currency,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("cannot find %s system account for %s: %v", purpose, currency, err)
+	}
+	return systemAccount.AccountID, nil
+}
@@ -0,0 +1,123 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostJournalTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createFundedAccount(t, 100)
+	account2 := createRandomAccount(t)
+	for account2.Currency != account1.Currency {
+		account2 = createRandomAccount(t)
+	}
+	account3 := createRandomAccount(t)
+	for account3.Currency != account1.Currency {
+		account3 = createRandomAccount(t)
+	}
+
+	// one debit split over two credits
+	result, err := store.PostJournalTx(context.Background(), PostJournalTxParams{
+		Legs: []JournalLeg{
+			{AccountID: account1.ID, Amount: -30},
+			{AccountID: account2.ID, Amount: 10},
+			{AccountID: account3.ID, Amount: 20},
+		},
+	})
+	require.NoError(t, err)
+	require.NotZero(t, result.Journal.ID)
+	require.Len(t, result.Entries, 3)
+	require.Len(t, result.Accounts, 3)
+
+	for i, entry := range result.Entries {
+		require.Equal(t, result.Journal.ID, entry.JournalID.Int64)
+		require.Equal(t, []int64{-30, 10, 20}[i], entry.Amount)
+	}
+	require.Equal(t, account1.Balance-30, result.account(account1.ID).Balance)
+	require.Equal(t, account3.Balance+20, result.account(account3.ID).Balance)
+
+	entries, err := store.ListJournalEntries(context.Background(), sql.NullInt64{Int64: result.Journal.ID, Valid: true})
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+}
+
+func TestPostJournalTxRejected(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createFundedAccount(t, 100)
+	account2 := createRandomAccount(t)
+	for account2.Currency != account1.Currency {
+		account2 = createRandomAccount(t)
+	}
+	otherCurrency := createRandomAccount(t)
+	for otherCurrency.Currency == account1.Currency {
+		otherCurrency = createRandomAccount(t)
+	}
+
+	testCases := []struct {
+		name string
+		legs []JournalLeg
+		err  error
+	}{
+		{
+			name: "NoLegs",
+			err:  ErrUnbalancedJournal,
+		},
+		{
+			name: "Unbalanced",
+			legs: []JournalLeg{
+				{AccountID: account1.ID, Amount: -10},
+				{AccountID: account2.ID, Amount: 9},
+			},
+			err: ErrUnbalancedJournal,
+		},
+		{
+			name: "UnbalancedPerCurrency",
+			legs: []JournalLeg{
+				{AccountID: account1.ID, Amount: -10},
+				{AccountID: otherCurrency.ID, Amount: 10},
+			},
+			err: ErrUnbalancedJournal,
+		},
+		{
+			name: "ZeroLeg",
+			legs: []JournalLeg{
+				{AccountID: account1.ID, Amount: 0},
+				{AccountID: account2.ID, Amount: 0},
+			},
+			err: ErrInvalidAmount,
+		},
+		{
+			name: "InsufficientFunds",
+			legs: []JournalLeg{
+				{AccountID: account1.ID, Amount: -account1.Balance - 1},
+				{AccountID: account2.ID, Amount: account1.Balance + 1},
+			},
+			err: ErrInsufficientFunds,
+		},
+		{
+			name: "AccountNotFound",
+			legs: []JournalLeg{
+				{AccountID: account1.ID, Amount: -10},
+				{AccountID: -1, Amount: 10},
+			},
+			err: sql.ErrNoRows,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := store.PostJournalTx(context.Background(), PostJournalTxParams{Legs: tc.legs})
+			require.ErrorIs(t, err, tc.err)
+		})
+	}
+
+	account, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, account.Balance)
+}
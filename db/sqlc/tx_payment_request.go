@@ -0,0 +1,139 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+const (
+	PaymentRequestStatusPending  = "pending"
+	PaymentRequestStatusAccepted = "accepted"
+	PaymentRequestStatusDeclined = "declined"
+	PaymentRequestStatusExpired  = "expired"
+)
+
+type CreatePaymentRequestTxParams struct {
+	CreatePaymentRequestParams
+	// AfterCreate runs inside the transaction, so the request is rolled back if the payer cannot be notified
+	AfterCreate func(paymentRequest PaymentRequest) error
+}
+
+// CreatePaymentRequestTx stores a request from one user to another to pay an amount into one of the requester's accounts
+func (store *SQLStore) CreatePaymentRequestTx(ctx context.Context, arg CreatePaymentRequestTxParams) (PaymentRequest, error) {
+	var result PaymentRequest
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result, err = q.CreatePaymentRequest(ctx, arg.CreatePaymentRequestParams)
+		if err != nil {
+			return err
+		}
+
+		return arg.AfterCreate(result)
+	})
+
+	return result, err
+}
+
+// AcceptPaymentRequestTxParams contains the input parameters of the accept payment request transaction
+type AcceptPaymentRequestTxParams struct {
+	PaymentRequestID int64 `json:"payment_request_id"`
+	// FromAccountID is the payer's account, in the currency of the request
+	FromAccountID int64 `json:"from_account_id"`
+	// Limits are the payer's default velocity limits, see TransferTxParams
+	Limits      *TransferLimits                           `json:"-"`
+	AfterAccept func(paymentRequest PaymentRequest) error `json:"-"`
+}
+
+type AcceptPaymentRequestTxResult struct {
+	PaymentRequest PaymentRequest `json:"payment_request"`
+	TransferTxResult
+}
+
+// AcceptPaymentRequestTx pays a pending payment request with a transfer and marks it accepted.
+// It fails with ErrPaymentRequestNotPending if the request was already answered or has expired.
+// If the transfer is rejected nothing is written, and the request stays pending
+func (store *SQLStore) AcceptPaymentRequestTx(ctx context.Context, arg AcceptPaymentRequestTxParams) (AcceptPaymentRequestTxResult, error) {
+	var result AcceptPaymentRequestTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		paymentRequest, err := lockPendingPaymentRequest(ctx, q, arg.PaymentRequestID)
+		if err != nil {
+			return err
+		}
+
+		result.TransferTxResult, err = transfer(ctx, q, TransferTxParams{
+			FromAccountID: arg.FromAccountID,
+			ToAccountID:   paymentRequest.ToAccountID,
+			Amount:        paymentRequest.Amount,
+			ToCurrency:    paymentRequest.Currency,
+			Description:   paymentRequest.Description,
+			Limits:        arg.Limits,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.PaymentRequest, err = q.UpdatePaymentRequest(ctx, UpdatePaymentRequestParams{
+			ID:         paymentRequest.ID,
+			Status:     PaymentRequestStatusAccepted,
+			TransferID: sql.NullInt64{Int64: result.Transfer.ID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		return arg.AfterAccept(result.PaymentRequest)
+	})
+
+	return result, err
+}
+
+type DeclinePaymentRequestTxParams struct {
+	PaymentRequestID int64
+	AfterDecline     func(paymentRequest PaymentRequest) error
+}
+
+// DeclinePaymentRequestTx marks a pending payment request declined.
+// It fails with ErrPaymentRequestNotPending if the request was already answered or has expired
+func (store *SQLStore) DeclinePaymentRequestTx(ctx context.Context, arg DeclinePaymentRequestTxParams) (PaymentRequest, error) {
+	var result PaymentRequest
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		paymentRequest, err := lockPendingPaymentRequest(ctx, q, arg.PaymentRequestID)
+		if err != nil {
+			return err
+		}
+
+		result, err = q.UpdatePaymentRequest(ctx, UpdatePaymentRequestParams{
+			ID:     paymentRequest.ID,
+			Status: PaymentRequestStatusDeclined,
+		})
+		if err != nil {
+			return err
+		}
+
+		return arg.AfterDecline(result)
+	})
+
+	return result, err
+}
+
+// lockPendingPaymentRequest locks a payment request and makes sure it can still be answered.
+// The request is always locked before the accounts of its transfer
+func lockPendingPaymentRequest(ctx context.Context, q *Queries, paymentRequestID int64) (PaymentRequest, error) {
+	paymentRequest, err := q.GetPaymentRequestForUpdate(ctx, paymentRequestID)
+	if err != nil {
+		return paymentRequest, err
+	}
+	if paymentRequest.Status != PaymentRequestStatusPending {
+		return paymentRequest, fmt.Errorf("%w: payment request [%d] is %s", ErrPaymentRequestNotPending, paymentRequest.ID, paymentRequest.Status)
+	}
+	if !paymentRequest.ExpiresAt.After(time.Now()) {
+		return paymentRequest, fmt.Errorf("%w: payment request [%d] expired at %s", ErrPaymentRequestNotPending, paymentRequest.ID, paymentRequest.ExpiresAt)
+	}
+	return paymentRequest, nil
+}
@@ -0,0 +1,115 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createTestPaymentRequest(t *testing.T, to Account, payer string, amount int64, expiresAt time.Time) PaymentRequest {
+	store := NewStore(testDB)
+
+	paymentRequest, err := store.CreatePaymentRequestTx(context.Background(), CreatePaymentRequestTxParams{
+		CreatePaymentRequestParams: CreatePaymentRequestParams{
+			Requester:   to.Owner,
+			Payer:       payer,
+			ToAccountID: to.ID,
+			Amount:      amount,
+			Currency:    to.Currency,
+			ExpiresAt:   expiresAt,
+		},
+		AfterCreate: func(PaymentRequest) error { return nil },
+	})
+	require.NoError(t, err)
+	require.Equal(t, PaymentRequestStatusPending, paymentRequest.Status)
+	return paymentRequest
+}
+
+func TestAcceptPaymentRequestTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	payerAccount := createFundedAccount(t, 100)
+	requesterAccount := createRandomAccount(t)
+	for requesterAccount.Currency != payerAccount.Currency {
+		requesterAccount = createRandomAccount(t)
+	}
+
+	paymentRequest := createTestPaymentRequest(t, requesterAccount, payerAccount.Owner, 60, time.Now().Add(time.Hour))
+
+	result, err := store.AcceptPaymentRequestTx(context.Background(), AcceptPaymentRequestTxParams{
+		PaymentRequestID: paymentRequest.ID,
+		FromAccountID:    payerAccount.ID,
+		AfterAccept:      func(PaymentRequest) error { return nil },
+	})
+	require.NoError(t, err)
+	require.Equal(t, PaymentRequestStatusAccepted, result.PaymentRequest.Status)
+	require.True(t, result.PaymentRequest.TransferID.Valid)
+	require.Equal(t, result.Transfer.ID, result.PaymentRequest.TransferID.Int64)
+	require.Equal(t, payerAccount.Balance-60, result.FromAccount.Balance)
+	require.Equal(t, requesterAccount.Balance+60, result.ToAccount.Balance)
+
+	// an answered request cannot be answered again
+	_, err = store.AcceptPaymentRequestTx(context.Background(), AcceptPaymentRequestTxParams{
+		PaymentRequestID: paymentRequest.ID,
+		FromAccountID:    payerAccount.ID,
+		AfterAccept:      func(PaymentRequest) error { return nil },
+	})
+	require.ErrorIs(t, err, ErrPaymentRequestNotPending)
+
+	_, err = store.DeclinePaymentRequestTx(context.Background(), DeclinePaymentRequestTxParams{
+		PaymentRequestID: paymentRequest.ID,
+		AfterDecline:     func(PaymentRequest) error { return nil },
+	})
+	require.ErrorIs(t, err, ErrPaymentRequestNotPending)
+}
+
+func TestAcceptPaymentRequestTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+
+	payerAccount := createRandomAccount(t)
+	requesterAccount := createRandomAccount(t)
+	for requesterAccount.Currency != payerAccount.Currency {
+		requesterAccount = createRandomAccount(t)
+	}
+
+	paymentRequest := createTestPaymentRequest(t, requesterAccount, payerAccount.Owner, payerAccount.Balance+1, time.Now().Add(time.Hour))
+
+	_, err := store.AcceptPaymentRequestTx(context.Background(), AcceptPaymentRequestTxParams{
+		PaymentRequestID: paymentRequest.ID,
+		FromAccountID:    payerAccount.ID,
+		AfterAccept:      func(PaymentRequest) error { return nil },
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// the request stays pending so the payer can try again
+	paymentRequest, err = testQueries.GetPaymentRequest(context.Background(), paymentRequest.ID)
+	require.NoError(t, err)
+	require.Equal(t, PaymentRequestStatusPending, paymentRequest.Status)
+}
+
+func TestExpirePaymentRequests(t *testing.T) {
+	store := NewStore(testDB)
+
+	payer := createRandomUser(t)
+	requesterAccount := createRandomAccount(t)
+	paymentRequest := createTestPaymentRequest(t, requesterAccount, payer.Username, 10, time.Now().Add(-time.Minute))
+
+	// an expired request cannot be accepted even before the expiry job has run
+	_, err := store.DeclinePaymentRequestTx(context.Background(), DeclinePaymentRequestTxParams{
+		PaymentRequestID: paymentRequest.ID,
+		AfterDecline:     func(PaymentRequest) error { return nil },
+	})
+	require.ErrorIs(t, err, ErrPaymentRequestNotPending)
+
+	_, err = testQueries.ExpirePaymentRequests(context.Background(), ExpirePaymentRequestsParams{
+		ExpiredAt: time.Now(),
+		MaxCount:  1000,
+	})
+	require.NoError(t, err)
+
+	paymentRequest, err = testQueries.GetPaymentRequest(context.Background(), paymentRequest.ID)
+	require.NoError(t, err)
+	require.Equal(t, PaymentRequestStatusExpired, paymentRequest.Status)
+}
@@ -0,0 +1,94 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+const (
+	ReconciliationStatusBalanced      = "balanced"
+	ReconciliationStatusDiscrepancies = "discrepancies"
+)
+
+// ReconciliationReport lists every discrepancy found by a reconciliation run
+type ReconciliationReport struct {
+	// BalanceDrifts are the accounts whose stored balance differs from the sum of their entries
+	BalanceDrifts []ListBalanceDriftsRow `json:"balance_drifts"`
+	// UnbalancedTransfers are the transfers whose entries do not net to zero in some currency
+	UnbalancedTransfers []ListUnbalancedTransfersRow `json:"unbalanced_transfers"`
+	// UncheckedTransfers counts the transfers made before journals that could not be linked to their entries,
+	// see migration 000028. Their entries still count towards the balance check, but they are not checked themselves
+	UncheckedTransfers int64 `json:"unchecked_transfers"`
+}
+
+// DiscrepancyCount is the number of problems in the report
+func (report ReconciliationReport) DiscrepancyCount() int {
+	return len(report.BalanceDrifts) + len(report.UnbalancedTransfers)
+}
+
+type ReconcileTxResult struct {
+	Run    ReconciliationRun    `json:"run"`
+	Report ReconciliationReport `json:"report"`
+}
+
+// ReconcileTx checks the whole ledger and records the outcome as a reconciliation run.
+// All checks read from one snapshot, so transfers made meanwhile cannot show up as drift.
+// Discrepancies are not an error: they are reported in the run for someone to look into
+func (store *SQLStore) ReconcileTx(ctx context.Context) (ReconcileTxResult, error) {
+	var result ReconcileTxResult
+	var scope CountReconciliationScopeRow
+	startedAt := time.Now()
+
+	opts := &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+	err := store.execTxWithOptions(ctx, opts, func(q *Queries) error {
+		var err error
+
+		scope, err = q.CountReconciliationScope(ctx)
+		if err != nil {
+			return err
+		}
+		result.Report.UncheckedTransfers = scope.UncheckedTransfers
+
+		result.Report.BalanceDrifts, err = q.ListBalanceDrifts(ctx)
+		if err != nil {
+			return err
+		}
+
+		result.Report.UnbalancedTransfers, err = q.ListUnbalancedTransfers(ctx)
+		return err
+	})
+	if err != nil {
+		return result, err
+	}
+
+	// an empty report lists no discrepancies rather than null ones
+	if result.Report.BalanceDrifts == nil {
+		result.Report.BalanceDrifts = []ListBalanceDriftsRow{}
+	}
+	if result.Report.UnbalancedTransfers == nil {
+		result.Report.UnbalancedTransfers = []ListUnbalancedTransfersRow{}
+	}
+
+	report, err := json.Marshal(result.Report)
+	if err != nil {
+		return result, err
+	}
+
+	status := ReconciliationStatusBalanced
+	if result.Report.DiscrepancyCount() > 0 {
+		status = ReconciliationStatusDiscrepancies
+	}
+
+	// the snapshot was read only, so the run is written on its own
+	result.Run, err = store.CreateReconciliationRun(ctx, CreateReconciliationRunParams{
+		Status:           status,
+		AccountsChecked:  scope.Accounts,
+		TransfersChecked: scope.Transfers,
+		DiscrepancyCount: int64(result.Report.DiscrepancyCount()),
+		Report:           report,
+		StartedAt:        startedAt,
+	})
+	return result, err
+}
@@ -0,0 +1,96 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconcileTxFindsBalanceDrift(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createRandomAccount(t)
+	// a balance changed without a journal
+	_, err := testQueries.AddAccountBalance(context.Background(), AddAccountBalanceParams{
+		ID:     account.ID,
+		Amount: 5,
+	})
+	require.NoError(t, err)
+
+	result, err := store.ReconcileTx(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, ReconciliationStatusDiscrepancies, result.Run.Status)
+	require.Equal(t, int64(result.Report.DiscrepancyCount()), result.Run.DiscrepancyCount)
+	require.Contains(t, result.Report.BalanceDrifts, ListBalanceDriftsRow{
+		AccountID: account.ID,
+		Currency:  account.Currency,
+		Balance:   account.Balance + 5,
+		EntrySum:  0,
+	})
+
+	run, err := testQueries.GetReconciliationRun(context.Background(), result.Run.ID)
+	require.NoError(t, err)
+
+	var report ReconciliationReport
+	err = json.Unmarshal(run.Report, &report)
+	require.NoError(t, err)
+	require.Equal(t, result.Report, report)
+}
+
+func TestReconcileTxTransfersNetToZero(t *testing.T) {
+	store := NewStore(testDB)
+
+	fromAccount := createFundedAccount(t, 100)
+	toAccount := createRandomAccount(t)
+	for toAccount.Currency == fromAccount.Currency {
+		toAccount = createRandomAccount(t)
+	}
+	_, err := testQueries.UpsertFxRate(context.Background(), UpsertFxRateParams{
+		BaseCurrency:  fromAccount.Currency,
+		QuoteCurrency: toAccount.Currency,
+		Rate:          "0.5",
+		SpreadBps:     100,
+	})
+	require.NoError(t, err)
+
+	transfer, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        10,
+		ToCurrency:    toAccount.Currency,
+	})
+	require.NoError(t, err)
+
+	result, err := store.ReconcileTx(context.Background())
+	require.NoError(t, err)
+	for _, unbalanced := range result.Report.UnbalancedTransfers {
+		require.NotEqual(t, transfer.Transfer.ID, unbalanced.TransferID)
+	}
+}
+
+func TestReconcileTxCountsUncheckedTransfers(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	// a transfer from before journals, with no entries linked to it
+	transfer, err := testQueries.CreateTransfer(context.Background(), CreateTransferParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		ToAmount:      10,
+		FxRate:        "1",
+		Metadata:      json.RawMessage(`{}`),
+	})
+	require.NoError(t, err)
+
+	result, err := store.ReconcileTx(context.Background())
+	require.NoError(t, err)
+	require.Positive(t, result.Report.UncheckedTransfers)
+	for _, unbalanced := range result.Report.UnbalancedTransfers {
+		require.NotEqual(t, transfer.ID, unbalanced.TransferID)
+	}
+}
@@ -0,0 +1,102 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// ReverseTransferTxParams contains the input parameters of the reverse transfer transaction
+type ReverseTransferTxParams struct {
+	TransferID int64 `json:"transfer_id"`
+	// Amount is in the currency of the original from account. Zero reverses whatever is left
+	Amount int64 `json:"amount"`
+}
+
+// ReverseTransferTxResult is the result of the reverse transfer transaction.
+// The embedded TransferTxResult describes the reversal, which moves money from the original to account back to the original from account
+type ReverseTransferTxResult struct {
+	TransferTxResult
+	OriginalTransfer Transfer `json:"original_transfer"`
+}
+
+// ReverseTransferTx creates a compensating transfer for all or part of an earlier transfer.
+// The sum of all reversals of a transfer can never exceed its amount.
+// Only completed transfers can be reversed, and the original becomes reversed once all of it has been reversed
+func (store *SQLStore) ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParams) (ReverseTransferTxResult, error) {
+	var result ReverseTransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		// locking the original transfer serializes concurrent reversals of it
+		result.OriginalTransfer, err = q.GetTransferForUpdate(ctx, arg.TransferID)
+		if err != nil {
+			return err
+		}
+		original := result.OriginalTransfer
+
+		if original.ReversedTransferID.Valid {
+			return fmt.Errorf("%w: transfer [%d] is itself a reversal", ErrTransferNotReversible, original.ID)
+		}
+		if original.Status != TransferStatusCompleted {
+			return fmt.Errorf("%w: transfer [%d] is %s", ErrTransferNotReversible, original.ID, original.Status)
+		}
+
+		reversedAmount, err := q.GetTransferReversedAmount(ctx, sql.NullInt64{Int64: original.ID, Valid: true})
+		if err != nil {
+			return err
+		}
+
+		remaining := original.Amount - reversedAmount
+		amount := arg.Amount
+		if amount == 0 {
+			amount = remaining
+		}
+		if amount <= 0 || amount > remaining {
+			return fmt.Errorf("%w: transfer [%d] has %d left to reverse, requested %d",
+				ErrReversalExceedsAmount, original.ID, remaining, amount)
+		}
+
+		// the amount taken back from the recipient is worked out on the running total,
+		// so that a transfer reversed in several parts debits exactly its to_amount in the end
+		debit := reversedShare(original, reversedAmount+amount) - reversedShare(original, reversedAmount)
+		if debit <= 0 {
+			return fmt.Errorf("%w: reversing %d of transfer [%d] takes nothing back from the recipient", ErrInvalidAmount, amount, original.ID)
+		}
+
+		fromAccount, toAccount, err := lockTransferAccounts(ctx, q, original.ToAccountID, original.FromAccountID)
+		if err != nil {
+			return err
+		}
+
+		// reversals are free, and the fee of the original transfer is kept
+		result.TransferTxResult, err = postTransfer(ctx, q, fromAccount, toAccount, CreateTransferParams{
+			Amount:             debit,
+			ToAmount:           amount,
+			FxRate:             original.FxRate,
+			FxSpreadBps:        original.FxSpreadBps,
+			ReversedTransferID: sql.NullInt64{Int64: original.ID, Valid: true},
+		}, transferFee{})
+		if err != nil {
+			return err
+		}
+
+		// the original is reversed once nothing is left to reverse
+		if amount == remaining {
+			reason := fmt.Sprintf("reversed by transfer [%d]", result.Transfer.ID)
+			result.OriginalTransfer, err = transitionTransfer(ctx, q, original, TransferStatusReversed, reason)
+		}
+		return err
+	})
+
+	return result, err
+}
+
+// reversedShare is the part of the transfer's to_amount that corresponds to reversing amount of it
+func reversedShare(transfer Transfer, amount int64) int64 {
+	if amount == transfer.Amount {
+		return transfer.ToAmount
+	}
+	return transfer.ToAmount * amount / transfer.Amount
+}
@@ -0,0 +1,105 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	ScheduledTransferStatusScheduled = "scheduled"
+	ScheduledTransferStatusCompleted = "completed"
+	ScheduledTransferStatusFailed    = "failed"
+	ScheduledTransferStatusCancelled = "cancelled"
+)
+
+type CreateScheduledTransferTxParams struct {
+	CreateScheduledTransferParams
+	// IdempotencyKey is optional. When set, the scheduled transfer is stored under this key within the same transaction
+	IdempotencyKey *CreateIdempotencyKeyParams
+	AfterCreate    func(scheduledTransfer ScheduledTransfer) error
+}
+
+type CreateScheduledTransferTxResult struct {
+	ScheduledTransfer ScheduledTransfer
+}
+
+// CreateScheduledTransferTx stores a transfer to be executed later.
+// AfterCreate runs inside the transaction, so the record is rolled back if the execution cannot be scheduled
+func (store *SQLStore) CreateScheduledTransferTx(ctx context.Context, arg CreateScheduledTransferTxParams) (CreateScheduledTransferTxResult, error) {
+	var result CreateScheduledTransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		if len(arg.Metadata) == 0 {
+			arg.Metadata = json.RawMessage(`{}`)
+		}
+		result.ScheduledTransfer, err = q.CreateScheduledTransfer(ctx, arg.CreateScheduledTransferParams)
+		if err != nil {
+			return err
+		}
+
+		if arg.IdempotencyKey != nil {
+			if err := saveIdempotencyKey(ctx, q, *arg.IdempotencyKey, result.ScheduledTransfer); err != nil {
+				return err
+			}
+		}
+
+		return arg.AfterCreate(result.ScheduledTransfer)
+	})
+
+	return result, err
+}
+
+type ExecuteScheduledTransferTxParams struct {
+	ScheduledTransferID int64 `json:"scheduled_transfer_id"`
+	// Limits are the default velocity limits of the owner, see checkVelocityLimits. Nil skips the check
+	Limits *TransferLimits `json:"-"`
+}
+
+type ExecuteScheduledTransferTxResult struct {
+	ScheduledTransfer ScheduledTransfer
+	TransferTxResult
+}
+
+// ExecuteScheduledTransferTx makes the transfer of a pending scheduled transfer and marks it completed.
+// It fails with ErrScheduledTransferNotPending if the schedule was already executed, failed or cancelled.
+// If the transfer is rejected nothing is written, and the caller is expected to record the failure
+func (store *SQLStore) ExecuteScheduledTransferTx(ctx context.Context, arg ExecuteScheduledTransferTxParams) (ExecuteScheduledTransferTxResult, error) {
+	var result ExecuteScheduledTransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		scheduledTransfer, err := q.GetScheduledTransferForUpdate(ctx, arg.ScheduledTransferID)
+		if err != nil {
+			return err
+		}
+		if scheduledTransfer.Status != ScheduledTransferStatusScheduled {
+			return fmt.Errorf("%w: scheduled transfer [%d] is %s", ErrScheduledTransferNotPending, scheduledTransfer.ID, scheduledTransfer.Status)
+		}
+
+		result.TransferTxResult, err = transfer(ctx, q, TransferTxParams{
+			FromAccountID:   scheduledTransfer.FromAccountID,
+			ToAccountID:     scheduledTransfer.ToAccountID,
+			Amount:          scheduledTransfer.Amount,
+			ToCurrency:      scheduledTransfer.ToCurrency,
+			Description:     scheduledTransfer.Description,
+			ClientReference: scheduledTransfer.ClientReference,
+			Metadata:        scheduledTransfer.Metadata,
+			Limits:          arg.Limits,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.ScheduledTransfer, err = q.UpdateScheduledTransfer(ctx, UpdateScheduledTransferParams{
+			ID:         scheduledTransfer.ID,
+			Status:     ScheduledTransferStatusCompleted,
+			TransferID: sql.NullInt64{Int64: result.Transfer.ID, Valid: true},
+		})
+		return err
+	})
+
+	return result, err
+}
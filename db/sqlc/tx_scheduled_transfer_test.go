@@ -0,0 +1,144 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func scheduleTestTransfer(t *testing.T, from, to Account, amount int64) ScheduledTransfer {
+	store := NewStore(testDB)
+
+	result, err := store.CreateScheduledTransferTx(context.Background(), CreateScheduledTransferTxParams{
+		CreateScheduledTransferParams: CreateScheduledTransferParams{
+			Owner:         from.Owner,
+			FromAccountID: from.ID,
+			ToAccountID:   to.ID,
+			Amount:        amount,
+			ToCurrency:    to.Currency,
+			ExecuteAt:     time.Now().Add(time.Hour),
+		},
+		AfterCreate: func(scheduledTransfer ScheduledTransfer) error {
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, ScheduledTransferStatusScheduled, result.ScheduledTransfer.Status)
+	return result.ScheduledTransfer
+}
+
+func TestExecuteScheduledTransferTx(t *testing.T) {
+	account1 := createFundedAccount(t, 100)
+	account2 := createRandomAccount(t)
+	for account2.Currency != account1.Currency {
+		account2 = createRandomAccount(t)
+	}
+
+	scheduledTransfer := scheduleTestTransfer(t, account1, account2, 100)
+
+	store := NewStore(testDB)
+	result, err := store.ExecuteScheduledTransferTx(context.Background(), ExecuteScheduledTransferTxParams{ScheduledTransferID: scheduledTransfer.ID})
+	require.NoError(t, err)
+	require.Equal(t, ScheduledTransferStatusCompleted, result.ScheduledTransfer.Status)
+	require.Equal(t, result.Transfer.ID, result.ScheduledTransfer.TransferID.Int64)
+	require.Equal(t, account1.Balance-100, result.FromAccount.Balance)
+
+	// a schedule is executed at most once
+	_, err = store.ExecuteScheduledTransferTx(context.Background(), ExecuteScheduledTransferTxParams{ScheduledTransferID: scheduledTransfer.ID})
+	require.ErrorIs(t, err, ErrScheduledTransferNotPending)
+}
+
+func TestExecuteScheduledTransferTxDetails(t *testing.T) {
+	account1 := createFundedAccount(t, 100)
+	account2 := createRandomAccount(t)
+	for account2.Currency != account1.Currency {
+		account2 = createRandomAccount(t)
+	}
+
+	store := NewStore(testDB)
+	created, err := store.CreateScheduledTransferTx(context.Background(), CreateScheduledTransferTxParams{
+		CreateScheduledTransferParams: CreateScheduledTransferParams{
+			Owner:           account1.Owner,
+			FromAccountID:   account1.ID,
+			ToAccountID:     account2.ID,
+			Amount:          10,
+			ToCurrency:      account2.Currency,
+			ExecuteAt:       time.Now().Add(time.Hour),
+			Description:     "rent for May",
+			ClientReference: "INV-42",
+			Metadata:        json.RawMessage(`{"category": "housing"}`),
+		},
+		AfterCreate: func(scheduledTransfer ScheduledTransfer) error {
+			return nil
+		},
+	})
+	require.NoError(t, err)
+
+	// the details are kept on the schedule and copied to the transfer it makes
+	result, err := store.ExecuteScheduledTransferTx(context.Background(), ExecuteScheduledTransferTxParams{ScheduledTransferID: created.ScheduledTransfer.ID})
+	require.NoError(t, err)
+	require.Equal(t, "rent for May", result.Transfer.Description)
+	require.Equal(t, "INV-42", result.Transfer.ClientReference)
+	require.JSONEq(t, `{"category": "housing"}`, string(result.Transfer.Metadata))
+}
+
+func TestExecuteScheduledTransferTxInsufficientFunds(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	for account2.Currency != account1.Currency {
+		account2 = createRandomAccount(t)
+	}
+
+	scheduledTransfer := scheduleTestTransfer(t, account1, account2, account1.Balance+1)
+
+	store := NewStore(testDB)
+	_, err := store.ExecuteScheduledTransferTx(context.Background(), ExecuteScheduledTransferTxParams{ScheduledTransferID: scheduledTransfer.ID})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+	require.True(t, IsRejected(err))
+
+	// nothing was written, so the schedule is still pending
+	stored, err := store.GetScheduledTransfer(context.Background(), scheduledTransfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, ScheduledTransferStatusScheduled, stored.Status)
+}
+
+func TestListOverdueScheduledTransfers(t *testing.T) {
+	account1 := createFundedAccount(t, 100)
+	account2 := createRandomAccount(t)
+	for account2.Currency != account1.Currency {
+		account2 = createRandomAccount(t)
+	}
+
+	scheduledTransfer := scheduleTestTransfer(t, account1, account2, 100)
+
+	store := NewStore(testDB)
+	overdue, err := store.ListOverdueScheduledTransfers(context.Background(), ListOverdueScheduledTransfersParams{
+		ExecuteBefore: scheduledTransfer.ExecuteAt,
+		MaxCount:      1000,
+	})
+	require.NoError(t, err)
+	require.NotContains(t, overdue, scheduledTransfer)
+
+	overdue, err = store.ListOverdueScheduledTransfers(context.Background(), ListOverdueScheduledTransfersParams{
+		ExecuteBefore: scheduledTransfer.ExecuteAt.Add(time.Second),
+		MaxCount:      1000,
+	})
+	require.NoError(t, err)
+	require.Contains(t, overdue, scheduledTransfer)
+
+	_, err = store.ExecuteScheduledTransferTx(context.Background(), ExecuteScheduledTransferTxParams{ScheduledTransferID: scheduledTransfer.ID})
+	require.NoError(t, err)
+
+	// only scheduled transfers are overdue
+	overdue, err = store.ListOverdueScheduledTransfers(context.Background(), ListOverdueScheduledTransfersParams{
+		ExecuteBefore: scheduledTransfer.ExecuteAt.Add(time.Second),
+		MaxCount:      1000,
+	})
+	require.NoError(t, err)
+	for _, other := range overdue {
+		require.NotEqual(t, scheduledTransfer.ID, other.ID)
+	}
+}
@@ -0,0 +1,135 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AutomaticOrca/simplebank/util"
+)
+
+const (
+	StandingOrderStatusActive    = "active"
+	StandingOrderStatusCancelled = "cancelled"
+
+	StandingOrderRunStatusCompleted = "completed"
+	StandingOrderRunStatusFailed    = "failed"
+)
+
+// RunStandingOrderTxParams identifies one occurrence of a standing order
+type RunStandingOrderTxParams struct {
+	StandingOrderID int64     `json:"standing_order_id"`
+	ScheduledFor    time.Time `json:"scheduled_for"`
+	// Limits are the default velocity limits of the owner, see checkVelocityLimits. Nil skips the check
+	Limits *TransferLimits `json:"-"`
+}
+
+type RunStandingOrderTxResult struct {
+	StandingOrder    StandingOrder    `json:"standing_order"`
+	StandingOrderRun StandingOrderRun `json:"standing_order_run"`
+	TransferTxResult
+}
+
+// RunStandingOrderTx makes the transfer for one occurrence of a standing order,
+// records the run and moves the order on to its next occurrence.
+// Every occurrence is run at most once: running it again fails with ErrStandingOrderRunExists.
+// If the transfer is rejected nothing is written, and the caller is expected to record the failure with FailStandingOrderRunTx
+func (store *SQLStore) RunStandingOrderTx(ctx context.Context, arg RunStandingOrderTxParams) (RunStandingOrderTxResult, error) {
+	var result RunStandingOrderTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		standingOrder, err := lockDueStandingOrder(ctx, q, arg)
+		if err != nil {
+			return err
+		}
+
+		result.TransferTxResult, err = transfer(ctx, q, TransferTxParams{
+			FromAccountID: standingOrder.FromAccountID,
+			ToAccountID:   standingOrder.ToAccountID,
+			Amount:        standingOrder.Amount,
+			ToCurrency:    standingOrder.ToCurrency,
+			Limits:        arg.Limits,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.StandingOrderRun, result.StandingOrder, err = recordStandingOrderRun(ctx, q, standingOrder, CreateStandingOrderRunParams{
+			StandingOrderID: standingOrder.ID,
+			ScheduledFor:    standingOrder.NextRunAt,
+			Status:          StandingOrderRunStatusCompleted,
+			TransferID:      sql.NullInt64{Int64: result.Transfer.ID, Valid: true},
+		})
+		return err
+	})
+
+	return result, err
+}
+
+type FailStandingOrderRunTxParams struct {
+	RunStandingOrderTxParams
+	FailureReason string `json:"failure_reason"`
+}
+
+// FailStandingOrderRunTx records an occurrence of a standing order that could not be run,
+// and moves the order on to its next occurrence
+func (store *SQLStore) FailStandingOrderRunTx(ctx context.Context, arg FailStandingOrderRunTxParams) (RunStandingOrderTxResult, error) {
+	var result RunStandingOrderTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		standingOrder, err := lockDueStandingOrder(ctx, q, arg.RunStandingOrderTxParams)
+		if err != nil {
+			return err
+		}
+
+		result.StandingOrderRun, result.StandingOrder, err = recordStandingOrderRun(ctx, q, standingOrder, CreateStandingOrderRunParams{
+			StandingOrderID: standingOrder.ID,
+			ScheduledFor:    standingOrder.NextRunAt,
+			Status:          StandingOrderRunStatusFailed,
+			FailureReason:   arg.FailureReason,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+func lockDueStandingOrder(ctx context.Context, q *Queries, arg RunStandingOrderTxParams) (StandingOrder, error) {
+	standingOrder, err := q.GetStandingOrderForUpdate(ctx, arg.StandingOrderID)
+	if err != nil {
+		return standingOrder, err
+	}
+	if standingOrder.Status != StandingOrderStatusActive {
+		return standingOrder, fmt.Errorf("%w: standing order [%d] is %s", ErrStandingOrderNotActive, standingOrder.ID, standingOrder.Status)
+	}
+	// next_run_at only moves forward, so an older occurrence has already been run
+	if !standingOrder.NextRunAt.Equal(arg.ScheduledFor) {
+		return standingOrder, fmt.Errorf("%w: standing order [%d] is next due at %s", ErrStandingOrderRunExists, standingOrder.ID, standingOrder.NextRunAt)
+	}
+	return standingOrder, nil
+}
+
+// recordStandingOrderRun stores the run of the current occurrence and advances next_run_at by one occurrence.
+// If the worker was down for a while, the next occurrence may already be due and is picked up by the next dispatch
+func recordStandingOrderRun(ctx context.Context, q *Queries, standingOrder StandingOrder, arg CreateStandingOrderRunParams) (StandingOrderRun, StandingOrder, error) {
+	run, err := q.CreateStandingOrderRun(ctx, arg)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = ErrStandingOrderRunExists
+		}
+		return run, standingOrder, err
+	}
+
+	nextRunAt, err := util.NextCronTime(standingOrder.Schedule, standingOrder.NextRunAt)
+	if err != nil {
+		return run, standingOrder, err
+	}
+
+	standingOrder, err = q.UpdateStandingOrderNextRun(ctx, UpdateStandingOrderNextRunParams{
+		ID:        standingOrder.ID,
+		NextRunAt: nextRunAt,
+	})
+	return run, standingOrder, err
+}
@@ -0,0 +1,162 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createTestStandingOrder(t *testing.T, from, to Account, amount int64, nextRunAt time.Time) StandingOrder {
+	standingOrder, err := testQueries.CreateStandingOrder(context.Background(), CreateStandingOrderParams{
+		Owner:         from.Owner,
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        amount,
+		ToCurrency:    to.Currency,
+		Schedule:      "0 9 1 * *",
+		NextRunAt:     nextRunAt,
+	})
+	require.NoError(t, err)
+	return standingOrder
+}
+
+func TestRunStandingOrderTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createFundedAccount(t, 100)
+	account2 := createRandomAccount(t)
+	for account2.Currency != account1.Currency {
+		account2 = createRandomAccount(t)
+	}
+
+	scheduledFor := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+	standingOrder := createTestStandingOrder(t, account1, account2, 10, scheduledFor)
+	arg := RunStandingOrderTxParams{
+		StandingOrderID: standingOrder.ID,
+		ScheduledFor:    standingOrder.NextRunAt,
+	}
+
+	result, err := store.RunStandingOrderTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, StandingOrderRunStatusCompleted, result.StandingOrderRun.Status)
+	require.Equal(t, result.Transfer.ID, result.StandingOrderRun.TransferID.Int64)
+	require.True(t, scheduledFor.Equal(result.StandingOrderRun.ScheduledFor))
+	require.True(t, result.StandingOrder.NextRunAt.Equal(time.Date(2024, time.February, 1, 9, 0, 0, 0, time.UTC)))
+
+	// the same occurrence is never run twice
+	_, err = store.RunStandingOrderTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrStandingOrderRunExists)
+
+	_, err = store.FailStandingOrderRunTx(context.Background(), FailStandingOrderRunTxParams{
+		RunStandingOrderTxParams: arg,
+		FailureReason:            "insufficient funds",
+	})
+	require.ErrorIs(t, err, ErrStandingOrderRunExists)
+
+	runs, err := store.ListStandingOrderRuns(context.Background(), ListStandingOrderRunsParams{
+		StandingOrderID: standingOrder.ID,
+		Limit:           5,
+	})
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+}
+
+func TestFailStandingOrderRunTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	for account2.Currency != account1.Currency {
+		account2 = createRandomAccount(t)
+	}
+
+	standingOrder := createTestStandingOrder(t, account1, account2, account1.Balance+1, time.Now().Add(-time.Minute))
+	arg := RunStandingOrderTxParams{
+		StandingOrderID: standingOrder.ID,
+		ScheduledFor:    standingOrder.NextRunAt,
+	}
+
+	_, err := store.RunStandingOrderTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	result, err := store.FailStandingOrderRunTx(context.Background(), FailStandingOrderRunTxParams{
+		RunStandingOrderTxParams: arg,
+		FailureReason:            err.Error(),
+	})
+	require.NoError(t, err)
+	require.Equal(t, StandingOrderRunStatusFailed, result.StandingOrderRun.Status)
+	require.NotEmpty(t, result.StandingOrderRun.FailureReason)
+	require.False(t, result.StandingOrderRun.TransferID.Valid)
+	require.True(t, result.StandingOrder.NextRunAt.After(standingOrder.NextRunAt))
+
+	// a cancelled order is not run any more
+	_, err = store.CancelStandingOrder(context.Background(), standingOrder.ID)
+	require.NoError(t, err)
+
+	_, err = store.RunStandingOrderTx(context.Background(), RunStandingOrderTxParams{
+		StandingOrderID: standingOrder.ID,
+		ScheduledFor:    result.StandingOrder.NextRunAt,
+	})
+	require.ErrorIs(t, err, ErrStandingOrderNotActive)
+}
+
+func TestRetryStandingOrderRun(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createFundedAccount(t, 100)
+	account2 := createRandomAccount(t)
+	for account2.Currency != account1.Currency {
+		account2 = createRandomAccount(t)
+	}
+
+	scheduledFor := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+	standingOrder := createTestStandingOrder(t, account1, account2, 10, scheduledFor)
+
+	retryAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	retried, err := store.RetryStandingOrderRun(context.Background(), RetryStandingOrderRunParams{
+		ID:           standingOrder.ID,
+		ScheduledFor: scheduledFor,
+		RetryAt:      retryAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(1), retried.FailedAttempts)
+	require.True(t, retryAt.Equal(retried.RetryAt.Time))
+	require.True(t, scheduledFor.Equal(retried.NextRunAt))
+
+	// the order is not due again before its retry
+	due, err := store.ListDueStandingOrders(context.Background(), ListDueStandingOrdersParams{
+		DueAt:    retryAt.Add(-time.Minute),
+		MaxCount: 1000,
+	})
+	require.NoError(t, err)
+	for _, other := range due {
+		require.NotEqual(t, standingOrder.ID, other.ID)
+	}
+
+	due, err = store.ListDueStandingOrders(context.Background(), ListDueStandingOrdersParams{
+		DueAt:    retryAt,
+		MaxCount: 1000,
+	})
+	require.NoError(t, err)
+	require.Contains(t, due, retried)
+
+	// running the occurrence clears the retry
+	result, err := store.RunStandingOrderTx(context.Background(), RunStandingOrderTxParams{
+		StandingOrderID: standingOrder.ID,
+		ScheduledFor:    scheduledFor,
+	})
+	require.NoError(t, err)
+	require.Zero(t, result.StandingOrder.FailedAttempts)
+	require.False(t, result.StandingOrder.RetryAt.Valid)
+
+	// a retry is only scheduled for the occurrence that failed
+	_, err = store.RetryStandingOrderRun(context.Background(), RetryStandingOrderRunParams{
+		ID:           standingOrder.ID,
+		ScheduledFor: scheduledFor,
+		RetryAt:      retryAt,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
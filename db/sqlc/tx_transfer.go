@@ -1,12 +1,33 @@
 package db
 
-import "context"
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/AutomaticOrca/simplebank/util"
+)
 
 // TransferTxParams contains the input parameters of the transfer transaction
 type TransferTxParams struct {
 	FromAccountID int64 `json:"from_account_id"`
 	ToAccountID   int64 `json:"to_account_id"`
 	Amount        int64 `json:"amount"`
+	// ToCurrency is the currency expected on the to account. When it differs from the from account's currency,
+	// the amount is converted at the current fx rate. Empty means the to account's currency
+	ToCurrency string `json:"to_currency"`
+	// Description, ClientReference and Metadata are stored on the transfer as given
+	Description     string          `json:"description"`
+	ClientReference string          `json:"client_reference"`
+	Metadata        json.RawMessage `json:"metadata"`
+	// Limits are the default velocity limits of the sender, see checkVelocityLimits. Nil skips the check
+	Limits *TransferLimits `json:"-"`
+	// IdempotencyKey is optional. When set, the result is stored under this key within the same transaction
+	IdempotencyKey *CreateIdempotencyKeyParams `json:"-"`
+	// IdempotentResponse builds the response stored under IdempotencyKey, when it should not be the whole result
+	IdempotentResponse func(result TransferTxResult) any `json:"-"`
 }
 
 // TransferTxResult is the result of the transfer transaction
@@ -16,68 +37,222 @@ type TransferTxResult struct {
 	ToAccount   Account  `json:"to_account"`
 	FromEntry   Entry    `json:"from_entry"`
 	ToEntry     Entry    `json:"to_entry"`
+	// Fee is charged to the from account on top of the amount, see quoteFee
+	Fee int64 `json:"fee"`
+	// FeeEntries debit the from account and credit the fee income account, none when there is no fee
+	FeeEntries []Entry `json:"fee_entries"`
 }
 
 // TransferTx performs a money transfer from one account to the other.
-// It creates the transfer, add account entries, and update accounts' balance within a database transaction
+// It creates the transfer and posts it as a journal (see PostJournalTx) within a database transaction.
+// The fee of the transfer, if its type has a fee schedule, is posted in the same journal.
+// It fails with ErrInsufficientFunds if the from account cannot cover the amount and fee within its overdraft limit,
+// and with a *VelocityLimitError if the sender would go past a limit
 func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
 	var result TransferTxResult
 
 	err := store.execTx(ctx, func(q *Queries) error {
 		var err error
+		result, err = transfer(ctx, q, arg)
+		return err
+	})
 
-		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams(arg))
-		if err != nil {
-			return err
+	return result, err
+}
+
+// transfer runs the steps of TransferTx within the caller's transaction
+func transfer(ctx context.Context, q *Queries, arg TransferTxParams) (TransferTxResult, error) {
+	fromAccount, toAccount, err := lockTransferAccounts(ctx, q, arg.FromAccountID, arg.ToAccountID)
+	if err != nil {
+		return TransferTxResult{}, err
+	}
+
+	result, err := transferLocked(ctx, q, fromAccount, toAccount, arg)
+	if err != nil {
+		return result, err
+	}
+
+	if arg.IdempotencyKey != nil {
+		var response any = result
+		if arg.IdempotentResponse != nil {
+			response = arg.IdempotentResponse(result)
 		}
+		err = saveIdempotencyKey(ctx, q, *arg.IdempotencyKey, response)
+	}
+	return result, err
+}
 
-		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.FromAccountID,
-			Amount:    -arg.Amount,
-		})
-		if err != nil {
-			return err
+// transferLocked prices and posts a transfer between two accounts already locked by the current transaction
+func transferLocked(ctx context.Context, q *Queries, fromAccount, toAccount Account, arg TransferTxParams) (TransferTxResult, error) {
+	if arg.Limits != nil {
+		if err := checkVelocityLimits(ctx, q, fromAccount, arg.Amount, *arg.Limits); err != nil {
+			return TransferTxResult{}, err
 		}
+	}
 
-		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.ToAccountID,
-			Amount:    arg.Amount,
-		})
+	fx, err := quoteFx(ctx, q, fromAccount, toAccount, arg.Amount, arg.ToCurrency)
+	if err != nil {
+		return TransferTxResult{}, err
+	}
+	fee, err := quoteFee(ctx, q, fromAccount, toAccount, arg.Amount)
+	if err != nil {
+		return TransferTxResult{}, err
+	}
+
+	return postTransfer(ctx, q, fromAccount, toAccount, CreateTransferParams{
+		Amount:          arg.Amount,
+		ToAmount:        fx.ToAmount,
+		FxRate:          fx.Rate,
+		FxSpreadBps:     fx.SpreadBps,
+		Description:     arg.Description,
+		ClientReference: arg.ClientReference,
+		Metadata:        arg.Metadata,
+	}, fee)
+}
+
+// saveIdempotencyKey stores the response under the idempotency key.
+// If an unexpired record with the same key already exists, the whole transaction is rolled back with ErrIdempotencyKeyExists
+func saveIdempotencyKey(ctx context.Context, q *Queries, arg CreateIdempotencyKeyParams, response any) error {
+	var err error
+	arg.Response, err = json.Marshal(response)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.CreateIdempotencyKey(ctx, arg)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrIdempotencyKeyExists
+	}
+	return err
+}
+
+// postTransfer records the transfer as a journal and moves the balances.
+// A cross currency transfer also goes through the fx position accounts of both currencies, so that the journal balances per currency.
+// A fee is added to the journal as two more legs, from the from account to the fee income account.
+// Both accounts must already be locked by the current transaction
+func postTransfer(ctx context.Context, q *Queries, fromAccount, toAccount Account, arg CreateTransferParams, fee transferFee) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	legs := []JournalLeg{
+		{AccountID: fromAccount.ID, Amount: -arg.Amount},
+		{AccountID: toAccount.ID, Amount: arg.ToAmount},
+	}
+	if fromAccount.Currency != toAccount.Currency {
+		fxFromID, err := systemAccountID(ctx, q, SystemAccountPurposeFxPosition, fromAccount.Currency)
 		if err != nil {
-			return err
+			return result, err
 		}
-
-		if arg.FromAccountID < arg.ToAccountID {
-			result.FromAccount, result.ToAccount, err = addMoney(ctx, q, arg.FromAccountID, -arg.Amount, arg.ToAccountID, arg.Amount)
-		} else {
-			result.ToAccount, result.FromAccount, err = addMoney(ctx, q, arg.ToAccountID, arg.Amount, arg.FromAccountID, -arg.Amount)
+		fxToID, err := systemAccountID(ctx, q, SystemAccountPurposeFxPosition, toAccount.Currency)
+		if err != nil {
+			return result, err
 		}
+		legs = append(legs,
+			JournalLeg{AccountID: fxFromID, Amount: arg.Amount},
+			JournalLeg{AccountID: fxToID, Amount: -arg.ToAmount},
+		)
+	}
+	if fee.Amount > 0 {
+		legs = append(legs,
+			JournalLeg{AccountID: fromAccount.ID, Amount: -fee.Amount},
+			JournalLeg{AccountID: fee.AccountID, Amount: fee.Amount},
+		)
+	}
 
-		return err
-	})
+	journal, err := postJournal(ctx, q, legs)
+	if err != nil {
+		return result, err
+	}
 
-	return result, err
+	arg.FromAccountID = fromAccount.ID
+	arg.ToAccountID = toAccount.ID
+	arg.JournalID = sql.NullInt64{Int64: journal.Journal.ID, Valid: true}
+	if len(arg.Metadata) == 0 {
+		arg.Metadata = json.RawMessage(`{}`)
+	}
+	result.Transfer, err = q.CreateTransfer(ctx, arg)
+	if err != nil {
+		return result, err
+	}
+
+	result.FromEntry = journal.Entries[0]
+	result.ToEntry = journal.Entries[1]
+	if fee.Amount > 0 {
+		result.Fee = fee.Amount
+		result.FeeEntries = journal.Entries[len(journal.Entries)-2:]
+	}
+	result.FromAccount = journal.account(fromAccount.ID)
+	result.ToAccount = journal.account(toAccount.ID)
+	return result, nil
 }
 
-func addMoney(
+// lockTransferAccounts locks both accounts of a transfer before their balances are read.
+// The account with the smaller ID is always locked first to avoid deadlock
+func lockTransferAccounts(
 	ctx context.Context,
 	q *Queries,
-	accountID1 int64,
-	amount1 int64,
-	accountID2 int64,
-	amount2 int64,
-) (account1 Account, account2 Account, err error) {
-	account1, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-		ID:     accountID1,
-		Amount: amount1,
-	})
+	fromAccountID int64,
+	toAccountID int64,
+) (fromAccount Account, toAccount Account, err error) {
+	if fromAccountID > toAccountID {
+		toAccount, fromAccount, err = lockTransferAccounts(ctx, q, toAccountID, fromAccountID)
+		return
+	}
+
+	fromAccount, err = q.GetAccountForUpdate(ctx, fromAccountID)
 	if err != nil {
 		return
 	}
 
-	account2, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-		ID:     accountID2,
-		Amount: amount2,
-	})
+	toAccount, err = q.GetAccountForUpdate(ctx, toAccountID)
 	return
 }
+
+// checkSufficientFunds makes sure the account can be debited by amount without going past its overdraft limit.
+// Money held by authorized holds is not available. The account must have been locked by the current transaction
+func checkSufficientFunds(account Account, amount int64) error {
+	if account.Balance-account.HeldAmount-amount < -account.OverdraftLimit {
+		return fmt.Errorf("%w: account [%d] has balance %d, held amount %d and overdraft limit %d, cannot send %d",
+			ErrInsufficientFunds, account.ID, account.Balance, account.HeldAmount, account.OverdraftLimit, amount)
+	}
+	return nil
+}
+
+// fxQuote is the conversion applied to the credited side of a transfer
+type fxQuote struct {
+	ToAmount  int64
+	Rate      string
+	SpreadBps int32
+}
+
+// quoteFx works out how much the to account receives when the from account sends amount.
+// Same currency transfers are credited one to one
+func quoteFx(ctx context.Context, q *Queries, fromAccount, toAccount Account, amount int64, toCurrency string) (fxQuote, error) {
+	if toCurrency != "" && toCurrency != toAccount.Currency {
+		return fxQuote{}, fmt.Errorf("%w: account [%d] holds %s, not %s", ErrCurrencyMismatch, toAccount.ID, toAccount.Currency, toCurrency)
+	}
+
+	if fromAccount.Currency == toAccount.Currency {
+		return fxQuote{ToAmount: amount, Rate: "1", SpreadBps: 0}, nil
+	}
+
+	rate, err := q.GetFxRate(ctx, GetFxRateParams{
+		BaseCurrency:  fromAccount.Currency,
+		QuoteCurrency: toAccount.Currency,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fxQuote{}, fmt.Errorf("%w: %s to %s", ErrFxRateNotFound, fromAccount.Currency, toAccount.Currency)
+		}
+		return fxQuote{}, err
+	}
+
+	toAmount, err := util.ConvertAmount(amount, rate.Rate, rate.SpreadBps)
+	if err != nil {
+		return fxQuote{}, err
+	}
+	if toAmount <= 0 {
+		return fxQuote{}, fmt.Errorf("%w: %d %s converts to nothing in %s", ErrInvalidAmount, amount, fromAccount.Currency, toAccount.Currency)
+	}
+
+	return fxQuote{ToAmount: toAmount, Rate: rate.Rate, SpreadBps: rate.SpreadBps}, nil
+}
@@ -0,0 +1,107 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	TransferLimitPeriodDaily   = "daily"
+	TransferLimitPeriodMonthly = "monthly"
+)
+
+// TransferLimits caps how much a user can send in one currency. Zero means no limit
+type TransferLimits struct {
+	Daily   int64 `json:"daily"`
+	Monthly int64 `json:"monthly"`
+}
+
+// VelocityLimitError tells which limit a transfer would go past, and how much can still be sent within it.
+// It unwraps to ErrVelocityLimitExceeded
+type VelocityLimitError struct {
+	Period    string `json:"period"`
+	Currency  string `json:"currency"`
+	Limit     int64  `json:"limit"`
+	Remaining int64  `json:"remaining"`
+}
+
+func (e *VelocityLimitError) Error() string {
+	return fmt.Sprintf("%s: %s limit of %d %s, %d remaining", ErrVelocityLimitExceeded, e.Period, e.Limit, e.Currency, e.Remaining)
+}
+
+func (e *VelocityLimitError) Unwrap() error {
+	return ErrVelocityLimitExceeded
+}
+
+// checkVelocityLimits makes sure the owner of the from account can still send amount today and this month.
+// Holds that are still authorized count as sent. The owner's limits in transfer_limits replace the defaults. The from account must be locked by the current
+// transaction; the advisory lock taken here then serializes all limit checks of the owner in that currency
+func checkVelocityLimits(ctx context.Context, q *Queries, fromAccount Account, amount int64, defaults TransferLimits) error {
+	err := q.LockTransferLimit(ctx, LockTransferLimitParams{
+		Owner:    fromAccount.Owner,
+		Currency: fromAccount.Currency,
+	})
+	if err != nil {
+		return err
+	}
+
+	limits := defaults
+	userLimit, err := q.GetTransferLimit(ctx, GetTransferLimitParams{
+		Username: fromAccount.Owner,
+		Currency: fromAccount.Currency,
+	})
+	switch {
+	case err == nil:
+		limits = TransferLimits{Daily: userLimit.DailyLimit, Monthly: userLimit.MonthlyLimit}
+	case !errors.Is(err, sql.ErrNoRows):
+		return err
+	}
+	if limits.Daily == 0 && limits.Monthly == 0 {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	sent, err := q.GetSentAmounts(ctx, GetSentAmountsParams{
+		DayStart:   dayStart,
+		Owner:      fromAccount.Owner,
+		Currency:   fromAccount.Currency,
+		MonthStart: monthStart,
+	})
+	if err != nil {
+		return err
+	}
+	held, err := q.GetAuthorizedHoldAmounts(ctx, GetAuthorizedHoldAmountsParams{
+		DayStart:   dayStart,
+		Owner:      fromAccount.Owner,
+		Currency:   fromAccount.Currency,
+		MonthStart: monthStart,
+	})
+	if err != nil {
+		return err
+	}
+	sent.DailyAmount += held.DailyAmount
+	sent.MonthlyAmount += held.MonthlyAmount
+
+	if limits.Daily > 0 && sent.DailyAmount+amount > limits.Daily {
+		return &VelocityLimitError{
+			Period:    TransferLimitPeriodDaily,
+			Currency:  fromAccount.Currency,
+			Limit:     limits.Daily,
+			Remaining: max(limits.Daily-sent.DailyAmount, 0),
+		}
+	}
+	if limits.Monthly > 0 && sent.MonthlyAmount+amount > limits.Monthly {
+		return &VelocityLimitError{
+			Period:    TransferLimitPeriodMonthly,
+			Currency:  fromAccount.Currency,
+			Limit:     limits.Monthly,
+			Remaining: max(limits.Monthly-sent.MonthlyAmount, 0),
+		}
+	}
+	return nil
+}
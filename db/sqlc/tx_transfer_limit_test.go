@@ -0,0 +1,191 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferTxDailyLimit(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createFundedAccount(t, 1000)
+	account2 := createRandomAccount(t)
+	for account2.Currency != account1.Currency {
+		account2 = createRandomAccount(t)
+	}
+
+	arg := TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        60,
+		Limits:        &TransferLimits{Daily: 100},
+	}
+	_, err := store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	arg.Amount = 50
+	_, err = store.TransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrVelocityLimitExceeded)
+
+	var limitErr *VelocityLimitError
+	require.True(t, errors.As(err, &limitErr))
+	require.Equal(t, TransferLimitPeriodDaily, limitErr.Period)
+	require.Equal(t, account1.Currency, limitErr.Currency)
+	require.Equal(t, int64(100), limitErr.Limit)
+	require.Equal(t, int64(40), limitErr.Remaining)
+
+	// the user's own limits replace the defaults
+	_, err = testQueries.UpsertTransferLimit(context.Background(), UpsertTransferLimitParams{
+		Username:     account1.Owner,
+		Currency:     account1.Currency,
+		DailyLimit:   0,
+		MonthlyLimit: 100,
+	})
+	require.NoError(t, err)
+
+	arg.Amount = 40
+	_, err = store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	arg.Amount = 1
+	_, err = store.TransferTx(context.Background(), arg)
+	require.True(t, errors.As(err, &limitErr))
+	require.Equal(t, TransferLimitPeriodMonthly, limitErr.Period)
+	require.Zero(t, limitErr.Remaining)
+}
+
+func TestTransferTxLimitConcurrent(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createFundedAccount(t, 1000)
+	account2 := createRandomAccount(t)
+	for account2.Currency != account1.Currency {
+		account2 = createRandomAccount(t)
+	}
+
+	n := 10
+	errs := make(chan error)
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := store.TransferTx(context.Background(), TransferTxParams{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        10,
+				Limits:        &TransferLimits{Daily: 50},
+			})
+			errs <- err
+		}()
+	}
+
+	// the limit is checked under lock, so exactly five transfers fit
+	succeeded := 0
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if err == nil {
+			succeeded++
+			continue
+		}
+		require.ErrorIs(t, err, ErrVelocityLimitExceeded)
+	}
+	require.Equal(t, 5, succeeded)
+}
+
+// spendTestLimit sends 60 of a daily limit of 100 from a new funded account, leaving 40
+func spendTestLimit(t *testing.T) (from, to Account, limits *TransferLimits) {
+	from = createFundedAccount(t, 1000)
+	to = createRandomAccount(t)
+	for to.Currency != from.Currency {
+		to = createRandomAccount(t)
+	}
+
+	limits = &TransferLimits{Daily: 100}
+	_, err := NewStore(testDB).TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        60,
+		Limits:        limits,
+	})
+	require.NoError(t, err)
+	return from, to, limits
+}
+
+func TestExecuteScheduledTransferTxLimit(t *testing.T) {
+	from, to, limits := spendTestLimit(t)
+	scheduledTransfer := scheduleTestTransfer(t, from, to, 50)
+
+	_, err := NewStore(testDB).ExecuteScheduledTransferTx(context.Background(), ExecuteScheduledTransferTxParams{
+		ScheduledTransferID: scheduledTransfer.ID,
+		Limits:              limits,
+	})
+	require.ErrorIs(t, err, ErrVelocityLimitExceeded)
+}
+
+func TestRunStandingOrderTxLimit(t *testing.T) {
+	from, to, limits := spendTestLimit(t)
+	standingOrder := createTestStandingOrder(t, from, to, 50, time.Now().Add(-time.Minute))
+
+	_, err := NewStore(testDB).RunStandingOrderTx(context.Background(), RunStandingOrderTxParams{
+		StandingOrderID: standingOrder.ID,
+		ScheduledFor:    standingOrder.NextRunAt,
+		Limits:          limits,
+	})
+	require.ErrorIs(t, err, ErrVelocityLimitExceeded)
+}
+
+func TestBatchTransferTxLimit(t *testing.T) {
+	from, to, limits := spendTestLimit(t)
+
+	// each leg fits on its own, but not after the one before it
+	leg := TransferTxParams{FromAccountID: from.ID, ToAccountID: to.ID, Amount: 30}
+	_, err := NewStore(testDB).BatchTransferTx(context.Background(), BatchTransferTxParams{
+		Legs:   []TransferTxParams{leg, leg},
+		Limits: limits,
+	})
+	require.ErrorIs(t, err, ErrVelocityLimitExceeded)
+
+	var legErr *BatchLegError
+	require.True(t, errors.As(err, &legErr))
+	require.Equal(t, 1, legErr.Leg)
+}
+
+func TestAuthorizeHoldTxLimit(t *testing.T) {
+	store := NewStore(testDB)
+	from, to, limits := spendTestLimit(t)
+
+	arg := AuthorizeHoldTxParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        50,
+		ToCurrency:    to.Currency,
+		ExpiresAt:     time.Now().Add(time.Hour),
+		Limits:        limits,
+	}
+	_, err := store.AuthorizeHoldTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrVelocityLimitExceeded)
+
+	// an authorized hold uses up the limit until it is released
+	arg.Amount = 40
+	result, err := store.AuthorizeHoldTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	transferArg := TransferTxParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        1,
+		Limits:        limits,
+	}
+	_, err = store.TransferTx(context.Background(), transferArg)
+	require.ErrorIs(t, err, ErrVelocityLimitExceeded)
+
+	// capturing it does not count it twice
+	_, err = store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{HoldID: result.Hold.ID, Amount: 30})
+	require.NoError(t, err)
+
+	transferArg.Amount = 10
+	_, err = store.TransferTx(context.Background(), transferArg)
+	require.NoError(t, err)
+}
@@ -0,0 +1,41 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+const (
+	TransferStatusPending   = "pending"
+	TransferStatusCompleted = "completed"
+	TransferStatusFailed    = "failed"
+	TransferStatusReversed  = "reversed"
+)
+
+// transferTransitions lists the statuses each transfer status can move to.
+// Failed and reversed are final
+var transferTransitions = map[string][]string{
+	TransferStatusPending:   {TransferStatusCompleted, TransferStatusFailed},
+	TransferStatusCompleted: {TransferStatusReversed},
+}
+
+// CanTransitionTransfer reports whether a transfer can move from one status to the other
+func CanTransitionTransfer(from, to string) bool {
+	return slices.Contains(transferTransitions[from], to)
+}
+
+// transitionTransfer checks and applies a status change to a transfer locked by the current transaction.
+// A status only changes together with the money, so it is only called by the transactions that post it,
+// such as ReverseTransferTx
+func transitionTransfer(ctx context.Context, q *Queries, transfer Transfer, status string, reason string) (Transfer, error) {
+	if !CanTransitionTransfer(transfer.Status, status) {
+		return transfer, fmt.Errorf("%w: transfer [%d] is %s, cannot become %s", ErrInvalidTransferTransition, transfer.ID, transfer.Status, status)
+	}
+
+	return q.UpdateTransferStatus(ctx, UpdateTransferStatusParams{
+		ID:           transfer.ID,
+		Status:       status,
+		StatusReason: reason,
+	})
+}
@@ -0,0 +1,61 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransitionTransfer(t *testing.T) {
+	store := NewStore(testDB).(*SQLStore)
+
+	account1 := createFundedAccount(t, 10)
+	account2 := createRandomAccount(t)
+	for account2.Currency != account1.Currency {
+		account2 = createRandomAccount(t)
+	}
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+	require.Equal(t, TransferStatusCompleted, result.Transfer.Status)
+
+	// a completed transfer cannot go back to pending or fail afterwards
+	for _, status := range []string{TransferStatusPending, TransferStatusFailed, TransferStatusCompleted} {
+		err = store.execTx(context.Background(), func(q *Queries) error {
+			_, err := transitionTransfer(context.Background(), q, result.Transfer, status, "")
+			return err
+		})
+		require.ErrorIs(t, err, ErrInvalidTransferTransition)
+	}
+
+	var transfer Transfer
+	err = store.execTx(context.Background(), func(q *Queries) error {
+		transfer, err = transitionTransfer(context.Background(), q, result.Transfer, TransferStatusReversed, "chargeback")
+		return err
+	})
+	require.NoError(t, err)
+	require.Equal(t, TransferStatusReversed, transfer.Status)
+	require.Equal(t, "chargeback", transfer.StatusReason)
+	require.False(t, transfer.UpdatedAt.Before(result.Transfer.UpdatedAt))
+
+	// reversed is final
+	err = store.execTx(context.Background(), func(q *Queries) error {
+		_, err := transitionTransfer(context.Background(), q, transfer, TransferStatusCompleted, "")
+		return err
+	})
+	require.ErrorIs(t, err, ErrInvalidTransferTransition)
+}
+
+func TestCanTransitionTransfer(t *testing.T) {
+	require.True(t, CanTransitionTransfer(TransferStatusPending, TransferStatusCompleted))
+	require.True(t, CanTransitionTransfer(TransferStatusPending, TransferStatusFailed))
+	require.True(t, CanTransitionTransfer(TransferStatusCompleted, TransferStatusReversed))
+	require.False(t, CanTransitionTransfer(TransferStatusPending, TransferStatusReversed))
+	require.False(t, CanTransitionTransfer(TransferStatusFailed, TransferStatusCompleted))
+	require.False(t, CanTransitionTransfer(TransferStatusReversed, TransferStatusCompleted))
+}
@@ -15,7 +15,9 @@ import (
 
 	"github.com/AutomaticOrca/simplebank/api"
 	db "github.com/AutomaticOrca/simplebank/db/sqlc"
+	"github.com/AutomaticOrca/simplebank/rails"
 	"github.com/AutomaticOrca/simplebank/util"
+	"github.com/AutomaticOrca/simplebank/worker"
 	"github.com/hibiken/asynq"
 	_ "github.com/lib/pq"
 	"golang.org/x/sync/errgroup"
@@ -71,10 +73,32 @@ func main() {
 	mailer := mail.NewGmailSender(config.EmailSenderName, config.EmailSenderAddress, config.EmailSenderPassword)
 	log.Info().Msg("Mailer initialized.")
 
+	redisOpt := asynq.RedisClientOpt{
+		Addr:     config.RedisAddress,
+		Password: config.RedisPassword,
+	}
+	taskDistributor := worker.NewRedisTaskDistributor(redisOpt)
+
+	// without a rail, top-ups and payouts are not offered
+	var rail rails.PaymentRail
+	if config.RailSimulatorEnabled {
+		rail = rails.NewSimulator(rails.SimulatorConfig{
+			Latency:     config.RailSimulatorLatency,
+			FailureRate: config.RailSimulatorFailureRate,
+		}, worker.NewRailCallback(taskDistributor))
+		log.Warn().Msg("Payment rail simulator enabled, top-ups settle without real money")
+	}
+
 	waitGroup, gCtx := errgroup.WithContext(ctx)
 
+	// Run background task processor
+	runTaskProcessorInGroup(gCtx, waitGroup, config, redisOpt, store, mailer, rail)
+
+	// Run periodic task scheduler (standing orders, hold expiry)
+	runTaskSchedulerInGroup(gCtx, waitGroup, redisOpt)
+
 	// Run Gin HTTP API Server
-	runGinAPIServerInGroup(gCtx, waitGroup, config, store, mailer)
+	runGinAPIServerInGroup(gCtx, waitGroup, config, store, mailer, taskDistributor)
 
 	log.Info().Msg("All components scheduled to run. Waiting for interrupt signal or component error...")
 	err = waitGroup.Wait() // Block until all goroutines in the group complete
@@ -95,8 +119,9 @@ func runGinAPIServerInGroup(
 	config util.Config,
 	store db.Store,
 	mailer mail.EmailSender,
+	taskDistributor worker.TaskDistributor,
 ) {
-	apiServer, err := api.NewServer(config, store, mailer)
+	apiServer, err := api.NewServer(config, store, mailer, taskDistributor)
 	if err != nil {
 		waitGroup.Go(func() error {
 			return fmt.Errorf("cannot create API server: %w", err)
@@ -116,3 +141,64 @@ func runGinAPIServerInGroup(
 		return nil
 	})
 }
+
+func runTaskProcessorInGroup(
+	gCtx context.Context,
+	waitGroup *errgroup.Group,
+	config util.Config,
+	redisOpt asynq.RedisClientOpt,
+	store db.Store,
+	mailer mail.EmailSender,
+	rail rails.PaymentRail,
+) {
+	taskProcessor := worker.NewRedisTaskProcessor(redisOpt, store, mailer, rail, config)
+
+	log.Info().Msg("Task processor starting...")
+	if err := taskProcessor.Start(); err != nil {
+		waitGroup.Go(func() error {
+			return fmt.Errorf("cannot start task processor: %w", err)
+		})
+		log.Error().Err(err).Msg("Failed to start task processor.")
+		return
+	}
+
+	waitGroup.Go(func() error {
+		<-gCtx.Done()
+		log.Info().Msg("Task processor: shutdown signal received, waiting for running tasks...")
+		taskProcessor.Shutdown()
+		log.Info().Msg("Task processor has stopped.")
+		return nil
+	})
+}
+
+func runTaskSchedulerInGroup(
+	gCtx context.Context,
+	waitGroup *errgroup.Group,
+	redisOpt asynq.RedisClientOpt,
+) {
+	taskScheduler, err := worker.NewRedisTaskScheduler(redisOpt)
+	if err != nil {
+		waitGroup.Go(func() error {
+			return fmt.Errorf("cannot create task scheduler: %w", err)
+		})
+		log.Error().Err(err).Msg("Failed to create task scheduler.")
+		return
+	}
+
+	log.Info().Msg("Task scheduler starting...")
+	if err := taskScheduler.Start(); err != nil {
+		waitGroup.Go(func() error {
+			return fmt.Errorf("cannot start task scheduler: %w", err)
+		})
+		log.Error().Err(err).Msg("Failed to start task scheduler.")
+		return
+	}
+
+	waitGroup.Go(func() error {
+		<-gCtx.Done()
+		log.Info().Msg("Task scheduler: shutdown signal received...")
+		taskScheduler.Shutdown()
+		log.Info().Msg("Task scheduler has stopped.")
+		return nil
+	})
+}
@@ -0,0 +1,77 @@
+// Package rails connects the bank to the external payment rails that move money in and out of it,
+// such as card acquirers or bank transfer networks.
+package rails
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Directions of a payment, seen from the bank
+const (
+	DirectionIn  = "in"
+	DirectionOut = "out"
+)
+
+// Outcomes of a payment reported to the Callback, or returned by Cancel
+const (
+	StatusSettled  = "settled"
+	StatusReturned = "returned"
+	// StatusCancelled is only returned by Cancel, the rail never reports it to the Callback
+	StatusCancelled = "cancelled"
+)
+
+var (
+	ErrInvalidPayment   = errors.New("invalid payment")
+	ErrPaymentCancelled = errors.New("payment was cancelled")
+)
+
+// Payment is a top-up (in) or payout (out) submitted to a rail
+type Payment struct {
+	// ID identifies the payment at the bank. Submitting the same ID twice must not move the money twice
+	ID        string `json:"id"`
+	Direction string `json:"direction"`
+	AccountID int64  `json:"account_id"`
+	Amount    int64  `json:"amount"`
+	Currency  string `json:"currency"`
+	Reference string `json:"reference"`
+}
+
+// Event is the outcome of a payment, reported by the rail some time after it was submitted
+type Event struct {
+	PaymentID string `json:"payment_id"`
+	// Reference is the one returned by Submit
+	Reference string `json:"reference"`
+	Status    string `json:"status"`
+	// Reason says why a returned payment failed
+	Reason string `json:"reason"`
+}
+
+// Callback is called by a rail with the outcome of each payment. An error asks the rail to deliver the event again later
+type Callback func(ctx context.Context, event Event) error
+
+// PaymentRail accepts payments, and reports their outcome asynchronously to the Callback it was created with
+type PaymentRail interface {
+	// Name identifies the rail in logs
+	Name() string
+	// Submit hands a payment to the rail and returns the rail's reference for it
+	Submit(ctx context.Context, payment Payment) (string, error)
+	// Cancel stops a payment the rail has not completed yet and returns its outcome. The outcome is StatusCancelled
+	// if the payment was stopped or never reached the rail, and the money is then never moved.
+	// Otherwise it is the outcome the rail already reached, which is reported to the Callback as well.
+	// Submitting a cancelled payment again fails with ErrPaymentCancelled
+	Cancel(ctx context.Context, paymentID string) (Event, error)
+}
+
+func validatePayment(payment Payment) error {
+	switch {
+	case payment.ID == "":
+		return fmt.Errorf("%w: missing id", ErrInvalidPayment)
+	case payment.Direction != DirectionIn && payment.Direction != DirectionOut:
+		return fmt.Errorf("%w: unknown direction %q", ErrInvalidPayment, payment.Direction)
+	case payment.Amount <= 0:
+		return fmt.Errorf("%w: amount must be positive", ErrInvalidPayment)
+	}
+	return nil
+}
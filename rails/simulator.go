@@ -0,0 +1,151 @@
+package rails
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// SimulatorConfig sets how the simulator behaves
+type SimulatorConfig struct {
+	// Latency is how long after submission the outcome of a payment is reported
+	Latency time.Duration
+	// FailureRate is the share of payments that are returned, from 0 (none) to 1 (all)
+	FailureRate float64
+	// MaxDeliveries is how many times an event is delivered while the callback fails. Zero means 5
+	MaxDeliveries int
+}
+
+// returnReasons are picked from at random for returned payments, after the ACH return codes
+var returnReasons = []string{
+	"R01 insufficient funds",
+	"R02 account closed",
+	"R03 no account",
+	"R16 account frozen",
+}
+
+// Simulator is a PaymentRail that runs in the process, for development and tests.
+// It settles or returns each payment at random after the configured latency
+type Simulator struct {
+	config   SimulatorConfig
+	callback Callback
+
+	mu         sync.Mutex
+	payments   map[string]*simulatedPayment
+	deliveries sync.WaitGroup
+}
+
+// simulatedPayment is a payment the simulator received or cancelled
+type simulatedPayment struct {
+	// event is the outcome of the payment, decided when it is submitted
+	event Event
+	// timer reports the outcome, it is nil for a payment cancelled before it was received
+	timer *time.Timer
+}
+
+// NewSimulator creates a simulated rail that reports outcomes to callback
+func NewSimulator(config SimulatorConfig, callback Callback) *Simulator {
+	if config.MaxDeliveries == 0 {
+		config.MaxDeliveries = 5
+	}
+	return &Simulator{
+		config:   config,
+		callback: callback,
+		payments: make(map[string]*simulatedPayment),
+	}
+}
+
+func (simulator *Simulator) Name() string {
+	return "simulator"
+}
+
+// Submit accepts a payment and schedules its outcome. A payment submitted again gets its first reference back,
+// and its outcome is only reported once. The simulator keeps its payments in memory, so they are lost on restart
+func (simulator *Simulator) Submit(ctx context.Context, payment Payment) (string, error) {
+	if err := validatePayment(payment); err != nil {
+		return "", err
+	}
+
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	if simulated, ok := simulator.payments[payment.ID]; ok {
+		if simulated.event.Status == StatusCancelled {
+			return "", ErrPaymentCancelled
+		}
+		return simulated.event.Reference, nil
+	}
+	reference := fmt.Sprintf("sim_%s", uuid.NewString())
+
+	event := Event{
+		PaymentID: payment.ID,
+		Reference: reference,
+		Status:    StatusSettled,
+	}
+	if rand.Float64() < simulator.config.FailureRate {
+		event.Status = StatusReturned
+		event.Reason = returnReasons[rand.Intn(len(returnReasons))]
+	}
+
+	simulator.deliveries.Add(1)
+	simulator.payments[payment.ID] = &simulatedPayment{
+		event: event,
+		timer: time.AfterFunc(simulator.config.Latency, func() {
+			simulator.deliver(event, 1)
+		}),
+	}
+	return reference, nil
+}
+
+// Cancel stops a payment whose outcome has not been reported yet. A payment the simulator does not know,
+// for instance because it restarted, is cancelled too, so that it cannot be submitted again
+func (simulator *Simulator) Cancel(ctx context.Context, paymentID string) (Event, error) {
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+
+	simulated, ok := simulator.payments[paymentID]
+	if !ok {
+		simulated = &simulatedPayment{event: Event{PaymentID: paymentID, Status: StatusCancelled}}
+		simulator.payments[paymentID] = simulated
+		return simulated.event, nil
+	}
+
+	if simulated.event.Status != StatusCancelled && simulated.timer.Stop() {
+		simulated.event.Status = StatusCancelled
+		simulated.event.Reason = ""
+		simulator.deliveries.Done()
+	}
+	return simulated.event, nil
+}
+
+// deliver reports the event to the callback, and tries again after the latency if the callback fails
+func (simulator *Simulator) deliver(event Event, attempt int) {
+	err := simulator.callback(context.Background(), event)
+	if err == nil {
+		simulator.deliveries.Done()
+		return
+	}
+
+	if attempt >= simulator.config.MaxDeliveries {
+		log.Error().Err(err).Str("payment_id", event.PaymentID).Str("reference", event.Reference).
+			Int("attempts", attempt).Msg("simulated rail gave up delivering event")
+		simulator.deliveries.Done()
+		return
+	}
+
+	log.Warn().Err(err).Str("payment_id", event.PaymentID).Str("reference", event.Reference).
+		Int("attempt", attempt).Msg("simulated rail will deliver event again")
+	time.AfterFunc(simulator.config.Latency, func() {
+		simulator.deliver(event, attempt+1)
+	})
+}
+
+// Wait blocks until the outcome of every submitted payment has been delivered, given up on or cancelled
+func (simulator *Simulator) Wait() {
+	simulator.deliveries.Wait()
+}
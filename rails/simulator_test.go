@@ -0,0 +1,151 @@
+package rails
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AutomaticOrca/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func randomPayment(id string) Payment {
+	return Payment{
+		ID:        id,
+		Direction: DirectionIn,
+		AccountID: util.RandomInt(1, 1000),
+		Amount:    util.RandomMoney() + 1,
+		Currency:  util.RandomCurrency(),
+	}
+}
+
+type recorder struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (r *recorder) callback(ctx context.Context, event Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+	return nil
+}
+
+func TestSimulatorSettles(t *testing.T) {
+	var r recorder
+	simulator := NewSimulator(SimulatorConfig{Latency: 20 * time.Millisecond}, r.callback)
+
+	submittedAt := time.Now()
+	reference, err := simulator.Submit(context.Background(), randomPayment("1"))
+	require.NoError(t, err)
+	require.NotEmpty(t, reference)
+
+	again, err := simulator.Submit(context.Background(), randomPayment("1"))
+	require.NoError(t, err)
+	require.Equal(t, reference, again)
+
+	simulator.Wait()
+	require.GreaterOrEqual(t, time.Since(submittedAt), 20*time.Millisecond)
+	require.Equal(t, []Event{{PaymentID: "1", Reference: reference, Status: StatusSettled}}, r.events)
+}
+
+func TestSimulatorReturns(t *testing.T) {
+	var r recorder
+	simulator := NewSimulator(SimulatorConfig{FailureRate: 1}, r.callback)
+
+	for _, id := range []string{"1", "2", "3"} {
+		_, err := simulator.Submit(context.Background(), randomPayment(id))
+		require.NoError(t, err)
+	}
+
+	simulator.Wait()
+	require.Len(t, r.events, 3)
+	for _, event := range r.events {
+		require.Equal(t, StatusReturned, event.Status)
+		require.Contains(t, returnReasons, event.Reason)
+	}
+}
+
+func TestSimulatorRedelivers(t *testing.T) {
+	var attempts int
+	var mu sync.Mutex
+	callback := func(ctx context.Context, event Event) error {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		if attempts < 3 {
+			return errors.New("not ready")
+		}
+		return nil
+	}
+	simulator := NewSimulator(SimulatorConfig{MaxDeliveries: 5}, callback)
+
+	_, err := simulator.Submit(context.Background(), randomPayment("1"))
+	require.NoError(t, err)
+
+	simulator.Wait()
+	require.Equal(t, 3, attempts)
+}
+
+func TestSimulatorInvalidPayment(t *testing.T) {
+	simulator := NewSimulator(SimulatorConfig{}, func(ctx context.Context, event Event) error {
+		t.Fatal("no event expected")
+		return nil
+	})
+
+	payment := randomPayment("")
+	_, err := simulator.Submit(context.Background(), payment)
+	require.ErrorIs(t, err, ErrInvalidPayment)
+
+	payment = randomPayment("1")
+	payment.Direction = "sideways"
+	_, err = simulator.Submit(context.Background(), payment)
+	require.ErrorIs(t, err, ErrInvalidPayment)
+
+	payment = randomPayment("1")
+	payment.Amount = 0
+	_, err = simulator.Submit(context.Background(), payment)
+	require.ErrorIs(t, err, ErrInvalidPayment)
+}
+
+func TestSimulatorCancel(t *testing.T) {
+	simulator := NewSimulator(SimulatorConfig{Latency: time.Hour}, func(ctx context.Context, event Event) error {
+		t.Fatal("no event expected")
+		return nil
+	})
+
+	reference, err := simulator.Submit(context.Background(), randomPayment("1"))
+	require.NoError(t, err)
+
+	event, err := simulator.Cancel(context.Background(), "1")
+	require.NoError(t, err)
+	require.Equal(t, Event{PaymentID: "1", Reference: reference, Status: StatusCancelled}, event)
+
+	// a payment the simulator never received, e.g. before a restart
+	event, err = simulator.Cancel(context.Background(), "2")
+	require.NoError(t, err)
+	require.Equal(t, Event{PaymentID: "2", Status: StatusCancelled}, event)
+
+	for _, id := range []string{"1", "2"} {
+		_, err = simulator.Submit(context.Background(), randomPayment(id))
+		require.ErrorIs(t, err, ErrPaymentCancelled)
+	}
+
+	simulator.Wait()
+}
+
+func TestSimulatorCancelCompleted(t *testing.T) {
+	var r recorder
+	simulator := NewSimulator(SimulatorConfig{}, r.callback)
+
+	reference, err := simulator.Submit(context.Background(), randomPayment("1"))
+	require.NoError(t, err)
+	simulator.Wait()
+
+	event, err := simulator.Cancel(context.Background(), "1")
+	require.NoError(t, err)
+	require.Equal(t, Event{PaymentID: "1", Reference: reference, Status: StatusSettled}, event)
+	require.Equal(t, []Event{event}, r.events)
+}
@@ -0,0 +1,151 @@
+package statement
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// camt053Writer writes an ISO 20022 bank to customer statement, camt.053.001.02
+type camt053Writer struct {
+	x      xmlWriter
+	header Header
+}
+
+func newCAMT053Writer(w io.Writer) *camt053Writer {
+	return &camt053Writer{x: xmlWriter{w: w}}
+}
+
+func camtTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05Z")
+}
+
+func creditDebit(amount int64) string {
+	if amount < 0 {
+		return "DBIT"
+	}
+	return "CRDT"
+}
+
+func (writer *camt053Writer) WriteHeader(header Header) error {
+	writer.header = header
+	x := &writer.x
+
+	id := fmt.Sprintf("STMT-%d-%s", header.AccountID, header.CreatedAt.UTC().Format("20060102150405"))
+
+	x.raw(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	x.raw(`<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.02">` + "\n")
+	x.depth++
+	x.open("BkToCstmrStmt")
+	x.open("GrpHdr")
+	x.element("MsgId", id)
+	x.element("CreDtTm", camtTime(header.CreatedAt))
+	x.close("GrpHdr")
+	x.open("Stmt")
+	x.element("Id", id)
+	x.element("CreDtTm", camtTime(header.CreatedAt))
+	x.open("FrToDt")
+	x.element("FrDtTm", camtTime(header.From))
+	x.element("ToDtTm", camtTime(header.To))
+	x.close("FrToDt")
+	x.open("Acct")
+	writer.accountID(header.AccountID)
+	x.element("Ccy", header.Currency)
+	x.close("Acct")
+	writer.balance("OPBD", header.OpeningBalance, header.From)
+	writer.balance("CLBD", header.ClosingBalance, header.To)
+	return x.err
+}
+
+func (writer *camt053Writer) accountID(accountID int64) {
+	x := &writer.x
+	x.open("Id")
+	x.open("Othr")
+	x.element("Id", strconv.FormatInt(accountID, 10))
+	x.close("Othr")
+	x.close("Id")
+}
+
+func (writer *camt053Writer) balance(code string, amount int64, at time.Time) {
+	x := &writer.x
+	x.open("Bal")
+	x.open("Tp")
+	x.open("CdOrPrtry")
+	x.element("Cd", code)
+	x.close("CdOrPrtry")
+	x.close("Tp")
+	x.elementAttr("Amt", "Ccy", writer.header.Currency, formatAmount(abs(amount)))
+	x.element("CdtDbtInd", creditDebit(amount))
+	x.open("Dt")
+	x.element("DtTm", camtTime(at))
+	x.close("Dt")
+	x.close("Bal")
+}
+
+func (writer *camt053Writer) WriteLine(line Line) error {
+	x := &writer.x
+
+	x.open("Ntry")
+	x.element("NtryRef", strconv.FormatInt(line.EntryID, 10))
+	x.elementAttr("Amt", "Ccy", writer.header.Currency, formatAmount(abs(line.Amount)))
+	x.element("CdtDbtInd", creditDebit(line.Amount))
+	x.element("Sts", "BOOK")
+	x.open("BookgDt")
+	x.element("DtTm", camtTime(line.CreatedAt))
+	x.close("BookgDt")
+	x.open("ValDt")
+	x.element("DtTm", camtTime(line.CreatedAt))
+	x.close("ValDt")
+	x.open("BkTxCd")
+	x.open("Prtry")
+	if line.TransferID != 0 {
+		x.element("Cd", "TRANSFER")
+	} else {
+		x.element("Cd", "ENTRY")
+	}
+	x.close("Prtry")
+	x.close("BkTxCd")
+	if line.TransferID != 0 {
+		writer.transactionDetails(line)
+	}
+	x.close("Ntry")
+	return x.err
+}
+
+func (writer *camt053Writer) transactionDetails(line Line) {
+	x := &writer.x
+	x.open("NtryDtls")
+	x.open("TxDtls")
+	x.open("Refs")
+	x.element("EndToEndId", strconv.FormatInt(line.TransferID, 10))
+	x.close("Refs")
+	if line.CounterpartyAccountID != 0 {
+		// money comes in from the debtor's account and goes out to the creditor's
+		party := "DbtrAcct"
+		if line.Amount < 0 {
+			party = "CdtrAcct"
+		}
+		x.open("RltdPties")
+		x.open(party)
+		writer.accountID(line.CounterpartyAccountID)
+		x.close(party)
+		x.close("RltdPties")
+	}
+	if line.Description != "" {
+		x.open("RmtInf")
+		x.element("Ustrd", line.Description)
+		x.close("RmtInf")
+	}
+	x.close("TxDtls")
+	x.close("NtryDtls")
+}
+
+func (writer *camt053Writer) Close() error {
+	x := &writer.x
+	x.close("Stmt")
+	x.close("BkToCstmrStmt")
+	x.depth--
+	x.raw("</Document>\n")
+	return x.err
+}
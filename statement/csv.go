@@ -0,0 +1,59 @@
+package statement
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type csvWriter struct {
+	w        *csv.Writer
+	currency string
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+func (writer *csvWriter) WriteHeader(header Header) error {
+	writer.currency = header.Currency
+	return writer.w.Write([]string{
+		"date", "entry_id", "transfer_id", "counterparty_account_id", "description", "amount", "balance", "currency",
+	})
+}
+
+func (writer *csvWriter) WriteLine(line Line) error {
+	return writer.w.Write([]string{
+		line.CreatedAt.UTC().Format(time.RFC3339),
+		strconv.FormatInt(line.EntryID, 10),
+		optionalID(line.TransferID),
+		optionalID(line.CounterpartyAccountID),
+		csvText(line.Description),
+		formatAmount(line.Amount),
+		formatAmount(line.Balance),
+		writer.currency,
+	})
+}
+
+func (writer *csvWriter) Close() error {
+	writer.w.Flush()
+	return writer.w.Error()
+}
+
+// csvText keeps free text from being run as a formula by a spreadsheet, by prefixing it with a quote
+// when it starts with a character that begins a formula
+func csvText(text string) string {
+	if text != "" && strings.ContainsRune("=+-@\t\r", rune(text[0])) {
+		return "'" + text
+	}
+	return text
+}
+
+func optionalID(id int64) string {
+	if id == 0 {
+		return ""
+	}
+	return strconv.FormatInt(id, 10)
+}
@@ -0,0 +1,100 @@
+package statement
+
+import (
+	"io"
+	"strconv"
+	"time"
+)
+
+// ofxTimeFormat is the OFX datetime with milliseconds and the UTC offset
+const ofxTimeFormat = "20060102150405.000[0:GMT]"
+
+// ofxWriter writes an OFX 2.2 bank statement response
+type ofxWriter struct {
+	x      xmlWriter
+	header Header
+}
+
+func newOFXWriter(w io.Writer) *ofxWriter {
+	return &ofxWriter{x: xmlWriter{w: w}}
+}
+
+func ofxTime(t time.Time) string {
+	return t.UTC().Format(ofxTimeFormat)
+}
+
+func (writer *ofxWriter) WriteHeader(header Header) error {
+	writer.header = header
+	x := &writer.x
+
+	x.raw(`<?xml version="1.0" encoding="UTF-8" standalone="no"?>` + "\n")
+	x.raw(`<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>` + "\n")
+	x.open("OFX")
+	x.open("SIGNONMSGSRSV1")
+	x.open("SONRS")
+	writer.status()
+	x.element("DTSERVER", ofxTime(header.CreatedAt))
+	x.element("LANGUAGE", "ENG")
+	x.close("SONRS")
+	x.close("SIGNONMSGSRSV1")
+	x.open("BANKMSGSRSV1")
+	x.open("STMTTRNRS")
+	x.element("TRNUID", "0")
+	writer.status()
+	x.open("STMTRS")
+	x.element("CURDEF", header.Currency)
+	x.open("BANKACCTFROM")
+	x.element("BANKID", "SIMPLEBANK")
+	x.element("ACCTID", strconv.FormatInt(header.AccountID, 10))
+	x.element("ACCTTYPE", "CHECKING")
+	x.close("BANKACCTFROM")
+	x.open("BANKTRANLIST")
+	x.element("DTSTART", ofxTime(header.From))
+	x.element("DTEND", ofxTime(header.To))
+	return x.err
+}
+
+func (writer *ofxWriter) status() {
+	x := &writer.x
+	x.open("STATUS")
+	x.element("CODE", "0")
+	x.element("SEVERITY", "INFO")
+	x.close("STATUS")
+}
+
+func (writer *ofxWriter) WriteLine(line Line) error {
+	x := &writer.x
+
+	trnType := "CREDIT"
+	if line.Amount < 0 {
+		trnType = "DEBIT"
+	}
+
+	x.open("STMTTRN")
+	x.element("TRNTYPE", trnType)
+	x.element("DTPOSTED", ofxTime(line.CreatedAt))
+	x.element("TRNAMT", formatAmount(line.Amount))
+	x.element("FITID", strconv.FormatInt(line.EntryID, 10))
+	if line.CounterpartyAccountID != 0 {
+		x.element("NAME", "Account "+strconv.FormatInt(line.CounterpartyAccountID, 10))
+	}
+	if line.Description != "" {
+		x.element("MEMO", line.Description)
+	}
+	x.close("STMTTRN")
+	return x.err
+}
+
+func (writer *ofxWriter) Close() error {
+	x := &writer.x
+	x.close("BANKTRANLIST")
+	x.open("LEDGERBAL")
+	x.element("BALAMT", formatAmount(writer.header.ClosingBalance))
+	x.element("DTASOF", ofxTime(writer.header.To))
+	x.close("LEDGERBAL")
+	x.close("STMTRS")
+	x.close("STMTTRNRS")
+	x.close("BANKMSGSRSV1")
+	x.close("OFX")
+	return x.err
+}
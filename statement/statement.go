@@ -0,0 +1,101 @@
+// Package statement writes account statements in formats that accounting tools can import.
+// Writers stream: the header goes out first and every line is written as soon as it is read
+package statement
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// Supported export formats
+const (
+	FormatCSV     = "csv"
+	FormatOFX     = "ofx"
+	FormatCAMT053 = "camt053"
+)
+
+// Header describes the statement period. Amounts are in minor units of Currency
+type Header struct {
+	AccountID      int64
+	Currency       string
+	From           time.Time
+	To             time.Time
+	OpeningBalance int64
+	ClosingBalance int64
+	CreatedAt      time.Time
+}
+
+// Line is one entry of the statement. TransferID and CounterpartyAccountID are zero
+// for entries that were not made by a transfer
+type Line struct {
+	EntryID               int64
+	CreatedAt             time.Time
+	Amount                int64
+	Balance               int64
+	TransferID            int64
+	CounterpartyAccountID int64
+	Description           string
+}
+
+// Writer writes a statement: WriteHeader once, then WriteLine for each line in order, then Close
+type Writer interface {
+	WriteHeader(header Header) error
+	WriteLine(line Line) error
+	// Close writes what comes after the lines. It does not close the underlying writer
+	Close() error
+}
+
+// NewWriter returns a Writer for the format that writes to w
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w), nil
+	case FormatOFX:
+		return newOFXWriter(w), nil
+	case FormatCAMT053:
+		return newCAMT053Writer(w), nil
+	}
+	return nil, fmt.Errorf("unsupported statement format %q", format)
+}
+
+// ContentType returns the MIME type of a format
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatOFX:
+		return "application/x-ofx"
+	case FormatCAMT053:
+		return "application/xml"
+	}
+	return "application/octet-stream"
+}
+
+// FileExtension returns the usual file extension of a format, without the dot
+func FileExtension(format string) string {
+	switch format {
+	case FormatOFX:
+		return "ofx"
+	case FormatCAMT053:
+		return "xml"
+	}
+	return "csv"
+}
+
+// formatAmount formats an amount in minor units with the two decimal places of all supported currencies
+func formatAmount(amount int64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, amount/100, amount%100)
+}
+
+func abs(amount int64) int64 {
+	if amount < 0 {
+		return -amount
+	}
+	return amount
+}
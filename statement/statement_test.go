@@ -0,0 +1,102 @@
+package statement
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+func testStatement() (Header, []Line) {
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	header := Header{
+		AccountID:      42,
+		Currency:       "EUR",
+		From:           from,
+		To:             from.AddDate(0, 1, 0),
+		OpeningBalance: 10050,
+		ClosingBalance: -1450,
+		CreatedAt:      time.Date(2024, 4, 2, 9, 30, 0, 0, time.UTC),
+	}
+	lines := []Line{
+		{
+			EntryID:               1001,
+			CreatedAt:             from.Add(36 * time.Hour),
+			Amount:                2500,
+			Balance:               12550,
+			TransferID:            501,
+			CounterpartyAccountID: 7,
+			Description:           `Invoice "March", rent & utilities`,
+		},
+		{
+			EntryID:               1002,
+			CreatedAt:             from.Add(240 * time.Hour),
+			Amount:                -14000,
+			Balance:               -1450,
+			TransferID:            502,
+			CounterpartyAccountID: 9,
+		},
+		{
+			EntryID:   1003,
+			CreatedAt: from.Add(300 * time.Hour),
+			Amount:    0,
+			Balance:   -1450,
+		},
+	}
+	return header, lines
+}
+
+func TestWriterGolden(t *testing.T) {
+	header, lines := testStatement()
+
+	for _, format := range []string{FormatCSV, FormatOFX, FormatCAMT053} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			writer, err := NewWriter(format, &buf)
+			require.NoError(t, err)
+
+			require.NoError(t, writer.WriteHeader(header))
+			for _, line := range lines {
+				require.NoError(t, writer.WriteLine(line))
+			}
+			require.NoError(t, writer.Close())
+
+			golden := filepath.Join("testdata", "statement."+format+".golden")
+			if *update {
+				err = os.WriteFile(golden, buf.Bytes(), 0o644)
+				require.NoError(t, err)
+			}
+
+			want, err := os.ReadFile(golden)
+			require.NoError(t, err)
+			require.Equal(t, string(want), buf.String())
+		})
+	}
+}
+
+func TestNewWriterUnsupportedFormat(t *testing.T) {
+	_, err := NewWriter("pdf", &bytes.Buffer{})
+	require.Error(t, err)
+}
+
+func TestFormatAmount(t *testing.T) {
+	require.Equal(t, "0.00", formatAmount(0))
+	require.Equal(t, "0.05", formatAmount(5))
+	require.Equal(t, "-0.05", formatAmount(-5))
+	require.Equal(t, "1234.50", formatAmount(123450))
+}
+
+func TestCSVText(t *testing.T) {
+	require.Equal(t, "", csvText(""))
+	require.Equal(t, "rent", csvText("rent"))
+	require.Equal(t, "'=HYPERLINK(\"http://evil\")", csvText(`=HYPERLINK("http://evil")`))
+	require.Equal(t, "'+1", csvText("+1"))
+	require.Equal(t, "'-1", csvText("-1"))
+	require.Equal(t, "'@SUM(A1)", csvText("@SUM(A1)"))
+}
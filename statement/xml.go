@@ -0,0 +1,58 @@
+package statement
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+// xmlWriter writes indented XML one element at a time, so documents can be streamed.
+// The first write error is kept and later writes are skipped
+type xmlWriter struct {
+	w     io.Writer
+	depth int
+	err   error
+}
+
+func (x *xmlWriter) raw(s string) {
+	if x.err != nil {
+		return
+	}
+	_, x.err = io.WriteString(x.w, s)
+}
+
+func (x *xmlWriter) indent() {
+	x.raw(strings.Repeat("  ", x.depth))
+}
+
+func (x *xmlWriter) open(tag string) {
+	x.indent()
+	x.raw("<" + tag + ">\n")
+	x.depth++
+}
+
+func (x *xmlWriter) close(tag string) {
+	x.depth--
+	x.indent()
+	x.raw("</" + tag + ">\n")
+}
+
+func (x *xmlWriter) element(tag, text string) {
+	x.elementAttr(tag, "", "", text)
+}
+
+func (x *xmlWriter) elementAttr(tag, attr, value, text string) {
+	x.indent()
+	x.raw("<" + tag)
+	if attr != "" {
+		x.raw(" " + attr + `="` + escapeXML(value) + `"`)
+	}
+	x.raw(">" + escapeXML(text) + "</" + tag + ">\n")
+}
+
+func escapeXML(s string) string {
+	var b strings.Builder
+	// writing to a strings.Builder never fails
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -4,6 +4,7 @@ import (
 	"errors" // 用于创建自定义错误
 	"fmt"    // 用于格式化错误消息
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/rs/zerolog/log"
@@ -12,19 +13,33 @@ import (
 )
 
 type Config struct {
-	Environment          string
-	DBDriver             string
-	DBSource             string
-	RedisAddress         string
-	RedisPassword        string
-	HTTPServerAddress    string
-	TokenSymmetricKey    string
-	AccessTokenDuration  time.Duration
-	RefreshTokenDuration time.Duration
-	EmailSenderName      string
-	EmailSenderAddress   string
-	EmailSenderPassword  string
-	FrontendBaseURL      string
+	Environment            string
+	DBDriver               string
+	DBSource               string
+	RedisAddress           string
+	RedisPassword          string
+	HTTPServerAddress      string
+	TokenSymmetricKey      string
+	AccessTokenDuration    time.Duration
+	RefreshTokenDuration   time.Duration
+	EmailSenderName        string
+	EmailSenderAddress     string
+	EmailSenderPassword    string
+	FrontendBaseURL        string
+	IdempotencyKeyDuration time.Duration
+	HoldDuration           time.Duration
+	PaymentRequestDuration time.Duration
+	// default velocity limits per user and currency, 0 means no limit
+	DailyTransferLimit   int64
+	MonthlyTransferLimit int64
+	// RailSimulatorEnabled turns on top-ups and payouts through the simulated payment rail.
+	// It is off by default: the simulator settles top-ups without any real money coming in
+	RailSimulatorEnabled bool
+	// how the simulated payment rail behaves, see rails.SimulatorConfig
+	RailSimulatorLatency     time.Duration
+	RailSimulatorFailureRate float64
+	// RailPaymentTimeout is how long a top-up or payout may stay pending before it is cancelled at the rail
+	RailPaymentTimeout time.Duration
 }
 
 func LoadConfig() (cfg Config, err error) {
@@ -74,6 +89,73 @@ func LoadConfig() (cfg Config, err error) {
 		return Config{}, fmt.Errorf("failed to parse REFRESH_TOKEN_DURATION: %w", err)
 	}
 
+	cfg.IdempotencyKeyDuration = 24 * time.Hour
+	if idempotencyKeyDurationStr := os.Getenv("IDEMPOTENCY_KEY_DURATION"); idempotencyKeyDurationStr != "" {
+		cfg.IdempotencyKeyDuration, err = time.ParseDuration(idempotencyKeyDurationStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("failed to parse IDEMPOTENCY_KEY_DURATION: %w", err)
+		}
+	}
+
+	cfg.HoldDuration = 7 * 24 * time.Hour
+	if holdDurationStr := os.Getenv("HOLD_DURATION"); holdDurationStr != "" {
+		cfg.HoldDuration, err = time.ParseDuration(holdDurationStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("failed to parse HOLD_DURATION: %w", err)
+		}
+	}
+
+	cfg.PaymentRequestDuration = 7 * 24 * time.Hour
+	if paymentRequestDurationStr := os.Getenv("PAYMENT_REQUEST_DURATION"); paymentRequestDurationStr != "" {
+		cfg.PaymentRequestDuration, err = time.ParseDuration(paymentRequestDurationStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("failed to parse PAYMENT_REQUEST_DURATION: %w", err)
+		}
+	}
+
+	if dailyTransferLimitStr := os.Getenv("DAILY_TRANSFER_LIMIT"); dailyTransferLimitStr != "" {
+		cfg.DailyTransferLimit, err = strconv.ParseInt(dailyTransferLimitStr, 10, 64)
+		if err != nil {
+			return Config{}, fmt.Errorf("failed to parse DAILY_TRANSFER_LIMIT: %w", err)
+		}
+	}
+	if monthlyTransferLimitStr := os.Getenv("MONTHLY_TRANSFER_LIMIT"); monthlyTransferLimitStr != "" {
+		cfg.MonthlyTransferLimit, err = strconv.ParseInt(monthlyTransferLimitStr, 10, 64)
+		if err != nil {
+			return Config{}, fmt.Errorf("failed to parse MONTHLY_TRANSFER_LIMIT: %w", err)
+		}
+	}
+
+	if railSimulatorEnabledStr := os.Getenv("RAIL_SIMULATOR_ENABLED"); railSimulatorEnabledStr != "" {
+		cfg.RailSimulatorEnabled, err = strconv.ParseBool(railSimulatorEnabledStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("failed to parse RAIL_SIMULATOR_ENABLED: %w", err)
+		}
+	}
+	cfg.RailSimulatorLatency = 5 * time.Second
+	if railSimulatorLatencyStr := os.Getenv("RAIL_SIMULATOR_LATENCY"); railSimulatorLatencyStr != "" {
+		cfg.RailSimulatorLatency, err = time.ParseDuration(railSimulatorLatencyStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("failed to parse RAIL_SIMULATOR_LATENCY: %w", err)
+		}
+	}
+	if railSimulatorFailureRateStr := os.Getenv("RAIL_SIMULATOR_FAILURE_RATE"); railSimulatorFailureRateStr != "" {
+		cfg.RailSimulatorFailureRate, err = strconv.ParseFloat(railSimulatorFailureRateStr, 64)
+		if err != nil {
+			return Config{}, fmt.Errorf("failed to parse RAIL_SIMULATOR_FAILURE_RATE: %w", err)
+		}
+		if cfg.RailSimulatorFailureRate < 0 || cfg.RailSimulatorFailureRate > 1 {
+			return Config{}, errors.New("RAIL_SIMULATOR_FAILURE_RATE must be between 0 and 1")
+		}
+	}
+	cfg.RailPaymentTimeout = 24 * time.Hour
+	if railPaymentTimeoutStr := os.Getenv("RAIL_PAYMENT_TIMEOUT"); railPaymentTimeoutStr != "" {
+		cfg.RailPaymentTimeout, err = time.ParseDuration(railPaymentTimeoutStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("failed to parse RAIL_PAYMENT_TIMEOUT: %w", err)
+		}
+	}
+
 	// --- 电子邮件相关配置检查 (示例，如果邮件功能是核心功能) ---
 	if cfg.EmailSenderAddress != "" { // 如果设置了发送地址，则认为邮件功能被启用
 		if cfg.EmailSenderName == "" {
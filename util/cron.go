@@ -0,0 +1,25 @@
+package util
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+var cronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// NextCronTime returns the first occurrence of a standard 5 field cron spec after the given time.
+// Specs are evaluated in UTC, so "0 9 1 * *" means 09:00 UTC on the first of every month
+func NextCronTime(spec string, after time.Time) (time.Time, error) {
+	schedule, err := cronParser.Parse(spec)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid cron spec %q: %w", spec, err)
+	}
+
+	next := schedule.Next(after.UTC())
+	if next.IsZero() {
+		return time.Time{}, fmt.Errorf("cron spec %q never occurs", spec)
+	}
+	return next, nil
+}
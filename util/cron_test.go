@@ -0,0 +1,38 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextCronTime(t *testing.T) {
+	after := time.Date(2024, time.January, 15, 10, 30, 0, 0, time.UTC)
+
+	testCases := []struct {
+		spec string
+		want time.Time
+	}{
+		{"0 9 1 * *", time.Date(2024, time.February, 1, 9, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, time.January, 15, 10, 45, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, time.January, 16, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 1", time.Date(2024, time.January, 22, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tc := range testCases {
+		next, err := NextCronTime(tc.spec, after)
+		require.NoError(t, err, tc.spec)
+		require.Equal(t, tc.want, next, tc.spec)
+	}
+
+	// the occurrence itself is not after itself
+	next, err := NextCronTime("0 9 1 * *", time.Date(2024, time.February, 1, 9, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC), next)
+
+	for _, spec := range []string{"", "every month", "0 9 1 *", "0 0 30 2 *", "0 0 0 * * *"} {
+		_, err := NextCronTime(spec, after)
+		require.Error(t, err, spec)
+	}
+}
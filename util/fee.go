@@ -0,0 +1,31 @@
+package util
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// TransferFee works out the fee on a transfer of amount: a flat fee plus a percentage of the amount
+// in basis points, kept between minFee and maxFee. The percentage is rounded up to the minor unit,
+// so rounding never works against the bank
+func TransferFee(amount, flatFee int64, percentageBps int32, minFee, maxFee int64) (int64, error) {
+	if amount <= 0 {
+		return 0, fmt.Errorf("invalid amount %d", amount)
+	}
+	if flatFee < 0 || percentageBps < 0 || percentageBps > 10000 || minFee < 0 || maxFee < minFee {
+		return 0, fmt.Errorf("invalid fee schedule: flat %d, %d bps, min %d, max %d", flatFee, percentageBps, minFee, maxFee)
+	}
+
+	percentage := new(big.Int).Mul(big.NewInt(amount), big.NewInt(int64(percentageBps)))
+	percentage.Add(percentage, big.NewInt(9999))
+	percentage.Quo(percentage, big.NewInt(10000))
+
+	fee := percentage.Add(percentage, big.NewInt(flatFee))
+	if fee.Cmp(big.NewInt(maxFee)) > 0 {
+		return maxFee, nil
+	}
+	if fee.Cmp(big.NewInt(minFee)) < 0 {
+		return minFee, nil
+	}
+	return fee.Int64(), nil
+}
@@ -0,0 +1,48 @@
+package util
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferFee(t *testing.T) {
+	// flat fee only
+	fee, err := TransferFee(10000, 50, 0, 0, math.MaxInt64)
+	require.NoError(t, err)
+	require.Equal(t, int64(50), fee)
+
+	// 1.5% of 100.00 plus 0.25
+	fee, err = TransferFee(10000, 25, 150, 0, math.MaxInt64)
+	require.NoError(t, err)
+	require.Equal(t, int64(175), fee)
+
+	// rounded up
+	fee, err = TransferFee(101, 0, 100, 0, math.MaxInt64)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), fee)
+
+	// kept between min and max
+	fee, err = TransferFee(100, 0, 100, 30, 500)
+	require.NoError(t, err)
+	require.Equal(t, int64(30), fee)
+
+	fee, err = TransferFee(1000000, 0, 100, 30, 500)
+	require.NoError(t, err)
+	require.Equal(t, int64(500), fee)
+
+	// no overflow on large amounts
+	fee, err = TransferFee(math.MaxInt64, 0, 10000, 0, math.MaxInt64)
+	require.NoError(t, err)
+	require.Equal(t, int64(math.MaxInt64), fee)
+
+	_, err = TransferFee(0, 50, 0, 0, math.MaxInt64)
+	require.Error(t, err)
+
+	_, err = TransferFee(100, 0, 10001, 0, math.MaxInt64)
+	require.Error(t, err)
+
+	_, err = TransferFee(100, 0, 100, 500, 30)
+	require.Error(t, err)
+}
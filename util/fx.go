@@ -0,0 +1,29 @@
+package util
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// ConvertAmount converts an amount in minor units at the given rate and takes the spread off the result.
+// The result is rounded down, so rounding never works against the bank.
+// All supported currencies have two decimal places, so minor units convert one to one
+func ConvertAmount(amount int64, rate string, spreadBps int32) (int64, error) {
+	r, ok := new(big.Rat).SetString(rate)
+	if !ok || r.Sign() <= 0 {
+		return 0, fmt.Errorf("invalid fx rate %q", rate)
+	}
+	if spreadBps < 0 || spreadBps >= 10000 {
+		return 0, fmt.Errorf("invalid fx spread %d bps", spreadBps)
+	}
+
+	converted := new(big.Rat).SetInt64(amount)
+	converted.Mul(converted, r)
+	converted.Mul(converted, big.NewRat(int64(10000-spreadBps), 10000))
+
+	result := new(big.Int).Quo(converted.Num(), converted.Denom())
+	if !result.IsInt64() {
+		return 0, fmt.Errorf("converted amount overflows: %s", result)
+	}
+	return result.Int64(), nil
+}
@@ -0,0 +1,32 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertAmount(t *testing.T) {
+	converted, err := ConvertAmount(10000, "0.9200000000", 0)
+	require.NoError(t, err)
+	require.Equal(t, int64(9200), converted)
+
+	// 1% spread
+	converted, err = ConvertAmount(10000, "0.92", 100)
+	require.NoError(t, err)
+	require.Equal(t, int64(9108), converted)
+
+	// rounded down
+	converted, err = ConvertAmount(1, "1.9999", 0)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), converted)
+
+	_, err = ConvertAmount(100, "abc", 0)
+	require.Error(t, err)
+
+	_, err = ConvertAmount(100, "-1", 0)
+	require.Error(t, err)
+
+	_, err = ConvertAmount(100, "1", 10000)
+	require.Error(t, err)
+}
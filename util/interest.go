@@ -0,0 +1,49 @@
+package util
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+)
+
+const (
+	// interestDaysInYear is the day count of interest: a day earns 1/365 of the annual rate, also in leap years
+	interestDaysInYear = 365
+	// interestScale is the number of decimal places of a minor unit daily interest is kept to
+	interestScale = 6
+)
+
+// DailyInterest returns the interest a balance in minor units earns in one day at an annual rate,
+// in minor units with six decimal places. Only positive balances earn interest
+func DailyInterest(balance int64, annualRate string) (string, error) {
+	r, ok := new(big.Rat).SetString(annualRate)
+	if !ok || r.Sign() < 0 {
+		return "", fmt.Errorf("invalid interest rate %q", annualRate)
+	}
+	if balance <= 0 {
+		return new(big.Rat).FloatString(interestScale), nil
+	}
+
+	interest := new(big.Rat).SetInt64(balance)
+	interest.Mul(interest, r)
+	interest.Quo(interest, big.NewRat(interestDaysInYear, 1))
+	return interest.FloatString(interestScale), nil
+}
+
+// RoundInterest rounds accrued interest to the minor unit of the currency, halves up.
+// All supported currencies have two decimal places, and amounts are kept in cents
+func RoundInterest(amount string, currency string) (int64, error) {
+	if !IsSupportedCurrency(currency) {
+		return 0, fmt.Errorf("unsupported currency %q", currency)
+	}
+	r, ok := new(big.Rat).SetString(amount)
+	if !ok {
+		return 0, fmt.Errorf("invalid interest amount %q", amount)
+	}
+
+	rounded, err := strconv.ParseInt(r.FloatString(0), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("interest amount overflows: %s", amount)
+	}
+	return rounded, nil
+}
@@ -0,0 +1,52 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDailyInterest(t *testing.T) {
+	// 1000.00 at 2% earns 20.00 a year
+	interest, err := DailyInterest(100000, "0.020000")
+	require.NoError(t, err)
+	require.Equal(t, "5.479452", interest)
+
+	interest, err = DailyInterest(1, "0.02")
+	require.NoError(t, err)
+	require.Equal(t, "0.000055", interest)
+
+	interest, err = DailyInterest(-100000, "0.02")
+	require.NoError(t, err)
+	require.Equal(t, "0.000000", interest)
+
+	interest, err = DailyInterest(100000, "0")
+	require.NoError(t, err)
+	require.Equal(t, "0.000000", interest)
+
+	_, err = DailyInterest(100000, "abc")
+	require.Error(t, err)
+
+	_, err = DailyInterest(100000, "-0.01")
+	require.Error(t, err)
+}
+
+func TestRoundInterest(t *testing.T) {
+	rounded, err := RoundInterest("169.863012", USD)
+	require.NoError(t, err)
+	require.Equal(t, int64(170), rounded)
+
+	rounded, err = RoundInterest("2.5", EUR)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), rounded)
+
+	rounded, err = RoundInterest("0.499999", CAD)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), rounded)
+
+	_, err = RoundInterest("1.5", "XYZ")
+	require.Error(t, err)
+
+	_, err = RoundInterest("abc", USD)
+	require.Error(t, err)
+}
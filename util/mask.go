@@ -0,0 +1,21 @@
+package util
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// MaskName hides a full name except for the initial of each word, e.g. "John Smith" becomes "J*** S***".
+// The masks have a fixed length so that they don't give away the length of the name
+func MaskName(fullName string) string {
+	words := strings.Fields(fullName)
+	if len(words) == 0 {
+		return "***"
+	}
+
+	for i, word := range words {
+		initial, _ := utf8.DecodeRuneInString(word)
+		words[i] = string(initial) + "***"
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,14 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaskName(t *testing.T) {
+	require.Equal(t, "J*** S***", MaskName("John Smith"))
+	require.Equal(t, "J*** S***", MaskName("  Jo   Smithson-Jones "))
+	require.Equal(t, "É***", MaskName("Émile"))
+	require.Equal(t, "***", MaskName(""))
+}
@@ -1,14 +1,16 @@
 package val
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/mail"
 	"regexp"
 )
 
 var (
-	isValidUsername = regexp.MustCompile(`^[a-z0-9_]+$`).MatchString
-	isValidFullName = regexp.MustCompile(`^[a-zA-Z\s]+$`).MatchString
+	isValidUsername        = regexp.MustCompile(`^[a-z0-9_]+$`).MatchString
+	isValidFullName        = regexp.MustCompile(`^[a-zA-Z\s]+$`).MatchString
+	isValidClientReference = regexp.MustCompile(`^[a-zA-Z0-9_.:/-]+$`).MatchString
 )
 
 func ValidateString(value string, minLength int, maxLength int) error {
@@ -63,3 +65,32 @@ func ValidateEmailId(value int64) error {
 func ValidateSecretCode(value string) error {
 	return ValidateString(value, 32, 128)
 }
+
+func ValidateIdempotencyKey(value string) error {
+	return ValidateString(value, 1, 255)
+}
+
+func ValidateClientReference(value string) error {
+	if err := ValidateString(value, 1, 64); err != nil {
+		return err
+	}
+	if !isValidClientReference(value) {
+		return fmt.Errorf("must contain only letters, digits, or any of _ . : / -")
+	}
+	return nil
+}
+
+func ValidateTransferDescription(value string) error {
+	return ValidateString(value, 0, 140)
+}
+
+func ValidateMetadata(value json.RawMessage) error {
+	if len(value) > 4096 {
+		return fmt.Errorf("must be at most 4096 bytes")
+	}
+	var object map[string]any
+	if err := json.Unmarshal(value, &object); err != nil || object == nil {
+		return fmt.Errorf("must be a JSON object")
+	}
+	return nil
+}
@@ -12,6 +12,26 @@ type TaskDistributor interface {
 		payload *PayloadSendVerifyEmail,
 		opts ...asynq.Option,
 	) error
+	DistributeTaskExecuteScheduledTransfer(
+		ctx context.Context,
+		payload *PayloadExecuteScheduledTransfer,
+		opts ...asynq.Option,
+	) error
+	DistributeTaskSendPaymentRequestNotification(
+		ctx context.Context,
+		payload *PayloadSendPaymentRequestNotification,
+		opts ...asynq.Option,
+	) error
+	DistributeTaskSubmitRailPayment(
+		ctx context.Context,
+		payload *PayloadSubmitRailPayment,
+		opts ...asynq.Option,
+	) error
+	DistributeTaskCompleteRailPayment(
+		ctx context.Context,
+		payload *PayloadCompleteRailPayment,
+		opts ...asynq.Option,
+	) error
 }
 
 type RedisTaskDistributor struct {
@@ -1,14 +1,14 @@
 // Code generated by MockGen. DO NOT EDIT.
 // Source: github.com/AutomaticOrca/simplebank/worker (interfaces: TaskDistributor)
 
-// Package mock_worker is a generated GoMock package.
+// Package mock is a generated GoMock package.
 package mock
 
 import (
 	context "context"
-	"github.com/AutomaticOrca/simplebank/worker"
 	reflect "reflect"
 
+	worker "github.com/AutomaticOrca/simplebank/worker"
 	gomock "github.com/golang/mock/gomock"
 	asynq "github.com/hibiken/asynq"
 )
@@ -36,6 +36,63 @@ func (m *MockTaskDistributor) EXPECT() *MockTaskDistributorMockRecorder {
 	return m.recorder
 }
 
+// DistributeTaskCompleteRailPayment mocks base method.
+func (m *MockTaskDistributor) DistributeTaskCompleteRailPayment(arg0 context.Context, arg1 *worker.PayloadCompleteRailPayment, arg2 ...asynq.Option) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DistributeTaskCompleteRailPayment", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DistributeTaskCompleteRailPayment indicates an expected call of DistributeTaskCompleteRailPayment.
+func (mr *MockTaskDistributorMockRecorder) DistributeTaskCompleteRailPayment(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DistributeTaskCompleteRailPayment", reflect.TypeOf((*MockTaskDistributor)(nil).DistributeTaskCompleteRailPayment), varargs...)
+}
+
+// DistributeTaskExecuteScheduledTransfer mocks base method.
+func (m *MockTaskDistributor) DistributeTaskExecuteScheduledTransfer(arg0 context.Context, arg1 *worker.PayloadExecuteScheduledTransfer, arg2 ...asynq.Option) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DistributeTaskExecuteScheduledTransfer", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DistributeTaskExecuteScheduledTransfer indicates an expected call of DistributeTaskExecuteScheduledTransfer.
+func (mr *MockTaskDistributorMockRecorder) DistributeTaskExecuteScheduledTransfer(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DistributeTaskExecuteScheduledTransfer", reflect.TypeOf((*MockTaskDistributor)(nil).DistributeTaskExecuteScheduledTransfer), varargs...)
+}
+
+// DistributeTaskSendPaymentRequestNotification mocks base method.
+func (m *MockTaskDistributor) DistributeTaskSendPaymentRequestNotification(arg0 context.Context, arg1 *worker.PayloadSendPaymentRequestNotification, arg2 ...asynq.Option) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DistributeTaskSendPaymentRequestNotification", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DistributeTaskSendPaymentRequestNotification indicates an expected call of DistributeTaskSendPaymentRequestNotification.
+func (mr *MockTaskDistributorMockRecorder) DistributeTaskSendPaymentRequestNotification(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DistributeTaskSendPaymentRequestNotification", reflect.TypeOf((*MockTaskDistributor)(nil).DistributeTaskSendPaymentRequestNotification), varargs...)
+}
+
 // DistributeTaskSendVerifyEmail mocks base method.
 func (m *MockTaskDistributor) DistributeTaskSendVerifyEmail(arg0 context.Context, arg1 *worker.PayloadSendVerifyEmail, arg2 ...asynq.Option) error {
 	m.ctrl.T.Helper()
@@ -54,3 +111,22 @@ func (mr *MockTaskDistributorMockRecorder) DistributeTaskSendVerifyEmail(arg0, a
 	varargs := append([]interface{}{arg0, arg1}, arg2...)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DistributeTaskSendVerifyEmail", reflect.TypeOf((*MockTaskDistributor)(nil).DistributeTaskSendVerifyEmail), varargs...)
 }
+
+// DistributeTaskSubmitRailPayment mocks base method.
+func (m *MockTaskDistributor) DistributeTaskSubmitRailPayment(arg0 context.Context, arg1 *worker.PayloadSubmitRailPayment, arg2 ...asynq.Option) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DistributeTaskSubmitRailPayment", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DistributeTaskSubmitRailPayment indicates an expected call of DistributeTaskSubmitRailPayment.
+func (mr *MockTaskDistributorMockRecorder) DistributeTaskSubmitRailPayment(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DistributeTaskSubmitRailPayment", reflect.TypeOf((*MockTaskDistributor)(nil).DistributeTaskSubmitRailPayment), varargs...)
+}
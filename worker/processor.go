@@ -6,6 +6,7 @@ import (
 
 	db "github.com/AutomaticOrca/simplebank/db/sqlc"
 	"github.com/AutomaticOrca/simplebank/mail"
+	"github.com/AutomaticOrca/simplebank/rails"
 	"github.com/AutomaticOrca/simplebank/util"
 	"github.com/go-redis/redis/v8"
 	"github.com/hibiken/asynq"
@@ -21,16 +22,29 @@ type TaskProcessor interface {
 	Start() error
 	Shutdown()
 	ProcessTaskSendVerifyEmail(ctx context.Context, task *asynq.Task) error
+	ProcessTaskExecuteScheduledTransfer(ctx context.Context, task *asynq.Task) error
+	ProcessTaskDispatchStandingOrders(ctx context.Context, task *asynq.Task) error
+	ProcessTaskExpireHolds(ctx context.Context, task *asynq.Task) error
+	ProcessTaskSendPaymentRequestNotification(ctx context.Context, task *asynq.Task) error
+	ProcessTaskExpirePaymentRequests(ctx context.Context, task *asynq.Task) error
+	ProcessTaskReconcileLedger(ctx context.Context, task *asynq.Task) error
+	ProcessTaskSnapshotBalances(ctx context.Context, task *asynq.Task) error
+	ProcessTaskAccrueInterest(ctx context.Context, task *asynq.Task) error
+	ProcessTaskSubmitRailPayment(ctx context.Context, task *asynq.Task) error
+	ProcessTaskCompleteRailPayment(ctx context.Context, task *asynq.Task) error
+	ProcessTaskSweepRailPayments(ctx context.Context, task *asynq.Task) error
+	ProcessTaskSweepScheduledTransfers(ctx context.Context, task *asynq.Task) error
 }
 
 type RedisTaskProcessor struct {
 	server *asynq.Server
 	store  db.Store
 	mailer mail.EmailSender
+	rail   rails.PaymentRail
 	config util.Config
 }
 
-func NewRedisTaskProcessor(redisOpt asynq.RedisClientOpt, store db.Store, mailer mail.EmailSender, config util.Config) TaskProcessor {
+func NewRedisTaskProcessor(redisOpt asynq.RedisClientOpt, store db.Store, mailer mail.EmailSender, rail rails.PaymentRail, config util.Config) TaskProcessor {
 	logger := NewLogger()
 	redis.SetLogger(logger)
 
@@ -55,6 +69,7 @@ func NewRedisTaskProcessor(redisOpt asynq.RedisClientOpt, store db.Store, mailer
 		server: server,
 		store:  store,
 		mailer: mailer,
+		rail:   rail,
 		config: config,
 	}
 }
@@ -63,6 +78,18 @@ func (processor *RedisTaskProcessor) Start() error {
 	mux := asynq.NewServeMux()
 
 	mux.HandleFunc(TaskSendVerifyEmail, processor.ProcessTaskSendVerifyEmail)
+	mux.HandleFunc(TaskExecuteScheduledTransfer, processor.ProcessTaskExecuteScheduledTransfer)
+	mux.HandleFunc(TaskDispatchStandingOrders, processor.ProcessTaskDispatchStandingOrders)
+	mux.HandleFunc(TaskExpireHolds, processor.ProcessTaskExpireHolds)
+	mux.HandleFunc(TaskSendPaymentRequestNotification, processor.ProcessTaskSendPaymentRequestNotification)
+	mux.HandleFunc(TaskExpirePaymentRequests, processor.ProcessTaskExpirePaymentRequests)
+	mux.HandleFunc(TaskReconcileLedger, processor.ProcessTaskReconcileLedger)
+	mux.HandleFunc(TaskSnapshotBalances, processor.ProcessTaskSnapshotBalances)
+	mux.HandleFunc(TaskAccrueInterest, processor.ProcessTaskAccrueInterest)
+	mux.HandleFunc(TaskSubmitRailPayment, processor.ProcessTaskSubmitRailPayment)
+	mux.HandleFunc(TaskCompleteRailPayment, processor.ProcessTaskCompleteRailPayment)
+	mux.HandleFunc(TaskSweepRailPayments, processor.ProcessTaskSweepRailPayments)
+	mux.HandleFunc(TaskSweepScheduledTransfers, processor.ProcessTaskSweepScheduledTransfers)
 
 	return processor.server.Start(mux)
 }
@@ -70,3 +97,12 @@ func (processor *RedisTaskProcessor) Start() error {
 func (processor *RedisTaskProcessor) Shutdown() {
 	processor.server.Shutdown()
 }
+
+// transferLimits are the default velocity limits, applied to the transfers the worker makes
+// just as the API applies them to the transfers it makes
+func (processor *RedisTaskProcessor) transferLimits() *db.TransferLimits {
+	return &db.TransferLimits{
+		Daily:   processor.config.DailyTransferLimit,
+		Monthly: processor.config.MonthlyTransferLimit,
+	}
+}
@@ -0,0 +1,67 @@
+package worker
+
+import (
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+// TaskScheduler enqueues the periodic tasks of the worker
+type TaskScheduler interface {
+	Start() error
+	Shutdown()
+}
+
+type RedisTaskScheduler struct {
+	scheduler *asynq.Scheduler
+}
+
+func NewRedisTaskScheduler(redisOpt asynq.RedisClientOpt) (TaskScheduler, error) {
+	scheduler := asynq.NewScheduler(redisOpt, &asynq.SchedulerOpts{
+		Location: time.UTC,
+		Logger:   NewLogger(),
+		EnqueueErrorHandler: func(task *asynq.Task, opts []asynq.Option, err error) {
+			log.Error().Err(err).Str("type", task.Type()).Msg("enqueue periodic task failed")
+		},
+	})
+
+	// every worker instance may run a scheduler, Unique keeps each task to one run per minute
+	periodicTasks := []struct {
+		cronspec string
+		taskType string
+	}{
+		{standingOrderDispatchSpec, TaskDispatchStandingOrders},
+		{expireHoldsSpec, TaskExpireHolds},
+		{expirePaymentRequestsSpec, TaskExpirePaymentRequests},
+		{reconcileLedgerSpec, TaskReconcileLedger},
+		{snapshotBalancesSpec, TaskSnapshotBalances},
+		{accrueInterestSpec, TaskAccrueInterest},
+		{sweepRailPaymentsSpec, TaskSweepRailPayments},
+		{sweepScheduledTransfersSpec, TaskSweepScheduledTransfers},
+	}
+	for _, periodicTask := range periodicTasks {
+		_, err := scheduler.Register(
+			periodicTask.cronspec,
+			asynq.NewTask(periodicTask.taskType, nil),
+			asynq.Queue(QueueCritical),
+			asynq.MaxRetry(0),
+			asynq.Unique(time.Minute),
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &RedisTaskScheduler{
+		scheduler: scheduler,
+	}, nil
+}
+
+func (scheduler *RedisTaskScheduler) Start() error {
+	return scheduler.scheduler.Start()
+}
+
+func (scheduler *RedisTaskScheduler) Shutdown() {
+	scheduler.scheduler.Shutdown()
+}
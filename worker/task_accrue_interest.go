@@ -0,0 +1,83 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	db "github.com/AutomaticOrca/simplebank/db/sqlc"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	TaskAccrueInterest = "task:accrue_interest"
+
+	// accrueInterestSpec runs just after midnight UTC, once the previous day's balances are final
+	accrueInterestSpec = "15 0 * * *"
+)
+
+// ProcessTaskAccrueInterest accrues the interest of every day since the last accruals up to yesterday,
+// then pays out the interest of the months that have ended. Both steps can be run again safely:
+// days and months already done are skipped
+func (processor *RedisTaskProcessor) ProcessTaskAccrueInterest(ctx context.Context, task *asynq.Task) error {
+	from, err := processor.store.GetInterestAccrualResumeDate(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get interest resume date: %w", err)
+	}
+
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	var accruals int64
+	for day := from; day.Before(today); day = day.AddDate(0, 0, 1) {
+		result, err := processor.store.AccrueInterestTx(ctx, day)
+		if err != nil {
+			return fmt.Errorf("failed to accrue interest for %s: %w", day.Format(time.DateOnly), err)
+		}
+		accruals += result.Accruals
+	}
+
+	// a month is paid out once it has ended
+	thisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	periods, err := processor.store.ListUnpostedInterestPeriods(ctx, thisMonth)
+	if err != nil {
+		return fmt.Errorf("failed to list unposted interest: %w", err)
+	}
+
+	for _, period := range periods {
+		processor.postInterest(ctx, period)
+	}
+
+	log.Info().Str("type", task.Type()).
+		Str("from", from.Format(time.DateOnly)).
+		Int64("accruals", accruals).
+		Int("postings", len(periods)).
+		Msg("processed task")
+	return nil
+}
+
+// postInterest pays out the interest of one account for one month.
+// Errors are only logged: the month stays unposted and is tried again by the next run
+func (processor *RedisTaskProcessor) postInterest(ctx context.Context, period db.ListUnpostedInterestPeriodsRow) {
+	result, err := processor.store.PostInterestTx(ctx, db.PostInterestTxParams{
+		AccountID: period.AccountID,
+		Period:    period.Period,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrInterestAlreadyPosted) {
+			return
+		}
+		log.Error().Err(err).Int64("account_id", period.AccountID).
+			Str("period", period.Period.Format("2006-01")).
+			Msg("failed to post interest")
+		return
+	}
+
+	log.Info().Int64("account_id", period.AccountID).
+		Str("period", period.Period.Format("2006-01")).
+		Int64("interest_posting_id", result.Posting.ID).
+		Int64("amount", result.Posting.Amount).
+		Msg("posted interest")
+}
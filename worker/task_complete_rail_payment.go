@@ -0,0 +1,140 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	db "github.com/AutomaticOrca/simplebank/db/sqlc"
+	"github.com/AutomaticOrca/simplebank/rails"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+const TaskCompleteRailPayment = "task:complete_rail_payment"
+
+type PayloadCompleteRailPayment struct {
+	CashMovementID int64 `json:"cash_movement_id"`
+	// Status is settled or returned
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+}
+
+func (distributor *RedisTaskDistributor) DistributeTaskCompleteRailPayment(
+	ctx context.Context,
+	payload *PayloadCompleteRailPayment,
+	opts ...asynq.Option,
+) error {
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+
+	defaultOpts := []asynq.Option{
+		asynq.MaxRetry(10),
+		asynq.Queue(QueueCritical),
+		asynq.Retention(24 * time.Hour),
+	}
+
+	finalOpts := append(defaultOpts, opts...)
+
+	task := asynq.NewTask(TaskCompleteRailPayment, jsonPayload, finalOpts...)
+	info, err := distributor.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+
+	log.Info().Str("type", task.Type()).Bytes("payload", task.Payload()).
+		Str("queue", info.Queue).Int("max_retry", info.MaxRetry).Msg("enqueued task")
+	return nil
+}
+
+// NewRailCallback returns the callback a payment rail reports outcomes to.
+// Each outcome is queued as a task, so that the rail is answered quickly and the ledger is updated with retries
+func NewRailCallback(distributor TaskDistributor) rails.Callback {
+	return func(ctx context.Context, event rails.Event) error {
+		cashMovementID, err := strconv.ParseInt(event.PaymentID, 10, 64)
+		if err != nil {
+			// the event is not for one of our payments, delivering it again won't help
+			log.Error().Str("payment_id", event.PaymentID).Str("reference", event.Reference).
+				Msg("ignored rail event for unknown payment")
+			return nil
+		}
+
+		return distributor.DistributeTaskCompleteRailPayment(ctx, &PayloadCompleteRailPayment{
+			CashMovementID: cashMovementID,
+			Status:         event.Status,
+			Reason:         event.Reason,
+		})
+	}
+}
+
+// ProcessTaskCompleteRailPayment records the outcome of a top-up or payout reported by the payment rail
+func (processor *RedisTaskProcessor) ProcessTaskCompleteRailPayment(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadCompleteRailPayment
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	status, ok := cashMovementStatus(payload.Status)
+	if !ok {
+		return fmt.Errorf("unknown rail payment status %q: %w", payload.Status, asynq.SkipRetry)
+	}
+
+	result, err := processor.store.CompleteRailPaymentTx(ctx, db.CompleteRailPaymentTxParams{
+		CashMovementID: payload.CashMovementID,
+		Status:         status,
+		ReturnReason:   payload.Reason,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return fmt.Errorf("cash movement doesn't exist: %w", asynq.SkipRetry)
+		case errors.Is(err, db.ErrCashMovementNotPending):
+			return processor.checkRecordedOutcome(ctx, task, payload.CashMovementID, status)
+		}
+		return fmt.Errorf("failed to complete rail payment: %w", err)
+	}
+
+	log.Info().Str("type", task.Type()).Bytes("payload", task.Payload()).
+		Str("status", result.CashMovement.Status).Int64("balance", result.Account.Balance).Msg("processed task")
+	return nil
+}
+
+// checkRecordedOutcome handles an outcome for a payment whose outcome was already recorded.
+// Usually the rail delivered the same outcome twice. An outcome that disagrees with the recorded one
+// means the ledger is wrong, for instance a payout given back to the account that the rail paid out after all,
+// so it is logged as an error for an operator to correct
+func (processor *RedisTaskProcessor) checkRecordedOutcome(ctx context.Context, task *asynq.Task, cashMovementID int64, status string) error {
+	cashMovement, err := processor.store.GetCashMovement(ctx, cashMovementID)
+	if err != nil {
+		return fmt.Errorf("failed to get cash movement: %w", err)
+	}
+
+	if cashMovement.Status != status {
+		log.Error().Str("type", task.Type()).Bytes("payload", task.Payload()).
+			Str("recorded_status", cashMovement.Status).Msg("rail reported an outcome that disagrees with the ledger")
+		return nil
+	}
+
+	log.Info().Str("type", task.Type()).Bytes("payload", task.Payload()).
+		Msg("skipped task: outcome already recorded")
+	return nil
+}
+
+// cashMovementStatus returns the status of a cash movement for the outcome of its rail payment
+func cashMovementStatus(railStatus string) (string, bool) {
+	switch railStatus {
+	case rails.StatusSettled:
+		return db.CashMovementStatusSettled, true
+	case rails.StatusReturned:
+		return db.CashMovementStatusReturned, true
+	case rails.StatusCancelled:
+		return db.CashMovementStatusFailed, true
+	}
+	return "", false
+}
@@ -0,0 +1,107 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	db "github.com/AutomaticOrca/simplebank/db/sqlc"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	TaskDispatchStandingOrders = "task:dispatch_standing_orders"
+
+	standingOrderDispatchSpec = "* * * * *"
+	// standingOrderDispatchBatch caps the standing orders run by one dispatch, the rest are picked up by the next one
+	standingOrderDispatchBatch = 100
+	// an occurrence that fails with an error that may go away is tried again after standingOrderRetryBackoff,
+	// doubled after each failure, and recorded as failed once it has been tried standingOrderMaxAttempts times
+	standingOrderRetryBackoff = time.Minute
+	standingOrderMaxAttempts  = 5
+)
+
+func (processor *RedisTaskProcessor) ProcessTaskDispatchStandingOrders(ctx context.Context, task *asynq.Task) error {
+	standingOrders, err := processor.store.ListDueStandingOrders(ctx, db.ListDueStandingOrdersParams{
+		DueAt:    time.Now(),
+		MaxCount: standingOrderDispatchBatch,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list due standing orders: %w", err)
+	}
+
+	for _, standingOrder := range standingOrders {
+		processor.runStandingOrder(ctx, standingOrder)
+	}
+
+	log.Info().Str("type", task.Type()).
+		Int("standing_orders", len(standingOrders)).Msg("processed task")
+	return nil
+}
+
+// runStandingOrder runs the current occurrence of a standing order.
+// Errors that may go away are only logged: the occurrence is tried again later, with a backoff so that orders
+// that keep failing do not hold up the others, until it has been tried standingOrderMaxAttempts times
+func (processor *RedisTaskProcessor) runStandingOrder(ctx context.Context, standingOrder db.StandingOrder) {
+	arg := db.RunStandingOrderTxParams{
+		StandingOrderID: standingOrder.ID,
+		ScheduledFor:    standingOrder.NextRunAt,
+		Limits:          processor.transferLimits(),
+	}
+
+	result, err := processor.store.RunStandingOrderTx(ctx, arg)
+	if err == nil {
+		log.Info().Int64("standing_order_id", standingOrder.ID).
+			Time("scheduled_for", arg.ScheduledFor).
+			Int64("transfer_id", result.Transfer.ID).Msg("ran standing order")
+		return
+	}
+
+	switch {
+	case errors.Is(err, db.ErrStandingOrderRunExists), errors.Is(err, db.ErrStandingOrderNotActive):
+		// run by a concurrent dispatch, or cancelled in the meantime
+		return
+	case db.IsRejected(err), errors.Is(err, sql.ErrNoRows):
+		processor.failStandingOrderRun(ctx, arg, err)
+		return
+	}
+
+	attempts := standingOrder.FailedAttempts + 1
+	log.Error().Err(err).Int64("standing_order_id", standingOrder.ID).
+		Time("scheduled_for", arg.ScheduledFor).Int32("attempts", attempts).Msg("failed to run standing order")
+	if attempts >= standingOrderMaxAttempts {
+		processor.failStandingOrderRun(ctx, arg, err)
+		return
+	}
+
+	_, retryErr := processor.store.RetryStandingOrderRun(ctx, db.RetryStandingOrderRunParams{
+		ID:           standingOrder.ID,
+		ScheduledFor: arg.ScheduledFor,
+		RetryAt:      time.Now().Add(standingOrderRetryBackoff << (attempts - 1)),
+	})
+	// run by a concurrent dispatch, or cancelled in the meantime
+	if retryErr != nil && !errors.Is(retryErr, sql.ErrNoRows) {
+		log.Error().Err(retryErr).Int64("standing_order_id", standingOrder.ID).
+			Msg("failed to schedule standing order retry")
+	}
+}
+
+// failStandingOrderRun records an occurrence of a standing order that could not be run, so that the order moves on
+func (processor *RedisTaskProcessor) failStandingOrderRun(ctx context.Context, arg db.RunStandingOrderTxParams, reason error) {
+	_, err := processor.store.FailStandingOrderRunTx(ctx, db.FailStandingOrderRunTxParams{
+		RunStandingOrderTxParams: arg,
+		FailureReason:            reason.Error(),
+	})
+	if err != nil && !errors.Is(err, db.ErrStandingOrderRunExists) && !errors.Is(err, db.ErrStandingOrderNotActive) {
+		log.Error().Err(err).Int64("standing_order_id", arg.StandingOrderID).
+			Msg("failed to record standing order failure")
+		return
+	}
+
+	log.Warn().Int64("standing_order_id", arg.StandingOrderID).
+		Time("scheduled_for", arg.ScheduledFor).
+		Str("reason", reason.Error()).Msg("standing order failed")
+}
@@ -0,0 +1,112 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	db "github.com/AutomaticOrca/simplebank/db/sqlc"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+const TaskExecuteScheduledTransfer = "task:execute_scheduled_transfer"
+
+type PayloadExecuteScheduledTransfer struct {
+	ScheduledTransferID int64 `json:"scheduled_transfer_id"`
+}
+
+func (distributor *RedisTaskDistributor) DistributeTaskExecuteScheduledTransfer(
+	ctx context.Context,
+	payload *PayloadExecuteScheduledTransfer,
+	opts ...asynq.Option,
+) error {
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+
+	defaultOpts := []asynq.Option{
+		asynq.MaxRetry(5),
+		asynq.Queue(QueueCritical),
+		asynq.Retention(24 * time.Hour),
+	}
+
+	finalOpts := append(defaultOpts, opts...)
+
+	task := asynq.NewTask(TaskExecuteScheduledTransfer, jsonPayload, finalOpts...)
+	info, err := distributor.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+
+	log.Info().Str("type", task.Type()).Bytes("payload", task.Payload()).
+		Str("queue", info.Queue).Int("max_retry", info.MaxRetry).
+		Time("process_at", info.NextProcessAt).Msg("enqueued task")
+	return nil
+}
+
+func (processor *RedisTaskProcessor) ProcessTaskExecuteScheduledTransfer(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadExecuteScheduledTransfer
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	result, err := processor.store.ExecuteScheduledTransferTx(ctx, db.ExecuteScheduledTransferTxParams{
+		ScheduledTransferID: payload.ScheduledTransferID,
+		Limits:              processor.transferLimits(),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows) && !isLastAttempt(ctx):
+			// the task is enqueued inside the transaction that creates the scheduled transfer,
+			// which may not be committed yet when execute_at is close
+			return fmt.Errorf("scheduled transfer doesn't exist yet: %w", err)
+		case errors.Is(err, db.ErrScheduledTransferNotPending):
+			// cancelled by the user, or already handled by an earlier attempt
+			log.Info().Str("type", task.Type()).Bytes("payload", task.Payload()).
+				Err(err).Msg("skipped task")
+			return nil
+		case db.IsRejected(err):
+			return processor.failScheduledTransfer(ctx, payload.ScheduledTransferID, err)
+		}
+
+		if isLastAttempt(ctx) {
+			return processor.failScheduledTransfer(ctx, payload.ScheduledTransferID, err)
+		}
+		return fmt.Errorf("failed to execute scheduled transfer: %w", err)
+	}
+
+	log.Info().Str("type", task.Type()).Bytes("payload", task.Payload()).
+		Int64("transfer_id", result.Transfer.ID).Msg("processed task")
+	return nil
+}
+
+// failScheduledTransfer records why a scheduled transfer could not be executed, so that it is never silently dropped
+func (processor *RedisTaskProcessor) failScheduledTransfer(ctx context.Context, scheduledTransferID int64, reason error) error {
+	_, err := processor.store.UpdateScheduledTransfer(ctx, db.UpdateScheduledTransferParams{
+		ID:            scheduledTransferID,
+		Status:        db.ScheduledTransferStatusFailed,
+		FailureReason: reason.Error(),
+	})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to record scheduled transfer failure: %w", err)
+	}
+
+	log.Warn().Int64("scheduled_transfer_id", scheduledTransferID).
+		Str("reason", reason.Error()).Msg("scheduled transfer failed")
+	return nil
+}
+
+// isLastAttempt reports whether the task will not be retried if the current attempt fails
+func isLastAttempt(ctx context.Context) bool {
+	retried, ok := asynq.GetRetryCount(ctx)
+	if !ok {
+		return false
+	}
+	maxRetry, ok := asynq.GetMaxRetry(ctx)
+	return ok && retried >= maxRetry
+}
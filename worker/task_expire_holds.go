@@ -0,0 +1,48 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	db "github.com/AutomaticOrca/simplebank/db/sqlc"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	TaskExpireHolds = "task:expire_holds"
+
+	expireHoldsSpec = "* * * * *"
+	// expireHoldsBatch caps the holds released by one run, the rest are picked up by the next one
+	expireHoldsBatch = 100
+)
+
+func (processor *RedisTaskProcessor) ProcessTaskExpireHolds(ctx context.Context, task *asynq.Task) error {
+	holds, err := processor.store.ListExpiredHolds(ctx, db.ListExpiredHoldsParams{
+		ExpiredAt: time.Now(),
+		MaxCount:  expireHoldsBatch,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list expired holds: %w", err)
+	}
+
+	expired := 0
+	for _, hold := range holds {
+		_, err := processor.store.ExpireHoldTx(ctx, hold.ID)
+		if err != nil {
+			// captured or voided in the meantime
+			if errors.Is(err, db.ErrHoldNotActive) {
+				continue
+			}
+			log.Error().Err(err).Int64("hold_id", hold.ID).Msg("failed to expire hold")
+			continue
+		}
+		expired++
+	}
+
+	log.Info().Str("type", task.Type()).
+		Int("holds", expired).Msg("processed task")
+	return nil
+}
@@ -0,0 +1,40 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	db "github.com/AutomaticOrca/simplebank/db/sqlc"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	TaskExpirePaymentRequests = "task:expire_payment_requests"
+
+	expirePaymentRequestsSpec = "*/5 * * * *"
+	// expirePaymentRequestsBatch caps the requests expired by one run, the rest are picked up by the next one
+	expirePaymentRequestsBatch = 100
+)
+
+func (processor *RedisTaskProcessor) ProcessTaskExpirePaymentRequests(ctx context.Context, task *asynq.Task) error {
+	paymentRequests, err := processor.store.ExpirePaymentRequests(ctx, db.ExpirePaymentRequestsParams{
+		ExpiredAt: time.Now(),
+		MaxCount:  expirePaymentRequestsBatch,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to expire payment requests: %w", err)
+	}
+
+	// the requests are expired already, so a failed email is logged rather than retried
+	for _, paymentRequest := range paymentRequests {
+		if err := processor.notifyPaymentRequest(ctx, paymentRequest); err != nil {
+			log.Error().Err(err).Int64("payment_request_id", paymentRequest.ID).Msg("failed to notify expired payment request")
+		}
+	}
+
+	log.Info().Str("type", task.Type()).
+		Int("payment_requests", len(paymentRequests)).Msg("processed task")
+	return nil
+}
@@ -0,0 +1,49 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	TaskReconcileLedger = "task:reconcile_ledger"
+
+	// reconcileLedgerSpec runs the reconciliation every night, when the ledger is quiet
+	reconcileLedgerSpec = "0 3 * * *"
+)
+
+func (processor *RedisTaskProcessor) ProcessTaskReconcileLedger(ctx context.Context, task *asynq.Task) error {
+	result, err := processor.store.ReconcileTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to reconcile ledger: %w", err)
+	}
+
+	// discrepancies need a person to look at them, running again would not fix them
+	for _, drift := range result.Report.BalanceDrifts {
+		log.Error().Int64("reconciliation_run_id", result.Run.ID).
+			Int64("account_id", drift.AccountID).
+			Int64("balance", drift.Balance).
+			Int64("entry_sum", drift.EntrySum).
+			Msg("account balance drifted from its entries")
+	}
+	for _, transfer := range result.Report.UnbalancedTransfers {
+		log.Error().Int64("reconciliation_run_id", result.Run.ID).
+			Int64("transfer_id", transfer.TransferID).
+			Str("currency", transfer.Currency).
+			Int64("net_amount", transfer.NetAmount).
+			Msg("transfer entries do not net to zero")
+	}
+
+	log.Info().Str("type", task.Type()).
+		Int64("reconciliation_run_id", result.Run.ID).
+		Str("status", result.Run.Status).
+		Int64("accounts_checked", result.Run.AccountsChecked).
+		Int64("transfers_checked", result.Run.TransfersChecked).
+		Int64("transfers_unchecked", result.Report.UncheckedTransfers).
+		Int64("discrepancies", result.Run.DiscrepancyCount).
+		Msg("processed task")
+	return nil
+}
@@ -0,0 +1,151 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"time"
+
+	db "github.com/AutomaticOrca/simplebank/db/sqlc"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+const TaskSendPaymentRequestNotification = "task:send_payment_request_notification"
+
+type PayloadSendPaymentRequestNotification struct {
+	PaymentRequestID int64 `json:"payment_request_id"`
+	// Status is the status to tell the users about, the request may have moved on by the time the task runs
+	Status string `json:"status"`
+}
+
+func (distributor *RedisTaskDistributor) DistributeTaskSendPaymentRequestNotification(
+	ctx context.Context,
+	payload *PayloadSendPaymentRequestNotification,
+	opts ...asynq.Option,
+) error {
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+
+	defaultOpts := []asynq.Option{
+		asynq.MaxRetry(3),
+		asynq.Retention(24 * time.Hour),
+	}
+
+	finalOpts := append(defaultOpts, opts...)
+
+	task := asynq.NewTask(TaskSendPaymentRequestNotification, jsonPayload, finalOpts...)
+	info, err := distributor.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+
+	log.Info().Str("type", task.Type()).Bytes("payload", task.Payload()).
+		Str("queue", info.Queue).Int("max_retry", info.MaxRetry).Msg("enqueued task")
+	return nil
+}
+
+// ProcessTaskSendPaymentRequestNotification tells the users of a payment request about its current status
+func (processor *RedisTaskProcessor) ProcessTaskSendPaymentRequestNotification(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadSendPaymentRequestNotification
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	paymentRequest, err := processor.store.GetPaymentRequest(ctx, payload.PaymentRequestID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// the task is enqueued inside the transaction that creates the request, which may not be committed yet.
+			// If it is still missing on the last attempt, the transaction was rolled back
+			if isLastAttempt(ctx) {
+				return fmt.Errorf("payment request doesn't exist: %w", asynq.SkipRetry)
+			}
+			return fmt.Errorf("payment request doesn't exist yet: %w", err)
+		}
+		return fmt.Errorf("failed to get payment request: %w", err)
+	}
+	paymentRequest.Status = payload.Status
+
+	if err := processor.notifyPaymentRequest(ctx, paymentRequest); err != nil {
+		return err
+	}
+
+	log.Info().Str("type", task.Type()).Bytes("payload", task.Payload()).
+		Str("status", paymentRequest.Status).Msg("processed task")
+	return nil
+}
+
+// notifyPaymentRequest emails the side of a payment request that has to know about its status:
+// the payer when it is created, the requester when it is answered, and both when it expires
+func (processor *RedisTaskProcessor) notifyPaymentRequest(ctx context.Context, paymentRequest db.PaymentRequest) error {
+	requester, err := processor.store.GetUser(ctx, paymentRequest.Requester)
+	if err != nil {
+		return fmt.Errorf("failed to get requester: %w", err)
+	}
+	payer, err := processor.store.GetUser(ctx, paymentRequest.Payer)
+	if err != nil {
+		return fmt.Errorf("failed to get payer: %w", err)
+	}
+
+	// names and the description are chosen by the users, so they are escaped before going into the HTML
+	requesterName := html.EscapeString(requester.FullName)
+	payerName := html.EscapeString(payer.FullName)
+	description := html.EscapeString(paymentRequest.Description)
+	amount := fmt.Sprintf("%d %s", paymentRequest.Amount, paymentRequest.Currency)
+	type notification struct {
+		to      db.User
+		subject string
+		content string
+	}
+	var notifications []notification
+
+	switch paymentRequest.Status {
+	case db.PaymentRequestStatusPending:
+		notifications = append(notifications, notification{
+			to:      payer,
+			subject: "New payment request",
+			content: fmt.Sprintf(`Hello %s,<br/>
+	%s asks you to pay %s: %s<br/>
+	The request expires at %s.<br/>
+	`, payerName, requesterName, amount, description, paymentRequest.ExpiresAt.UTC().Format(time.RFC1123)),
+		})
+	case db.PaymentRequestStatusAccepted, db.PaymentRequestStatusDeclined:
+		notifications = append(notifications, notification{
+			to:      requester,
+			subject: "Your payment request was " + paymentRequest.Status,
+			content: fmt.Sprintf(`Hello %s,<br/>
+	%s has %s your request for %s.<br/>
+	`, requesterName, payerName, paymentRequest.Status, amount),
+		})
+	case db.PaymentRequestStatusExpired:
+		notifications = append(notifications,
+			notification{
+				to:      requester,
+				subject: "Your payment request expired",
+				content: fmt.Sprintf(`Hello %s,<br/>
+	Your request to %s for %s expired without an answer.<br/>
+	`, requesterName, payerName, amount),
+			},
+			notification{
+				to:      payer,
+				subject: "A payment request expired",
+				content: fmt.Sprintf(`Hello %s,<br/>
+	The request from %s for %s has expired and can no longer be paid.<br/>
+	`, payerName, requesterName, amount),
+			},
+		)
+	}
+
+	for _, n := range notifications {
+		err := processor.mailer.SendEmail(n.subject, n.content, []string{n.to.Email}, nil, nil, nil)
+		if err != nil {
+			return fmt.Errorf("failed to send payment request email: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	db "github.com/AutomaticOrca/simplebank/db/sqlc"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	TaskSnapshotBalances = "task:snapshot_balances"
+
+	// snapshotBalancesSpec runs just after midnight UTC, once the previous day is closed
+	snapshotBalancesSpec = "5 0 * * *"
+)
+
+// ProcessTaskSnapshotBalances takes the end of day balances of every day since the last snapshot
+// up to yesterday, so days missed while the worker was down are caught up
+func (processor *RedisTaskProcessor) ProcessTaskSnapshotBalances(ctx context.Context, task *asynq.Task) error {
+	from, err := processor.store.GetBalanceSnapshotResumeDate(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get snapshot resume date: %w", err)
+	}
+
+	result, err := processor.store.SnapshotBalancesTx(ctx, db.SnapshotBalancesTxParams{
+		From: from,
+		To:   time.Now().UTC().AddDate(0, 0, -1),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to snapshot balances: %w", err)
+	}
+
+	log.Info().Str("type", task.Type()).
+		Str("from", from.Format(time.DateOnly)).
+		Int("days", result.Days).
+		Int64("snapshots", result.Snapshots).
+		Msg("processed task")
+	return nil
+}
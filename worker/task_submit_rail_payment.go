@@ -0,0 +1,125 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	db "github.com/AutomaticOrca/simplebank/db/sqlc"
+	"github.com/AutomaticOrca/simplebank/rails"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+const TaskSubmitRailPayment = "task:submit_rail_payment"
+
+type PayloadSubmitRailPayment struct {
+	CashMovementID int64 `json:"cash_movement_id"`
+}
+
+func (distributor *RedisTaskDistributor) DistributeTaskSubmitRailPayment(
+	ctx context.Context,
+	payload *PayloadSubmitRailPayment,
+	opts ...asynq.Option,
+) error {
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+
+	defaultOpts := []asynq.Option{
+		asynq.MaxRetry(10),
+		asynq.Queue(QueueCritical),
+		asynq.Retention(24 * time.Hour),
+	}
+
+	finalOpts := append(defaultOpts, opts...)
+
+	task := asynq.NewTask(TaskSubmitRailPayment, jsonPayload, finalOpts...)
+	info, err := distributor.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+
+	log.Info().Str("type", task.Type()).Bytes("payload", task.Payload()).
+		Str("queue", info.Queue).Int("max_retry", info.MaxRetry).Msg("enqueued task")
+	return nil
+}
+
+// ProcessTaskSubmitRailPayment hands a pending top-up or payout to the payment rail.
+// The rail reports the outcome later through the callback made by NewRailCallback
+func (processor *RedisTaskProcessor) ProcessTaskSubmitRailPayment(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadSubmitRailPayment
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", asynq.SkipRetry)
+	}
+	if processor.rail == nil {
+		// top-ups and payouts are only offered when a rail is configured
+		return fmt.Errorf("no payment rail is configured: %w", asynq.SkipRetry)
+	}
+
+	cashMovement, err := processor.store.GetCashMovement(ctx, payload.CashMovementID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// the task is enqueued inside the transaction that creates the payment, which may not be committed yet.
+			// If it is still missing on the last attempt, the transaction was rolled back
+			if isLastAttempt(ctx) {
+				return fmt.Errorf("cash movement doesn't exist: %w", asynq.SkipRetry)
+			}
+			return fmt.Errorf("cash movement doesn't exist yet: %w", err)
+		}
+		return fmt.Errorf("failed to get cash movement: %w", err)
+	}
+	if cashMovement.Status != db.CashMovementStatusPending || cashMovement.RailReference != "" {
+		log.Info().Str("type", task.Type()).Bytes("payload", task.Payload()).
+			Str("status", cashMovement.Status).Msg("skipped task")
+		return nil
+	}
+
+	reference, err := processor.submitRailPayment(ctx, cashMovement)
+	if err != nil {
+		return err
+	}
+
+	log.Info().Str("type", task.Type()).Bytes("payload", task.Payload()).
+		Str("rail", processor.rail.Name()).Str("reference", reference).Msg("processed task")
+	return nil
+}
+
+// submitRailPayment submits a pending payment to the rail and saves the reference the rail gave it
+func (processor *RedisTaskProcessor) submitRailPayment(ctx context.Context, cashMovement db.CashMovement) (string, error) {
+	account, err := processor.store.GetAccount(ctx, cashMovement.AccountID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get account: %w", err)
+	}
+
+	direction := rails.DirectionIn
+	if cashMovement.Kind == db.CashMovementWithdrawal {
+		direction = rails.DirectionOut
+	}
+	// the rail does not move the money twice for the same ID, so the payment can be submitted again on retry
+	reference, err := processor.rail.Submit(ctx, rails.Payment{
+		ID:        strconv.FormatInt(cashMovement.ID, 10),
+		Direction: direction,
+		AccountID: cashMovement.AccountID,
+		Amount:    cashMovement.Amount,
+		Currency:  account.Currency,
+		Reference: cashMovement.Reference,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to submit payment to %s rail: %w", processor.rail.Name(), err)
+	}
+
+	_, err = processor.store.SetCashMovementRailReference(ctx, db.SetCashMovementRailReferenceParams{
+		ID:            cashMovement.ID,
+		RailReference: reference,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to save rail reference: %w", err)
+	}
+	return reference, nil
+}
@@ -0,0 +1,101 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	db "github.com/AutomaticOrca/simplebank/db/sqlc"
+	"github.com/AutomaticOrca/simplebank/rails"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	TaskSweepRailPayments = "task:sweep_rail_payments"
+
+	sweepRailPaymentsSpec = "*/10 * * * *"
+	// railPaymentResubmitAfter is how long a payment may stay pending before it is submitted again.
+	// A rail may lose a payment, as the simulator does when it restarts, and submitting it again is safe
+	// because the rail does not move the money twice for the same ID
+	railPaymentResubmitAfter = 10 * time.Minute
+	// sweepRailPaymentsBatch caps the payments swept by one run, the rest are picked up by the next one
+	sweepRailPaymentsBatch = 100
+)
+
+// railPaymentTimeoutReason is the return reason of a payment cancelled by the sweep
+const railPaymentTimeoutReason = "not completed by the payment rail in time"
+
+// ProcessTaskSweepRailPayments looks after top-ups and payouts the rail has not completed.
+// They are submitted to the rail again until they are older than the rail payment timeout.
+// Then they are cancelled at the rail, and failed, which gives a payout back to the account.
+// A payment the rail has already completed cannot be cancelled, and its outcome is recorded instead
+func (processor *RedisTaskProcessor) ProcessTaskSweepRailPayments(ctx context.Context, task *asynq.Task) error {
+	now := time.Now()
+	cashMovements, err := processor.store.ListStaleRailPayments(ctx, db.ListStaleRailPaymentsParams{
+		CreatedBefore: now.Add(-railPaymentResubmitAfter),
+		MaxCount:      sweepRailPaymentsBatch,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list stale rail payments: %w", err)
+	}
+	if len(cashMovements) > 0 && processor.rail == nil {
+		// only the rail can tell whether the money moved, so the payments are left pending
+		log.Error().Str("type", task.Type()).Int("cash_movements", len(cashMovements)).
+			Msg("rail payments are pending but no payment rail is configured")
+		return nil
+	}
+
+	resubmitted, completed := 0, 0
+	for _, cashMovement := range cashMovements {
+		if now.Sub(cashMovement.CreatedAt) < processor.config.RailPaymentTimeout {
+			_, err := processor.submitRailPayment(ctx, cashMovement)
+			if err != nil {
+				log.Error().Err(err).Int64("cash_movement_id", cashMovement.ID).Msg("failed to resubmit rail payment")
+				continue
+			}
+			resubmitted++
+			continue
+		}
+
+		if err := processor.cancelRailPayment(ctx, cashMovement); err != nil {
+			log.Error().Err(err).Int64("cash_movement_id", cashMovement.ID).Msg("failed to cancel rail payment")
+			continue
+		}
+		completed++
+	}
+
+	log.Info().Str("type", task.Type()).
+		Int("resubmitted", resubmitted).Int("completed", completed).Msg("processed task")
+	return nil
+}
+
+// cancelRailPayment cancels a payment at the rail, and records the outcome the rail returns
+func (processor *RedisTaskProcessor) cancelRailPayment(ctx context.Context, cashMovement db.CashMovement) error {
+	event, err := processor.rail.Cancel(ctx, strconv.FormatInt(cashMovement.ID, 10))
+	if err != nil {
+		return fmt.Errorf("failed to cancel payment at %s rail: %w", processor.rail.Name(), err)
+	}
+
+	status, ok := cashMovementStatus(event.Status)
+	if !ok {
+		return fmt.Errorf("unknown rail payment status %q", event.Status)
+	}
+	reason := event.Reason
+	if event.Status == rails.StatusCancelled {
+		reason = railPaymentTimeoutReason
+	}
+
+	_, err = processor.store.CompleteRailPaymentTx(ctx, db.CompleteRailPaymentTxParams{
+		CashMovementID: cashMovement.ID,
+		Status:         status,
+		ReturnReason:   reason,
+	})
+	// completed by the rail's callback in the meantime
+	if errors.Is(err, db.ErrCashMovementNotPending) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,60 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	db "github.com/AutomaticOrca/simplebank/db/sqlc"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	TaskSweepScheduledTransfers = "task:sweep_scheduled_transfers"
+
+	sweepScheduledTransfersSpec = "*/10 * * * *"
+	// scheduledTransferOverdueAfter is how long after execute_at a scheduled transfer is executed by the sweep.
+	// Its own task normally executes it before, but the task can be lost, e.g. if Redis loses its data
+	scheduledTransferOverdueAfter = 10 * time.Minute
+	// sweepScheduledTransfersBatch caps the scheduled transfers executed by one run, the rest are picked up by the next one
+	sweepScheduledTransfersBatch = 100
+)
+
+// ProcessTaskSweepScheduledTransfers executes the scheduled transfers whose task did not execute them in time.
+// Errors that may go away are only logged, and the transfer is tried again by the next sweep
+func (processor *RedisTaskProcessor) ProcessTaskSweepScheduledTransfers(ctx context.Context, task *asynq.Task) error {
+	scheduledTransfers, err := processor.store.ListOverdueScheduledTransfers(ctx, db.ListOverdueScheduledTransfersParams{
+		ExecuteBefore: time.Now().Add(-scheduledTransferOverdueAfter),
+		MaxCount:      sweepScheduledTransfersBatch,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list overdue scheduled transfers: %w", err)
+	}
+
+	executed := 0
+	for _, scheduledTransfer := range scheduledTransfers {
+		_, err := processor.store.ExecuteScheduledTransferTx(ctx, db.ExecuteScheduledTransferTxParams{
+			ScheduledTransferID: scheduledTransfer.ID,
+			Limits:              processor.transferLimits(),
+		})
+		switch {
+		case err == nil:
+			executed++
+		case errors.Is(err, db.ErrScheduledTransferNotPending):
+			// executed or cancelled in the meantime
+		case db.IsRejected(err), errors.Is(err, sql.ErrNoRows):
+			if err := processor.failScheduledTransfer(ctx, scheduledTransfer.ID, err); err != nil {
+				log.Error().Err(err).Int64("scheduled_transfer_id", scheduledTransfer.ID).Msg("failed to fail scheduled transfer")
+			}
+		default:
+			log.Error().Err(err).Int64("scheduled_transfer_id", scheduledTransfer.ID).Msg("failed to execute scheduled transfer")
+		}
+	}
+
+	log.Info().Str("type", task.Type()).
+		Int("scheduled_transfers", executed).Msg("processed task")
+	return nil
+}